@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
 
 	"klipd/config"
 	"klipd/database"
@@ -19,6 +22,9 @@ type App struct {
 	config           *config.Config
 	clipboardMonitor *services.ClipboardMonitor
 	hotkeyManager    *services.HotkeyManager
+	mainWindow       *services.MainWindow
+	ephemeral        bool // History is kept in memory only; the data dir was unusable
+	logLevel         slog.LevelVar
 }
 
 // NewApp creates a new App application struct
@@ -30,13 +36,20 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.mainWindow = services.NewMainWindow(wailsWindow{ctx})
+
+	// Route all logging, including plain log calls, through one leveled
+	// handler; the level follows the LogLevel setting
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: &a.logLevel})))
 
 	// Initialize database
-	db, err := database.New()
+	db, ephemeral, err := database.New()
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		slog.Error("Failed to initialize database", "err", err)
+		os.Exit(1)
 	}
 	a.db = db
+	a.ephemeral = ephemeral
 
 	// Initialize configuration
 	a.config = config.NewConfig()
@@ -44,45 +57,113 @@ func (a *App) startup(ctx context.Context) {
 	// Load settings from database and update config
 	if settings, err := a.db.GetSettings(); err == nil {
 		settingsMap := map[string]interface{}{
-			"pollingInterval":    settings.PollingInterval,
-			"maxItems":           settings.MaxItems,
-			"maxDays":            settings.MaxDays,
-			"monitoringEnabled":  settings.MonitoringEnabled,
-			"globalHotkey":       settings.GlobalHotkey,
-			"previousItemHotkey": settings.PreviousItemHotkey,
-			"autoLaunch":         settings.AutoLaunch,
-			"enableSounds":       settings.EnableSounds,
+			"pollingInterval":           settings.PollingInterval,
+			"maxItems":                  settings.MaxItems,
+			"maxItemsPerApp":            settings.MaxItemsPerApp,
+			"maxDays":                   settings.MaxDays,
+			"monitoringEnabled":         settings.MonitoringEnabled,
+			"globalHotkey":              settings.GlobalHotkey,
+			"previousItemHotkey":        settings.PreviousItemHotkey,
+			"showWindowHotkey":          settings.ShowWindowHotkey,
+			"forgetHotkey":              settings.ForgetHotkey,
+			"cycleSortHotkey":           settings.CycleSortHotkey,
+			"captureHotkey":             settings.CaptureHotkey,
+			"stackPasteMode":            settings.StackPasteMode,
+			"previousHotkeyAction":      settings.PreviousHotkeyAction,
+			"autoLaunch":                settings.AutoLaunch,
+			"enableSounds":              settings.EnableSounds,
+			"respectSecureInput":        settings.RespectSecureInput,
+			"captureExistingOnStart":    settings.CaptureExistingOnStart,
+			"dedupNormalization":        settings.DedupNormalization,
+			"minContentLength":          settings.MinContentLength,
+			"dedupWindowMs":             settings.DedupWindowMs,
+			"captureTransforms":         settings.CaptureTransforms,
+			"normalizeText":             settings.NormalizeText,
+			"captureAllowlist":          settings.CaptureAllowlist,
+			"ignoreSelfWrites":          settings.IgnoreSelfWrites,
+			"ignoreSignatures":          settings.IgnoreSignatures,
+			"logLevel":                  settings.LogLevel,
+			"logContentPreviews":        settings.LogContentPreviews,
+			"theme":                     settings.Theme,
+			"compressLargeContent":      settings.CompressLargeContent,
+			"skipWhitespaceOnlyChanges": settings.SkipWhitespaceOnlyChanges,
+			"promotePinnedOnRecopy":     settings.PromotePinnedOnRecopy,
+			"imageExtensions":           settings.ImageExtensions,
+			"captureMode":               settings.CaptureMode,
+			"manualCaptureMode":         settings.ManualCaptureMode,
+			"maxImageDimension":         settings.MaxImageDimension,
+			"stripURLTracking":          settings.StripURLTracking,
+			"passwordEntropyThreshold":  settings.PasswordEntropyThreshold,
+			"passwordSkipThreshold":     settings.PasswordSkipThreshold,
 		}
 		a.config.UpdateFromSettings(settingsMap)
+		a.logLevel.Set(a.config.LogLevel)
 	}
 
 	// Initialize clipboard monitor
 	a.clipboardMonitor = services.NewClipboardMonitor(a.db, a.config)
+
+	// Set Wails context for event emission, before starting so the first
+	// "monitor-state" event reaches the frontend
+	a.clipboardMonitor.SetWailsContext(a.ctx)
+
 	// Start clipboard monitoring
 	if err := a.clipboardMonitor.Start(); err != nil {
-		log.Printf("Failed to start clipboard monitor: %v", err)
+		slog.Error("Failed to start clipboard monitor", "err", err)
 	}
 
-	// Set Wails context for event emission
-	a.clipboardMonitor.SetWailsContext(a.ctx)
-
 	// Initialize hotkey manager
 	a.hotkeyManager = services.NewHotkeyManager()
 
 	// Register and start global hotkeys
 	if err := a.setupHotkeys(); err != nil {
-		log.Printf("Failed to setup hotkeys: %v", err)
+		slog.Error("Failed to setup hotkeys", "err", err)
 	}
 	if err := a.hotkeyManager.Start(); err != nil {
-		log.Printf("Failed to start hotkey manager: %v", err)
+		slog.Error("Failed to start hotkey manager", "err", err)
+	}
+
+	slog.Info("Klipd clipboard manager started successfully")
+}
+
+// domReady is called once the frontend has loaded and can receive events
+func (a *App) domReady(ctx context.Context) {
+	if a.ephemeral {
+		runtime.EventsEmit(ctx, "persistence-disabled")
+	}
+	if backup := a.db.RecoveredBackup(); backup != "" {
+		runtime.EventsEmit(ctx, "db-recovered", backup)
+	}
+	if empty, err := a.IsHistoryEmpty(); err == nil && empty {
+		runtime.EventsEmit(ctx, "history-empty")
 	}
+}
 
-	log.Println("Klipd clipboard manager started successfully")
+// GetVersion returns the version Klipd was built as, "dev" for local builds
+func (a *App) GetVersion() string {
+	return config.AppVersion()
+}
+
+// GetSchemaVersion returns the settings schema version of the database, or
+// 0 if the settings can't be read
+func (a *App) GetSchemaVersion() int {
+	settings, err := a.db.GetSettings()
+	if err != nil {
+		slog.Error("Failed to read schema version", "err", err)
+		return 0
+	}
+	return settings.SchemaVersion
+}
+
+// IsPersistenceDisabled reports whether history is only kept in memory for
+// this session because the data directory couldn't be used
+func (a *App) IsPersistenceDisabled() bool {
+	return a.ephemeral
 }
 
 // shutdown is called when the app is shutting down
 func (a *App) shutdown(ctx context.Context) {
-	log.Println("Shutting down Klipd...")
+	slog.Info("Shutting down Klipd")
 
 	if a.clipboardMonitor != nil {
 		a.clipboardMonitor.Stop()
@@ -94,7 +175,7 @@ func (a *App) shutdown(ctx context.Context) {
 
 	if a.db != nil {
 		if err := a.db.Close(); err != nil {
-			log.Printf("Failed to close database: %v", err)
+			slog.Error("Failed to close database", "err", err)
 		}
 	}
 }
@@ -110,11 +191,9 @@ func (a *App) setupHotkeys() error {
 	if hotkeyStr == "" {
 		hotkeyStr = "Cmd+Shift+Space"
 	}
-	err = a.hotkeyManager.Register(hotkeyStr, func() {
-		log.Printf("Global hotkey triggered: %s", hotkeyStr)
-		// Bring window to front and show search interface
-		runtime.WindowShow(a.ctx)
-		runtime.EventsEmit(a.ctx, "show-search-interface")
+	err = a.hotkeyManager.RegisterAll(services.SplitHotkeys(hotkeyStr), func() {
+		slog.Debug("Global hotkey triggered", "hotkey", hotkeyStr)
+		a.ToggleMainWindow()
 	})
 	if err != nil {
 		return err
@@ -126,19 +205,67 @@ func (a *App) setupHotkeys() error {
 		previousHotkey = "Cmd+Shift+C" // Default hotkey
 	}
 
-	err = a.hotkeyManager.Register(previousHotkey, func() {
-		log.Printf("Previous item hotkey triggered: %s", previousHotkey)
-		// Get the most recent clipboard item and paste it
-		a.pasteLastItem()
+	err = a.hotkeyManager.RegisterAll(services.SplitHotkeys(previousHotkey), func() {
+		slog.Debug("Previous item hotkey triggered", "hotkey", previousHotkey)
+		// The action is looked up per press so changing it needs no
+		// re-registration
+		actions := services.PreviousItemActions{
+			Copy:  a.copyPrevious,
+			Paste: a.PastePrevious,
+			Show:  a.ShowLatestItem,
+		}
+		if err := actions.Run(a.config.PreviousHotkeyAction); err != nil {
+			slog.Error("Previous item hotkey failed", "err", err)
+		}
 	})
 	if err != nil {
 		return err
 	}
 
+	// Register the optional "forget that" hotkey
+	if forgetHotkey := a.config.ForgetHotkey; forgetHotkey != "" {
+		err = a.hotkeyManager.RegisterAll(services.SplitHotkeys(forgetHotkey), func() {
+			slog.Debug("Forget hotkey triggered", "hotkey", forgetHotkey)
+			if err := a.ForgetCurrent(); err != nil {
+				slog.Error("Failed to forget current item", "err", err)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Register the optional sort mode hotkey
+	if cycleSortHotkey := a.config.CycleSortHotkey; cycleSortHotkey != "" {
+		err = a.hotkeyManager.RegisterAll(services.SplitHotkeys(cycleSortHotkey), func() {
+			slog.Debug("Cycle sort hotkey triggered", "hotkey", cycleSortHotkey)
+			a.CycleSortMode()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Register the optional capture hotkey, mainly for manual capture mode
+	if captureHotkey := a.config.CaptureHotkey; captureHotkey != "" {
+		err = a.hotkeyManager.RegisterAll(services.SplitHotkeys(captureHotkey), func() {
+			slog.Debug("Capture hotkey triggered", "hotkey", captureHotkey)
+			if _, err := a.CaptureNow(); err != nil {
+				slog.Error("Failed to capture clipboard", "err", err)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	// Register show window hotkey
-	showWindowHotkey := "Cmd+Shift+K" // Show main window hotkey
-	err = a.hotkeyManager.Register(showWindowHotkey, func() {
-		log.Printf("Show window hotkey triggered: %s", showWindowHotkey)
+	showWindowHotkey := a.config.ShowWindowHotkey
+	if showWindowHotkey == "" {
+		showWindowHotkey = "Cmd+Shift+K" // Default hotkey
+	}
+	err = a.hotkeyManager.RegisterAll(services.SplitHotkeys(showWindowHotkey), func() {
+		slog.Debug("Show window hotkey triggered", "hotkey", showWindowHotkey)
 		a.ShowMainWindow()
 	})
 	if err != nil {
@@ -148,22 +275,113 @@ func (a *App) setupHotkeys() error {
 	return nil
 }
 
-// pasteLastItem copies the most recent clipboard item to system clipboard
-func (a *App) pasteLastItem() {
-	items, err := a.db.GetClipboardItems(1, 0, "", "copied")
+// PasteCyclePrevious copies a recent item to the system clipboard. Repeated
+// calls within a few seconds step further back through history.
+func (a *App) PasteCyclePrevious() error {
+	_, err := a.cyclePrevious()
+	return err
+}
+
+func (a *App) cyclePrevious() (*models.ClipboardItem, error) {
+	item, err := a.clipboardMonitor.CopyPreviousToClipboard()
 	if err != nil {
-		log.Printf("Failed to get recent items: %v", err)
-		return
+		return nil, err
+	}
+
+	if item != nil {
+		slog.Info("Pasted previous clipboard item", a.config.ContentLogAttrs(item.ContentType, item.SizeBytes, item.PreviewText)...)
+	}
+	return item, nil
+}
+
+// copyPrevious is the previous-item hotkey's copy action. In stack mode each
+// press pops the newest item; otherwise it copies the most recent item, or
+// older ones on repeated presses.
+func (a *App) copyPrevious() error {
+	_, err := a.copyPreviousItem()
+	return err
+}
+
+func (a *App) copyPreviousItem() (*models.ClipboardItem, error) {
+	if a.config.StackPasteMode {
+		return a.PopAndPasteLast()
+	}
+	return a.cyclePrevious()
+}
+
+// PastePrevious copies an item as the copy action does, then pastes it into
+// the frontmost app. Nothing is pasted when history is empty.
+func (a *App) PastePrevious() error {
+	item, err := a.copyPreviousItem()
+	if err != nil || item == nil {
+		return err
 	}
+	return services.SendPasteKeystroke()
+}
 
+// ShowLatestItem brings up the main window and asks the frontend, with a
+// "show-item-detail" event, to open the most recently copied item
+func (a *App) ShowLatestItem() error {
+	a.ShowMainWindow()
+
+	items, err := a.db.GetClipboardItems(1, 0, "", database.SortCopied)
+	if err != nil {
+		return err
+	}
 	if len(items) > 0 {
-		err := a.clipboardMonitor.CopyItemToClipboard(items[0].ID)
-		if err != nil {
-			log.Printf("Failed to copy item to clipboard: %v", err)
-		} else {
-			log.Printf("Pasted last clipboard item: %s", items[0].PreviewText)
+		runtime.EventsEmit(a.ctx, "show-item-detail", items[0].ID)
+	}
+	return nil
+}
+
+// FindCurrentClipboardInHistory lists the history items matching what's
+// currently copied, newest first, without capturing it
+func (a *App) FindCurrentClipboardInHistory() ([]models.ClipboardItem, error) {
+	return a.clipboardMonitor.FindCurrentInHistory()
+}
+
+// PopAndPasteLast copies the newest unpinned item to the system clipboard
+// and deletes it from history, returning it. Nil means history was empty.
+func (a *App) PopAndPasteLast() (*models.ClipboardItem, error) {
+	item, err := a.clipboardMonitor.PopAndPaste()
+	if err != nil {
+		return nil, err
+	}
+
+	if item != nil {
+		slog.Info("Popped clipboard item", a.config.ContentLogAttrs(item.ContentType, item.SizeBytes, item.PreviewText)...)
+	}
+	return item, nil
+}
+
+// CaptureNow stores what's on the clipboard right away, through the same
+// filters as automatic captures. It's how items get into history in manual
+// capture mode. Nil means the content was filtered out.
+func (a *App) CaptureNow() (*models.ClipboardItem, error) {
+	return a.clipboardMonitor.CaptureNow()
+}
+
+// ForgetCurrent clears the system clipboard and deletes the newest unpinned
+// history item
+func (a *App) ForgetCurrent() error {
+	return a.clipboardMonitor.ForgetCurrent()
+}
+
+// CycleSortMode switches to the next sort mode, saves it and returns it. The
+// frontend gets a "sort-mode-changed" event to reload the list. On failure
+// the current mode is returned unchanged.
+func (a *App) CycleSortMode() string {
+	mode, err := a.db.CycleSortMode()
+	if err != nil {
+		slog.Error("Failed to cycle sort mode", "err", err)
+		if settings, err := a.db.GetSettings(); err == nil {
+			return settings.SortByRecent
 		}
+		return ""
 	}
+
+	runtime.EventsEmit(a.ctx, "sort-mode-changed", string(mode))
+	return string(mode)
 }
 
 // ShowSearchInterface emits an event to show the search interface (callable from frontend)
@@ -179,55 +397,150 @@ func (a *App) HideSearchInterface() {
 // TriggerGlobalHotkey manually triggers the global hotkey (for testing)
 // This function is now a placeholder as the new library doesn't support manual triggering.
 func (a *App) TriggerGlobalHotkey() {
-	log.Println("Manual hotkey triggering is not supported by the new library.")
+	slog.Warn("Manual hotkey triggering is not supported by the new library")
 }
 
-// GetClipboardItems returns clipboard items with optional pagination and filtering
+// queryTimeout bounds list and search queries so a pathological pattern
+// can't hang the UI
+const queryTimeout = 5 * time.Second
+
+// queryDB returns the database bound to a context that expires after
+// queryTimeout or when the app shuts down. Call cancel once done.
+func (a *App) queryDB() (db *database.Database, cancel context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(a.ctx, queryTimeout)
+	return a.db.WithContext(ctx), cancel
+}
+
+// sortOrder returns the saved sort mode and direction. Without settings the
+// list is by capture time, newest first; a saved mode that doesn't parse is
+// an error.
+func sortOrder(db *database.Database) (database.SortMode, bool, error) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		return database.SortCopied, false, nil
+	}
+	mode, err := database.ParseSortMode(settings.SortByRecent)
+	if err != nil {
+		return "", false, err
+	}
+	return mode, settings.SortAscending, nil
+}
+
+// GetClipboardItems returns clipboard items with optional pagination and
+// filtering. contentType may be an alias like "img"; see
+// config.ResolveContentTypeFilter.
 func (a *App) GetClipboardItems(limit int, offset int, contentType string) ([]models.ClipboardItem, error) {
-	settings, err := a.db.GetSettings()
+	db, cancel := a.queryDB()
+	defer cancel()
+
+	mode, ascending, err := sortOrder(db)
 	if err != nil {
-		return a.db.GetClipboardItems(limit, offset, contentType, "copied")
+		return nil, err
 	}
-	return a.db.GetClipboardItems(limit, offset, contentType, settings.SortByRecent)
+	return db.GetClipboardItemsOrdered(limit, offset, config.ResolveContentTypeFilter(contentType), mode, ascending)
+}
+
+// GetClipboardItemsCursor returns the page of items after cursor; pass the
+// zero cursor for the first page and the returned Next for the following ones
+func (a *App) GetClipboardItemsCursor(cursor models.Cursor, limit int, contentType string) (*models.ClipboardPage, error) {
+	db, cancel := a.queryDB()
+	defer cancel()
+
+	mode, ascending, err := sortOrder(db)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetClipboardItemsAfter(cursor, limit, config.ResolveContentTypeFilter(contentType), mode, ascending)
+}
+
+// GetAdjacentItems returns the items before and after id in the current
+// ordering, for stepping through history in a detail view
+func (a *App) GetAdjacentItems(id string) (*models.AdjacentItems, error) {
+	mode, ascending, err := sortOrder(a.db)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, next, err := a.db.GetAdjacentItems(id, mode, ascending)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AdjacentItems{Prev: prev, Next: next}, nil
 }
 
 func (a *App) GetClipboardItemsPaginated(limit int, offset int, contentType string) ([]models.ClipboardItem, error) {
 	return a.GetClipboardItems(limit, offset, contentType)
 }
 
-// SearchClipboardItems searches clipboard items by content
+// SearchClipboardItems searches clipboard items by content, note, tags and source app
 func (a *App) SearchClipboardItems(query string, limit int) ([]models.ClipboardItem, error) {
 	if query == "" {
 		return a.GetClipboardItems(limit, 0, "")
 	}
-	return a.SearchClipboardItemsPaginated(query, limit, 0, false)
+	return a.SearchClipboardItemsPaginated(query, limit, 0, false, false, false)
 }
 
-func (a *App) SearchClipboardItemsPaginated(query string, limit int, offset int, useRegex bool) ([]models.ClipboardItem, error) {
-	settings, err := a.db.GetSettings()
-	sortByRecent := "copied"
-	if err == nil {
-		sortByRecent = settings.SortByRecent
+// AddRecentSearch remembers a query typed into the search box so it can be
+// suggested again
+func (a *App) AddRecentSearch(query string) error {
+	return a.db.AddRecentSearch(query)
+}
+
+// GetRecentSearches returns past search queries for the search box's
+// suggestions, most recent first
+func (a *App) GetRecentSearches() ([]string, error) {
+	db, cancel := a.queryDB()
+	defer cancel()
+
+	return db.GetRecentSearches()
+}
+
+// GetClipboardItemsWithMeta returns a page of clipboard items like
+// GetClipboardItems, along with whether there is a next page
+func (a *App) GetClipboardItemsWithMeta(limit int, offset int, contentType string) (*models.ItemsPage, error) {
+	db, cancel := a.queryDB()
+	defer cancel()
+
+	mode, ascending, err := sortOrder(db)
+	if err != nil {
+		return nil, err
 	}
+	return db.GetClipboardItemsPage(limit, offset, config.ResolveContentTypeFilter(contentType), mode, ascending)
+}
 
-	if query == "" {
-		return a.db.GetClipboardItems(limit, offset, "", sortByRecent)
+// SearchClipboardItemsPaginated searches the history, by regex or across all
+// text fields. pinnedOnly restricts it to pinned items, for searching a
+// snippet library; with an empty query it lists them all. wholeWord matches
+// the query only as a whole word; it doesn't apply to regex searches.
+func (a *App) SearchClipboardItemsPaginated(query string, limit int, offset int, useRegex bool, pinnedOnly bool, wholeWord bool) ([]models.ClipboardItem, error) {
+	db, cancel := a.queryDB()
+	defer cancel()
+
+	mode, ascending, err := sortOrder(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if query == "" && !pinnedOnly {
+		return db.GetClipboardItemsOrdered(limit, offset, "", mode, ascending)
 	}
 
 	if useRegex {
-		return a.db.SearchClipboardItemsRegex(query, limit, offset, sortByRecent)
+		return db.SearchClipboardItemsRegex(query, limit, offset, mode, ascending, pinnedOnly)
 	}
-	return a.db.SearchClipboardItems(query, limit, offset, sortByRecent)
+	return db.SearchAllFields(query, nil, limit, offset, mode, ascending, pinnedOnly, wholeWord)
 }
 
 // SearchClipboardItemsRegex searches clipboard items using regex patterns
 func (a *App) SearchClipboardItemsRegex(regexPattern string, limit int) ([]models.ClipboardItem, error) {
-	settings, err := a.db.GetSettings()
-	sortByRecent := "copied"
-	if err == nil {
-		sortByRecent = settings.SortByRecent
+	db, cancel := a.queryDB()
+	defer cancel()
+
+	mode, ascending, err := sortOrder(db)
+	if err != nil {
+		return nil, err
 	}
-	return a.db.SearchClipboardItemsRegex(regexPattern, limit, 0, sortByRecent)
+	return db.SearchClipboardItemsRegex(regexPattern, limit, 0, mode, ascending, false)
 }
 
 // GetClipboardItemByID retrieves a specific clipboard item
@@ -235,24 +548,235 @@ func (a *App) GetClipboardItemByID(id string) (*models.ClipboardItem, error) {
 	return a.clipboardMonitor.GetItemByID(id)
 }
 
+// DiffItems returns a unified diff between the text of two items
+func (a *App) DiffItems(idA string, idB string) (string, error) {
+	return a.clipboardMonitor.DiffItems(idA, idB)
+}
+
+// PeekItem returns the full item, thumbnail included, for a detail view
+// without copying it or counting it as accessed
+func (a *App) PeekItem(id string) (*models.ClipboardItem, error) {
+	return a.clipboardMonitor.PeekItem(id)
+}
+
 // SelectClipboardItem copies a clipboard item back to the system clipboard
 func (a *App) SelectClipboardItem(id string) error {
 	return a.clipboardMonitor.CopyItemToClipboard(id)
 }
 
+// CopyItemFormatted copies a pretty-printed version of a JSON or XML item
+func (a *App) CopyItemFormatted(id string) error {
+	return a.clipboardMonitor.CopyItemFormatted(id)
+}
+
+// CopyItemWithLineEnding copies an item with its line endings converted to
+// style: "lf", "crlf" or "cr"
+func (a *App) CopyItemWithLineEnding(id string, style string) error {
+	return a.clipboardMonitor.CopyItemWithLineEnding(id, style)
+}
+
+// ExportItemAsMarkdown returns an item as a Markdown snippet, e.g. code in a
+// fenced block tagged with its language, for sharing in chats and docs
+func (a *App) ExportItemAsMarkdown(id string) (string, error) {
+	return a.clipboardMonitor.ItemMarkdown(id)
+}
+
+// CopyItemAsMarkdown copies an item's Markdown snippet to the clipboard
+func (a *App) CopyItemAsMarkdown(id string) error {
+	return a.clipboardMonitor.CopyItemAsMarkdown(id)
+}
+
+// CopyItemsJoined copies the text of several items at once, joined by
+// separator, without adding the result to the history
+func (a *App) CopyItemsJoined(ids []string, separator string) error {
+	return a.clipboardMonitor.CopyItemsJoined(ids, separator)
+}
+
+// CopyItemOriginal copies an item as it was captured, before any cleanup
+func (a *App) CopyItemOriginal(id string) error {
+	return a.clipboardMonitor.CopyItemOriginal(id)
+}
+
 // PinClipboardItem toggles the pin status of a clipboard item
 func (a *App) PinClipboardItem(id string, pinned bool) error {
 	return a.clipboardMonitor.PinItem(id, pinned)
 }
 
+// SetItemOneTime marks an item to be deleted after it's next copied, e.g. a
+// one-time code
+func (a *App) SetItemOneTime(id string, oneTime bool) error {
+	return a.clipboardMonitor.SetItemOneTime(id, oneTime)
+}
+
+// WaitForNextCapture blocks until the next clipboard item is captured and
+// returns it, for automation that needs to follow what gets copied. It fails
+// if nothing is captured within timeoutMs milliseconds.
+func (a *App) WaitForNextCapture(timeoutMs int) (*models.ClipboardItem, error) {
+	return a.clipboardMonitor.WaitForNextCapture(time.Duration(timeoutMs) * time.Millisecond)
+}
+
+// PromoteItem moves an item to the top of the list to keep it handy, without
+// touching its content or creating a copy
+func (a *App) PromoteItem(id string) error {
+	return a.clipboardMonitor.PromoteItem(id)
+}
+
 // DeleteClipboardItem removes a clipboard item
 func (a *App) DeleteClipboardItem(id string) error {
 	return a.clipboardMonitor.DeleteItem(id)
 }
 
-// ClearAllClipboardItems removes all clipboard items
-func (a *App) ClearAllClipboardItems(preservePinned bool) error {
-	return a.clipboardMonitor.ClearAll(preservePinned)
+// DeleteAndReturnNext deletes the highlighted item and returns the item the
+// palette should highlight instead, nil once the list is empty. An empty
+// sortByRecent uses the saved sort mode; the direction is always the saved one.
+func (a *App) DeleteAndReturnNext(id string, sortByRecent string) (*models.ClipboardItem, error) {
+	mode, ascending, err := sortOrder(a.db)
+	if err != nil {
+		return nil, err
+	}
+	if sortByRecent != "" {
+		if mode, err = database.ParseSortMode(sortByRecent); err != nil {
+			return nil, err
+		}
+	}
+	return a.clipboardMonitor.DeleteAndReturnNext(id, mode, ascending)
+}
+
+// GetItemContent returns only an item's content, with images as a data URL
+func (a *App) GetItemContent(id string) (string, error) {
+	return a.clipboardMonitor.GetItemContent(id)
+}
+
+// GetItemContentBytes returns only an item's raw content bytes
+func (a *App) GetItemContentBytes(id string) ([]byte, error) {
+	return a.clipboardMonitor.GetItemContentBytes(id)
+}
+
+// SaveItemToFile writes an item's content to path, adding an extension if
+// path has none
+func (a *App) SaveItemToFile(id string, path string) error {
+	return a.clipboardMonitor.SaveItemToFile(id, path)
+}
+
+// ImportItems adds items from a JSON file to the history. strategy decides
+// what happens to items already stored: "skip", "overwrite" or "keep-both".
+func (a *App) ImportItems(path string, strategy string) (int, error) {
+	return a.clipboardMonitor.ImportItems(path, strategy, a.maintenanceProgress("import"))
+}
+
+// ImportFromTextFile adds a text item for each line of a plain text file, or
+// each paragraph if mode is "paragraphs", and returns how many were added
+func (a *App) ImportFromTextFile(path string, mode string) (int, error) {
+	return a.clipboardMonitor.ImportFromTextFile(path, mode, a.maintenanceProgress("import"))
+}
+
+// ReclassifyItems re-runs content type detection over stored items and
+// returns how many changed
+func (a *App) ReclassifyItems() (int, error) {
+	return a.clipboardMonitor.ReclassifyItems(a.maintenanceProgress("reclassify"))
+}
+
+// maintenanceProgress reports a long operation's progress to the frontend
+// as "maintenance-progress" events
+func (a *App) maintenanceProgress(operation string) database.ProgressFunc {
+	return func(processed, total int) {
+		runtime.EventsEmit(a.ctx, "maintenance-progress", models.MaintenanceProgress{
+			Operation: operation,
+			Processed: processed,
+			Total:     total,
+		})
+	}
+}
+
+// GetAllTags returns every tag in use, for autocomplete
+func (a *App) GetAllTags() ([]string, error) {
+	return a.db.GetAllTags()
+}
+
+// TagItems adds tags to several clipboard items at once
+func (a *App) TagItems(ids []string, tags []string) error {
+	return a.db.TagItems(ids, tags)
+}
+
+// PinSearchResults pins or unpins up to limit items matching query and
+// returns how many changed. An empty query is an error.
+func (a *App) PinSearchResults(query string, pinned bool, limit int) (int, error) {
+	mode, ascending, err := sortOrder(a.db)
+	if err != nil {
+		return 0, err
+	}
+	return a.db.PinSearchResults(query, pinned, limit, mode, ascending)
+}
+
+// IsItemOnClipboard reports whether an item is what's currently copied
+func (a *App) IsItemOnClipboard(id string) (bool, error) {
+	return a.clipboardMonitor.IsItemOnClipboard(id)
+}
+
+// RequestClearAll returns a short-lived token to pass to ConfirmClearAll
+func (a *App) RequestClearAll() (string, error) {
+	return a.clipboardMonitor.RequestClearAll()
+}
+
+// IsHistoryEmpty reports whether there are no clipboard items yet, so the
+// frontend can show onboarding instead of an empty list. A "history-empty"
+// event is also sent at startup and whenever deleting items leaves none.
+func (a *App) IsHistoryEmpty() (bool, error) {
+	return a.clipboardMonitor.IsHistoryEmpty()
+}
+
+// ConfirmClearAll removes all clipboard items if the token is valid
+func (a *App) ConfirmClearAll(token string, preservePinned bool) error {
+	return a.clipboardMonitor.ConfirmClearAll(token, preservePinned)
+}
+
+// itemsSinceLimit caps GetItemsSince; given a full batch the frontend asks
+// again from the last item's time and id
+const itemsSinceLimit = 500
+
+// GetItemsSince returns items captured after an RFC 3339 time, oldest first,
+// so the frontend can refresh after a reconnect without reloading the list.
+// afterID is the id of the newest item the frontend has, or empty; items
+// sharing its timestamp are told apart by id.
+func (a *App) GetItemsSince(sinceRFC3339 string, afterID string) ([]models.ClipboardItem, error) {
+	since, err := time.Parse(time.RFC3339, sinceRFC3339)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time: %w", err)
+	}
+
+	db, cancel := a.queryDB()
+	defer cancel()
+	return db.GetItemsSince(since, afterID, itemsSinceLimit)
+}
+
+// RunCleanupNow applies the history limits right away rather than at the
+// next hourly cleanup. The frontend gets "cleanup-complete" with the number
+// of items removed.
+func (a *App) RunCleanupNow() error {
+	_, err := a.clipboardMonitor.RunCleanupNow()
+	return err
+}
+
+// ClearItemsInRange removes items captured between two RFC 3339 times, the
+// end excluded. An empty bound leaves that side open.
+func (a *App) ClearItemsInRange(startRFC3339, endRFC3339 string, preservePinned bool) error {
+	start, err := parseRangeBound(startRFC3339)
+	if err != nil {
+		return fmt.Errorf("invalid start time: %w", err)
+	}
+	end, err := parseRangeBound(endRFC3339)
+	if err != nil {
+		return fmt.Errorf("invalid end time: %w", err)
+	}
+	return a.clipboardMonitor.ClearInRange(start, end, preservePinned)
+}
+
+// parseRangeBound parses an RFC 3339 time, treating "" as the zero time
+func parseRangeBound(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
 }
 
 // ClearClipboardItemsByType removes all clipboard items of a specific type
@@ -260,6 +784,21 @@ func (a *App) ClearClipboardItemsByType(contentType string, preservePinned bool)
 	return a.clipboardMonitor.ClearByType(contentType, preservePinned)
 }
 
+// GetRegisteredHotkeys returns the hotkey combinations currently bound
+func (a *App) GetRegisteredHotkeys() []string {
+	if a.hotkeyManager == nil {
+		return nil
+	}
+	return a.hotkeyManager.ListRegistered()
+}
+
+// UnregisterHotkey releases a single hotkey until the next settings update
+func (a *App) UnregisterHotkey(combo string) {
+	if a.hotkeyManager != nil {
+		a.hotkeyManager.Unregister(combo)
+	}
+}
+
 // GetSettings returns the current application settings
 func (a *App) GetSettings() (*models.Settings, error) {
 	return a.db.GetSettings()
@@ -267,32 +806,98 @@ func (a *App) GetSettings() (*models.Settings, error) {
 
 // UpdateSettings updates the application settings
 func (a *App) UpdateSettings(settings *models.Settings) error {
-	if err := a.db.UpdateSettings(settings); err != nil {
+	if err := services.ValidateHotkeys(map[string]string{
+		"global search":  settings.GlobalHotkey,
+		"paste previous": settings.PreviousItemHotkey,
+		"show window":    settings.ShowWindowHotkey,
+		"forget":         settings.ForgetHotkey,
+		"cycle sort":     settings.CycleSortHotkey,
+		"capture":        settings.CaptureHotkey,
+	}); err != nil {
 		return err
 	}
-	// Re-register hotkeys on settings update
-	a.hotkeyManager.Stop()
-	a.hotkeyManager = services.NewHotkeyManager()
-	if err := a.setupHotkeys(); err != nil {
-		log.Printf("Failed to re-setup hotkeys after settings update: %v", err)
+	if settings.SortByRecent == "" {
+		settings.SortByRecent = string(database.SortCopied)
 	}
-	if err := a.hotkeyManager.Start(); err != nil {
-		log.Printf("Failed to re-start hotkey manager: %v", err)
+	if _, err := database.ParseSortMode(settings.SortByRecent); err != nil {
+		return err
+	}
+	if settings.Theme == "" {
+		settings.Theme = config.ThemeAuto
+	}
+	if err := config.ValidateTheme(settings.Theme); err != nil {
+		return err
+	}
+	if settings.PreviousHotkeyAction == "" {
+		settings.PreviousHotkeyAction = config.PreviousActionCopy
+	}
+	if err := config.ValidatePreviousHotkeyAction(settings.PreviousHotkeyAction); err != nil {
+		return err
+	}
+	previousTheme := a.config.Theme
+
+	if err := a.db.UpdateSettings(settings); err != nil {
+		return err
 	}
 
 	// Update runtime configuration
 	settingsMap := map[string]interface{}{
-		"pollingInterval":    settings.PollingInterval,
-		"maxItems":           settings.MaxItems,
-		"maxDays":            settings.MaxDays,
-		"monitoringEnabled":  settings.MonitoringEnabled,
-		"globalHotkey":       settings.GlobalHotkey,
-		"previousItemHotkey": settings.PreviousItemHotkey,
-		"autoLaunch":         settings.AutoLaunch,
-		"enableSounds":       settings.EnableSounds,
-		"allowPasswords":     settings.AllowPasswords,
+		"pollingInterval":           settings.PollingInterval,
+		"maxItems":                  settings.MaxItems,
+		"maxItemsPerApp":            settings.MaxItemsPerApp,
+		"maxDays":                   settings.MaxDays,
+		"monitoringEnabled":         settings.MonitoringEnabled,
+		"globalHotkey":              settings.GlobalHotkey,
+		"previousItemHotkey":        settings.PreviousItemHotkey,
+		"showWindowHotkey":          settings.ShowWindowHotkey,
+		"forgetHotkey":              settings.ForgetHotkey,
+		"cycleSortHotkey":           settings.CycleSortHotkey,
+		"captureHotkey":             settings.CaptureHotkey,
+		"stackPasteMode":            settings.StackPasteMode,
+		"previousHotkeyAction":      settings.PreviousHotkeyAction,
+		"autoLaunch":                settings.AutoLaunch,
+		"enableSounds":              settings.EnableSounds,
+		"allowPasswords":            settings.AllowPasswords,
+		"respectSecureInput":        settings.RespectSecureInput,
+		"captureExistingOnStart":    settings.CaptureExistingOnStart,
+		"dedupNormalization":        settings.DedupNormalization,
+		"minContentLength":          settings.MinContentLength,
+		"dedupWindowMs":             settings.DedupWindowMs,
+		"captureTransforms":         settings.CaptureTransforms,
+		"normalizeText":             settings.NormalizeText,
+		"captureAllowlist":          settings.CaptureAllowlist,
+		"ignoreSelfWrites":          settings.IgnoreSelfWrites,
+		"ignoreSignatures":          settings.IgnoreSignatures,
+		"logLevel":                  settings.LogLevel,
+		"logContentPreviews":        settings.LogContentPreviews,
+		"theme":                     settings.Theme,
+		"compressLargeContent":      settings.CompressLargeContent,
+		"skipWhitespaceOnlyChanges": settings.SkipWhitespaceOnlyChanges,
+		"promotePinnedOnRecopy":     settings.PromotePinnedOnRecopy,
+		"imageExtensions":           settings.ImageExtensions,
+		"captureMode":               settings.CaptureMode,
+		"manualCaptureMode":         settings.ManualCaptureMode,
+		"maxImageDimension":         settings.MaxImageDimension,
+		"stripURLTracking":          settings.StripURLTracking,
+		"passwordEntropyThreshold":  settings.PasswordEntropyThreshold,
+		"passwordSkipThreshold":     settings.PasswordSkipThreshold,
 	}
 	a.config.UpdateFromSettings(settingsMap)
+	a.logLevel.Set(a.config.LogLevel)
+
+	if a.config.Theme != previousTheme {
+		runtime.EventsEmit(a.ctx, "theme-changed", a.config.Theme)
+	}
+
+	// Re-register hotkeys now the config holds the new combinations
+	a.hotkeyManager.Stop()
+	a.hotkeyManager = services.NewHotkeyManager()
+	if err := a.setupHotkeys(); err != nil {
+		slog.Error("Failed to re-setup hotkeys after settings update", "err", err)
+	}
+	if err := a.hotkeyManager.Start(); err != nil {
+		slog.Error("Failed to re-start hotkey manager", "err", err)
+	}
 
 	// Update clipboard monitor configuration
 	if a.clipboardMonitor != nil {
@@ -302,20 +907,34 @@ func (a *App) UpdateSettings(settings *models.Settings) error {
 	return nil
 }
 
+// ResetSettingsSection restores one group of settings, e.g. "hotkeys", to
+// its defaults and applies the result like any other settings update
+func (a *App) ResetSettingsSection(section string) error {
+	settings, err := a.db.GetSettings()
+	if err != nil {
+		return err
+	}
+	if err := database.ResetSettingsSection(settings, section); err != nil {
+		return err
+	}
+	return a.UpdateSettings(settings)
+}
+
+// ToggleMonitoring pauses or resumes capturing and saves the choice. The
+// monitor announces the change with a "monitor-state" event.
 func (a *App) ToggleMonitoring() bool {
+	a.clipboardMonitor.SetMonitoringEnabled(!a.config.MonitoringEnabled)
 	if a.config.MonitoringEnabled {
-		a.config.MonitoringEnabled = false
-		log.Println("Clipboard monitoring paused")
+		slog.Info("Clipboard monitoring resumed")
 	} else {
-		a.config.MonitoringEnabled = true
-		log.Println("Clipboard monitoring resumed")
+		slog.Info("Clipboard monitoring paused")
 	}
 
 	// Update the setting in database
 	if settings, err := a.db.GetSettings(); err == nil {
 		settings.MonitoringEnabled = a.config.MonitoringEnabled
 		if err := a.db.UpdateSettings(settings); err != nil {
-			log.Printf("Failed to update settings: %v", err)
+			slog.Error("Failed to update settings", "err", err)
 		}
 	}
 
@@ -333,17 +952,52 @@ func (a *App) GetMonitoringStatus() map[string]interface{} {
 		"enabled":         a.config.MonitoringEnabled,
 		"pollingInterval": a.config.PollingInterval.Milliseconds(),
 		"isRunning":       a.clipboardMonitor != nil && a.clipboardMonitor.IsRunning(),
+		"state":           a.monitorState(),
+	}
+}
+
+// monitorState returns the clipboard monitor's state, or "stopped" before
+// it exists
+func (a *App) monitorState() string {
+	if a.clipboardMonitor == nil {
+		return services.MonitorStopped
 	}
+	return a.clipboardMonitor.State()
 }
 
 // ShowMainWindow shows the main application window
 func (a *App) ShowMainWindow() {
-	runtime.WindowShow(a.ctx)
+	a.mainWindow.Show()
+}
+
+// ToggleMainWindow hides the main window if it's frontmost and otherwise
+// brings it forward with the search interface showing
+func (a *App) ToggleMainWindow() {
+	if a.mainWindow.Toggle() {
+		runtime.EventsEmit(a.ctx, "show-search-interface")
+	} else {
+		runtime.EventsEmit(a.ctx, "hide-search-interface")
+	}
+}
+
+// SetMainWindowFocused is called by the frontend when the main window gains
+// or loses focus, so the toggle knows whether it's frontmost
+func (a *App) SetMainWindowFocused(focused bool) {
+	a.mainWindow.SetFocused(focused)
 }
 
+// wailsWindow drives the main window through the Wails runtime
+type wailsWindow struct {
+	ctx context.Context
+}
+
+func (w wailsWindow) Show()             { runtime.WindowShow(w.ctx) }
+func (w wailsWindow) Hide()             { runtime.WindowHide(w.ctx) }
+func (w wailsWindow) IsMinimised() bool { return runtime.WindowIsMinimised(w.ctx) }
+
 // ShowPreferences shows the preferences window
 func (a *App) ShowPreferences() {
-	runtime.WindowShow(a.ctx)
+	a.mainWindow.Show()
 	// The frontend will handle showing the preferences modal
 	runtime.EventsEmit(a.ctx, "show-preferences")
 }
@@ -360,3 +1014,14 @@ func (a *App) GetRecentItems(limit int) ([]models.ClipboardItem, error) {
 	}
 	return a.db.GetClipboardItems(limit, 0, "", "recent") // Get recent items, all types
 }
+
+// GetStatistics returns item counts and total stored size
+func (a *App) GetStatistics() (*models.Statistics, error) {
+	return a.db.GetStatistics()
+}
+
+// GetCaptureCountsByDay returns per-day capture counts for the last days
+// days, oldest first, for a usage sparkline
+func (a *App) GetCaptureCountsByDay(days int) ([]models.DayCount, error) {
+	return a.db.GetCaptureCountsByDay(days)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// ParseCaptureAllowlist splits a newline-separated allowlist into entries.
+// An entry wrapped in slashes, like /^test-[0-9]+$/, is a regular expression;
+// anything else must match the trimmed content exactly. Invalid expressions
+// are dropped with a warning.
+func ParseCaptureAllowlist(list string) []string {
+	var entries []string
+	for i, entry := range strings.Split(list, "\n") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if pattern, ok := allowlistPattern(entry); ok {
+			if _, err := regexp.Compile(pattern); err != nil {
+				// Entries may be credentials themselves, so only say where
+				slog.Warn("Ignoring invalid capture allowlist pattern", "line", i+1)
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// isAllowlisted reports whether content matches an entry of CaptureAllowlist
+func (c *Config) isAllowlisted(content string) bool {
+	content = strings.TrimSpace(content)
+	for _, entry := range c.CaptureAllowlist {
+		pattern, ok := allowlistPattern(entry)
+		if !ok {
+			if content == entry {
+				return true
+			}
+			continue
+		}
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowlistPattern returns the expression inside a /.../ entry
+func allowlistPattern(entry string) (string, bool) {
+	if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+		return entry[1 : len(entry)-1], true
+	}
+	return "", false
+}
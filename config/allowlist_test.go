@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCaptureAllowlist(t *testing.T) {
+	list := "TestUser#2024!\n\n  /^staging-[A-Za-z0-9!]+$/  \n/[unclosed/\n"
+	assert.Equal(t, []string{"TestUser#2024!", "/^staging-[A-Za-z0-9!]+$/"}, ParseCaptureAllowlist(list))
+	assert.Empty(t, ParseCaptureAllowlist(""))
+}
+
+func TestShouldSkipContentAllowlist(t *testing.T) {
+	cfg := NewConfig()
+	cfg.CaptureAllowlist = ParseCaptureAllowlist("TestUser#2024!\n/^staging-[A-Za-z0-9!]+$/")
+
+	tests := []struct {
+		content  string
+		expected bool
+		desc     string
+	}{
+		{"TestUser#2024!", false, "exact allowlisted credential is captured"},
+		{"  TestUser#2024!\n", false, "surrounding whitespace is ignored"},
+		{"staging-Pa55word!", false, "pattern-allowlisted credential is captured"},
+		{"TestUser#2025!", true, "near miss is still skipped"},
+		{"Password123!", true, "unlisted password is still skipped"},
+		{"", true, "empty content is still skipped"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, cfg.ShouldSkipContent(test.content), test.desc)
+	}
+}
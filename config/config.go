@@ -3,46 +3,156 @@ package config
 import (
 	"crypto/sha256"
 	"fmt"
+	"log/slog"
+	"math"
 	"strings"
 	"time"
 
 	"regexp"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Config holds runtime configuration for the clipboard manager
 type Config struct {
-	PollingInterval   time.Duration
-	MaxItems          int
-	MaxDays           int
-	MonitoringEnabled bool
-	GlobalHotkey      string
-	PreviousHotkey    string
-	AutoLaunch        bool
-	EnableSounds      bool
-	AllowPasswords    bool
+	PollingInterval           time.Duration
+	MaxItems                  int
+	MaxItemsPerApp            int // Unpinned items kept per source app; 0 means no per-app limit
+	MaxDays                   int
+	MonitoringEnabled         bool
+	GlobalHotkey              string
+	PreviousHotkey            string
+	ShowWindowHotkey          string
+	ForgetHotkey              string // Clears the clipboard and newest item; empty leaves it unbound
+	CycleSortHotkey           string // Switches to the next sort mode; empty leaves it unbound
+	CaptureHotkey             string // Captures the clipboard on demand; empty leaves it unbound
+	StackPasteMode            bool   // The previous-item hotkey pastes and deletes the newest item instead of cycling
+	PreviousHotkeyAction      string // What the previous-item hotkey does: PreviousActionCopy, PreviousActionPaste or PreviousActionShow
+	AutoLaunch                bool
+	EnableSounds              bool
+	AllowPasswords            bool
+	RespectSecureInput        bool
+	MinContentLength          int               // Minimum trimmed length in characters; 0 captures everything
+	DedupWindow               time.Duration     // Identical content seen again within this window is ignored; 0 disables
+	CaptureTransforms         []string          // Normalizations applied in order before hashing, e.g. "crlf-to-lf"
+	NormalizeText             bool              // Apply NormalizeText after CaptureTransforms, keeping the original
+	CaptureMode               string            // CaptureModeAll, CaptureModeMultilineOnly or CaptureModeSingleLineOnly
+	ManualCaptureMode         bool              // Only capture on request (CaptureNow) instead of polling
+	MaxImageDimension         int               // Inline images with a longer edge are downscaled on capture; 0 keeps them as is
+	StripURLTracking          bool              // Store copied URLs without tracking query parameters, keeping the original
+	TrackingParams            []string          // Query parameters StripURLTracking removes; a trailing "*" matches any suffix
+	EventCoalesceWindow       time.Duration     // Captures within this window are announced by one "items-changed" event; 0 sends one each
+	PasswordEntropyThreshold  float64           // Bits per character above which a two-class token counts as a password; 0 disables
+	PasswordSkipThreshold     float64           // Password confidence (0-1) at which content counts as a password; 0 means the default
+	CaptureAllowlist          []string          // Exact strings or /regex/ entries captured even when they look like passwords
+	IgnoreSelfWrites          bool              // Content Klipd recently wrote to the clipboard isn't captured when it comes back
+	IgnoreSignatures          []IgnoreSignature // Content containing any of these strings or /regex/ matches is never captured
+	LogLevel                  slog.Level        // Least severe level that gets logged
+	LogContentPreviews        bool              // Include a short content preview in log lines; otherwise only type and size
+	ImageExtensions           []string          // File extensions treated as images; nil means DefaultImageExtensions
+	CaptureExistingOnStart    bool              // Capture what is already on the clipboard when monitoring starts
+	DedupNormalization        string            // DedupExact, DedupTrim or DedupTrimLower
+	Theme                     string            // ThemeAuto, ThemeLight or ThemeDark
+	CompressLargeContent      bool              // Store large text items gzipped; previews stay plain for search
+	SkipWhitespaceOnlyChanges bool              // Ignore content differing from the previous capture only in whitespace
+	PromotePinnedOnRecopy     bool              // Re-copying a pinned item's content moves it to the top of the pinned group
 }
 
+// Themes the interface can use; ThemeAuto follows the system appearance
+const (
+	ThemeAuto  = "auto"
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+)
+
+// ValidateTheme reports an error for anything but ThemeAuto, ThemeLight or
+// ThemeDark
+func ValidateTheme(theme string) error {
+	switch theme {
+	case ThemeAuto, ThemeLight, ThemeDark:
+		return nil
+	default:
+		return fmt.Errorf("unknown theme: %s", theme)
+	}
+}
+
+// Actions the previous-item hotkey can take
+const (
+	PreviousActionCopy  = "copy"  // Copy the item to the clipboard
+	PreviousActionPaste = "paste" // Copy the item, then paste it into the frontmost app
+	PreviousActionShow  = "show"  // Open the newest item in the main window
+)
+
+// ValidatePreviousHotkeyAction reports an error for anything but
+// PreviousActionCopy, PreviousActionPaste or PreviousActionShow
+func ValidatePreviousHotkeyAction(action string) error {
+	switch action {
+	case PreviousActionCopy, PreviousActionPaste, PreviousActionShow:
+		return nil
+	default:
+		return fmt.Errorf("unknown previous-item hotkey action: %s", action)
+	}
+}
+
+// Dedup normalizations deciding which captures count as the same content
+const (
+	DedupExact     = "exact"
+	DedupTrim      = "trim"
+	DedupTrimLower = "trim-lower"
+)
+
+// Capture modes restricting content by line count
+const (
+	CaptureModeAll            = "all"
+	CaptureModeMultilineOnly  = "multiline-only"
+	CaptureModeSingleLineOnly = "single-line-only"
+)
+
 // NewConfig creates a new configuration with default values
 func NewConfig() *Config {
 	return &Config{
-		PollingInterval:   500 * time.Millisecond,
-		MaxItems:          100,
-		MaxDays:           7,
-		MonitoringEnabled: true,
-		GlobalHotkey:      "Cmd+Shift+Space",
-		PreviousHotkey:    "Cmd+Shift+C",
-		AutoLaunch:        true,
-		EnableSounds:      false,
-		AllowPasswords:    false,
+		PollingInterval:          500 * time.Millisecond,
+		MaxItems:                 100,
+		MaxDays:                  7,
+		MonitoringEnabled:        true,
+		GlobalHotkey:             "Cmd+Shift+Space",
+		PreviousHotkey:           "Cmd+Shift+C",
+		ShowWindowHotkey:         "Cmd+Shift+K",
+		PreviousHotkeyAction:     PreviousActionCopy,
+		AutoLaunch:               true,
+		EnableSounds:             false,
+		AllowPasswords:           false,
+		RespectSecureInput:       true,
+		MinContentLength:         0,
+		DedupWindow:              time.Second,
+		CaptureMode:              CaptureModeAll,
+		TrackingParams:           DefaultTrackingParams,
+		IgnoreSelfWrites:         true,
+		EventCoalesceWindow:      200 * time.Millisecond,
+		PasswordEntropyThreshold: DefaultPasswordEntropyThreshold,
+		PasswordSkipThreshold:    DefaultPasswordSkipThreshold,
+		LogLevel:                 slog.LevelInfo,
+		ImageExtensions:          DefaultImageExtensions,
+		CaptureExistingOnStart:   true,
+		DedupNormalization:       DedupExact,
+		Theme:                    ThemeAuto,
 	}
 }
 
+// DefaultPasswordSkipThreshold is the password confidence at or above which
+// content is skipped or, when passwords are allowed, encrypted
+const DefaultPasswordSkipThreshold = 0.5
+
+// DefaultPasswordEntropyThreshold is high enough that ordinary words with a
+// number appended stay below it, while random 16-character tokens clear it
+const DefaultPasswordEntropyThreshold = 3.7
+
 var (
 	// Common patterns that are NOT passwords
 	urlRegex      = regexp.MustCompile(`^https?://|^ftp://|^www\.`)
 	filePathRegex = regexp.MustCompile(`^[a-zA-Z]:[\\\/]|^\/[^\/]|^\.\/|^\.\.\/|^\~\/`)
 	emailRegex    = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	hexColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{8}$`)
 
 	// Programming/code patterns
 	functionCallRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*\.[a-zA-Z_][a-zA-Z0-9_]*\(.*\)$`)
@@ -76,6 +186,9 @@ func (c *Config) UpdateFromSettings(settings map[string]interface{}) {
 	if val, ok := settings["maxItems"].(int); ok {
 		c.MaxItems = val
 	}
+	if val, ok := settings["maxItemsPerApp"].(int); ok {
+		c.MaxItemsPerApp = val
+	}
 	if val, ok := settings["maxDays"].(int); ok {
 		c.MaxDays = val
 	}
@@ -88,6 +201,24 @@ func (c *Config) UpdateFromSettings(settings map[string]interface{}) {
 	if val, ok := settings["previousItemHotkey"].(string); ok {
 		c.PreviousHotkey = val
 	}
+	if val, ok := settings["showWindowHotkey"].(string); ok {
+		c.ShowWindowHotkey = val
+	}
+	if val, ok := settings["forgetHotkey"].(string); ok {
+		c.ForgetHotkey = val
+	}
+	if val, ok := settings["cycleSortHotkey"].(string); ok {
+		c.CycleSortHotkey = val
+	}
+	if val, ok := settings["captureHotkey"].(string); ok {
+		c.CaptureHotkey = val
+	}
+	if val, ok := settings["stackPasteMode"].(bool); ok {
+		c.StackPasteMode = val
+	}
+	if val, ok := settings["previousHotkeyAction"].(string); ok && val != "" {
+		c.PreviousHotkeyAction = val
+	}
 	if val, ok := settings["autoLaunch"].(bool); ok {
 		c.AutoLaunch = val
 	}
@@ -97,6 +228,127 @@ func (c *Config) UpdateFromSettings(settings map[string]interface{}) {
 	if val, ok := settings["allowPasswords"].(bool); ok {
 		c.AllowPasswords = val
 	}
+	if val, ok := settings["respectSecureInput"].(bool); ok {
+		c.RespectSecureInput = val
+	}
+	if val, ok := settings["minContentLength"].(int); ok {
+		c.MinContentLength = val
+	}
+	if val, ok := settings["dedupWindowMs"].(int); ok {
+		c.DedupWindow = time.Duration(val) * time.Millisecond
+	}
+	if val, ok := settings["maxImageDimension"].(int); ok {
+		c.MaxImageDimension = val
+	}
+	if val, ok := settings["captureMode"].(string); ok {
+		c.CaptureMode = val
+	}
+	if val, ok := settings["manualCaptureMode"].(bool); ok {
+		c.ManualCaptureMode = val
+	}
+	if val, ok := settings["passwordEntropyThreshold"].(float64); ok {
+		c.PasswordEntropyThreshold = val
+	}
+	if val, ok := settings["passwordSkipThreshold"].(float64); ok {
+		c.PasswordSkipThreshold = val
+	}
+	if val, ok := settings["stripURLTracking"].(bool); ok {
+		c.StripURLTracking = val
+	}
+	if val, ok := settings["skipWhitespaceOnlyChanges"].(bool); ok {
+		c.SkipWhitespaceOnlyChanges = val
+	}
+	if val, ok := settings["promotePinnedOnRecopy"].(bool); ok {
+		c.PromotePinnedOnRecopy = val
+	}
+	if val, ok := settings["compressLargeContent"].(bool); ok {
+		c.CompressLargeContent = val
+	}
+	if val, ok := settings["theme"].(string); ok {
+		c.Theme = val
+	}
+	if val, ok := settings["dedupNormalization"].(string); ok {
+		c.DedupNormalization = val
+	}
+	if val, ok := settings["captureExistingOnStart"].(bool); ok {
+		c.CaptureExistingOnStart = val
+	}
+	if val, ok := settings["imageExtensions"].(string); ok {
+		c.ImageExtensions = ParseImageExtensions(val)
+	}
+	if val, ok := settings["logContentPreviews"].(bool); ok {
+		c.LogContentPreviews = val
+	}
+	if val, ok := settings["logLevel"].(string); ok {
+		c.LogLevel = ParseLogLevel(val)
+	}
+	if val, ok := settings["captureAllowlist"].(string); ok {
+		c.CaptureAllowlist = ParseCaptureAllowlist(val)
+	}
+	if val, ok := settings["ignoreSelfWrites"].(bool); ok {
+		c.IgnoreSelfWrites = val
+	}
+	if val, ok := settings["ignoreSignatures"].(string); ok {
+		c.IgnoreSignatures = ParseIgnoreSignatures(val)
+	}
+	if val, ok := settings["captureTransforms"].(string); ok {
+		c.CaptureTransforms = ParseCaptureTransforms(val)
+	}
+	if val, ok := settings["normalizeText"].(bool); ok {
+		c.NormalizeText = val
+	}
+}
+
+// DedupKey returns the form of content that duplicate detection compares.
+// Unknown normalizations compare content exactly.
+func (c *Config) DedupKey(content string) string {
+	switch c.DedupNormalization {
+	case DedupTrim:
+		return strings.TrimSpace(content)
+	case DedupTrimLower:
+		return strings.ToLower(strings.TrimSpace(content))
+	default:
+		return content
+	}
+}
+
+// WhitespaceOnlyChange reports whether content differs from previous, but
+// only in whitespace: indentation, spacing or line endings
+func WhitespaceOnlyChange(previous string, content string) bool {
+	if previous == "" || previous == content {
+		return false
+	}
+	return stripWhitespace(previous) == stripWhitespace(content)
+}
+
+func stripWhitespace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// ParseLogLevel maps "debug", "info", "warn" or "error" (any case) to a log
+// level. Anything else falls back to info.
+func ParseLogLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// ContentLogAttrs describes an item for a log line as slog key/value pairs:
+// its type and size in bytes, plus a short preview only if LogContentPreviews
+// is on. The size is passed separately since previews are truncated.
+func (c *Config) ContentLogAttrs(contentType string, size int, preview string) []any {
+	attrs := []any{"type", contentType, "size", size}
+	if c.LogContentPreviews {
+		attrs = append(attrs, "preview", TruncatePreview(preview, 50))
+	}
+	return attrs
 }
 
 // ContentType represents the type of clipboard content
@@ -106,6 +358,9 @@ const (
 	ContentTypeText ContentType = iota
 	ContentTypeImage
 	ContentTypeFile
+	ContentTypeJSON
+	ContentTypeColor
+	ContentTypeTable
 )
 
 func (ct ContentType) String() string {
@@ -116,58 +371,217 @@ func (ct ContentType) String() string {
 		return "image"
 	case ContentTypeFile:
 		return "file"
+	case ContentTypeJSON:
+		return "json"
+	case ContentTypeColor:
+		return "color"
+	case ContentTypeTable:
+		return "table"
 	default:
 		return "unknown"
 	}
 }
 
-func ParseContentType(s string) ContentType {
-	switch strings.ToLower(s) {
+// contentTypeAliases maps what people type into the type filter to the
+// content type they mean
+var contentTypeAliases = map[string]ContentType{
+	"txt":         ContentTypeText,
+	"plain":       ContentTypeText,
+	"string":      ContentTypeText,
+	"img":         ContentTypeImage,
+	"pic":         ContentTypeImage,
+	"picture":     ContentTypeImage,
+	"photo":       ContentTypeImage,
+	"screenshot":  ContentTypeImage,
+	"png":         ContentTypeImage,
+	"jpg":         ContentTypeImage,
+	"jpeg":        ContentTypeImage,
+	"gif":         ContentTypeImage,
+	"doc":         ContentTypeFile,
+	"document":    ContentTypeFile,
+	"path":        ContentTypeFile,
+	"folder":      ContentTypeFile,
+	"colour":      ContentTypeColor,
+	"hex":         ContentTypeColor,
+	"rgb":         ContentTypeColor,
+	"csv":         ContentTypeTable,
+	"tsv":         ContentTypeTable,
+	"spreadsheet": ContentTypeTable,
+}
+
+// lookupContentType matches a content type name or alias, ignoring case and
+// surrounding space
+func lookupContentType(s string) (ContentType, bool) {
+	switch name := strings.ToLower(strings.TrimSpace(s)); name {
 	case "text":
-		return ContentTypeText
-	// TODO
+		return ContentTypeText, true
 	case "image":
-		return ContentTypeImage
+		return ContentTypeImage, true
 	case "file":
-		return ContentTypeFile
+		return ContentTypeFile, true
+	case "json":
+		return ContentTypeJSON, true
+	case "color":
+		return ContentTypeColor, true
+	case "table":
+		return ContentTypeTable, true
 	default:
-		return ContentTypeText
+		ct, ok := contentTypeAliases[name]
+		return ct, ok
+	}
+}
+
+// ParseContentType reads a content type name or one of its aliases, e.g.
+// "img" for image. Anything unrecognized is text.
+func ParseContentType(s string) ContentType {
+	if ct, ok := lookupContentType(s); ok {
+		return ct
+	}
+	return ContentTypeText
+}
+
+// ResolveContentTypeFilter turns a type filter as typed by the user into the
+// stored content type, so "img" lists images. Filters that aren't a known
+// name or alias are returned as is to be matched exactly, and "" still means
+// every type.
+func ResolveContentTypeFilter(filter string) string {
+	if ct, ok := lookupContentType(filter); ok {
+		return ct.String()
 	}
+	return filter
 }
 
+// GenerateHash returns the hex SHA-256 of content. It's the one hash used
+// for captures, dedup and self-write tracking, so they always agree.
 func GenerateHash(content string) string {
-	hash := sha256.Sum256([]byte(content))
+	return hashBytes([]byte(content))
+}
+
+// HashContent hashes an item's content: the decoded bytes of an image that
+// has them, otherwise its text. The same picture can arrive as data URIs
+// with different prefixes, so for images the bytes are what identify it.
+func HashContent(contentType string, text string, binary []byte) string {
+	if contentType == ContentTypeImage.String() && len(binary) > 0 {
+		return hashBytes(binary)
+	}
+	return GenerateHash(text)
+}
+
+func hashBytes(data []byte) string {
+	hash := sha256.Sum256(data)
 	return fmt.Sprintf("%x", hash)
 }
 
-// TruncatePreview creates a preview text with specified length
+const (
+	// DefaultEllipsis marks where TruncatePreview cut text short
+	DefaultEllipsis = "..."
+	// DefaultMinBreakFraction is how far into the preview TruncatePreview's
+	// last word break has to be for it to cut there rather than mid-word
+	DefaultMinBreakFraction = 0.5
+)
+
+// TruncatePreview creates a preview text of at most maxLength characters
+// (runes, not bytes) plus "...". The cut never splits a character or
+// separates one from the combining marks that follow it.
 func TruncatePreview(text string, maxLength int) string {
-	if len(text) <= maxLength {
+	return TruncatePreviewWith(text, maxLength, DefaultEllipsis, DefaultMinBreakFraction)
+}
+
+// TruncatePreviewWith is TruncatePreview with a choice of ellipsis and of how
+// far (as a fraction of maxLength) the last space or newline has to be for
+// the cut to move back to it; 1 always cuts at maxLength.
+func TruncatePreviewWith(text string, maxLength int, ellipsis string, minBreakFraction float64) string {
+	if utf8.RuneCountInString(text) <= maxLength {
 		return text
 	}
 
+	runes := []rune(text)
+	cut := maxLength
+	for cut > 0 && unicode.Is(unicode.M, runes[cut]) {
+		cut--
+	}
+
 	// Find a good break point (space, newline, etc.)
-	truncated := text[:maxLength]
-	lastSpace := strings.LastIndex(truncated, " ")
-	lastNewline := strings.LastIndex(truncated, "\n")
+	truncated := runes[:cut]
+	breakPoint := -1
+	for i := len(truncated) - 1; i >= 0; i-- {
+		if truncated[i] == ' ' || truncated[i] == '\n' {
+			breakPoint = i
+			break
+		}
+	}
 
-	breakPoint := lastSpace
-	if lastNewline > lastSpace {
-		breakPoint = lastNewline
+	if float64(breakPoint) > float64(maxLength)*minBreakFraction {
+		return string(runes[:breakPoint]) + ellipsis
 	}
 
-	if breakPoint > maxLength/2 {
-		return text[:breakPoint] + "..."
+	return string(truncated) + ellipsis
+}
+
+// TruncateMiddle shortens text to at most maxLength characters plus ellipsis
+// by cutting out its middle ("start…end"), which suits paths and URLs whose
+// end matters as much as their start. The end gets the odd character, and
+// neither cut separates a character from its combining marks.
+func TruncateMiddle(text string, maxLength int, ellipsis string) string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
 	}
 
-	return text[:maxLength] + "..."
+	runes := []rune(text)
+	head := maxLength / 2
+	for head > 0 && unicode.Is(unicode.M, runes[head]) {
+		head--
+	}
+	tail := len(runes) - (maxLength - maxLength/2)
+	for tail < len(runes) && unicode.Is(unicode.M, runes[tail]) {
+		tail++
+	}
+
+	return string(runes[:head]) + ellipsis + string(runes[tail:])
 }
 
+// DefaultImageExtensions are the file extensions treated as images unless
+// ImageExtensions is configured
+var DefaultImageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".tiff", ".svg"}
+
+// IsImageFormat reports whether filename has one of DefaultImageExtensions
 func IsImageFormat(filename string) bool {
-	extensions := []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".tiff", ".svg"}
+	return hasExtension(filename, DefaultImageExtensions)
+}
+
+// IsImageFormat reports whether filename has one of the configured
+// ImageExtensions, or of DefaultImageExtensions if none are configured
+func (c *Config) IsImageFormat(filename string) bool {
+	if c.ImageExtensions == nil {
+		return IsImageFormat(filename)
+	}
+	return hasExtension(filename, c.ImageExtensions)
+}
+
+// ParseImageExtensions splits a comma-separated extension list, adding the
+// leading dot where it's missing. An empty list gives DefaultImageExtensions.
+func ParseImageExtensions(list string) []string {
+	extensions := []string{}
+	for _, ext := range strings.Split(list, ",") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" || ext == "." {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		extensions = append(extensions, ext)
+	}
+	if len(extensions) == 0 {
+		return DefaultImageExtensions
+	}
+	return extensions
+}
+
+func hasExtension(filename string, extensions []string) bool {
 	lower := strings.ToLower(filename)
 	for _, ext := range extensions {
-		if strings.HasSuffix(lower, ext) {
+		if strings.HasSuffix(lower, strings.ToLower(ext)) {
 			return true
 		}
 	}
@@ -182,7 +596,22 @@ func (c *Config) CleanupInterval() time.Duration {
 // ShouldSkipContent determines if content should be skipped from clipboard monitoring
 func (c *Config) ShouldSkipContent(content string) bool {
 	// Skip empty content
-	if strings.TrimSpace(content) == "" {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return true
+	}
+
+	// Skip fragments shorter than the configured minimum
+	if c.MinContentLength > 0 && utf8.RuneCountInString(trimmed) < c.MinContentLength {
+		return true
+	}
+
+	// Skip content on the wrong side of the capture mode; unknown modes capture all
+	multiline := strings.Contains(trimmed, "\n")
+	if c.CaptureMode == CaptureModeMultilineOnly && !multiline {
+		return true
+	}
+	if c.CaptureMode == CaptureModeSingleLineOnly && multiline {
 		return true
 	}
 
@@ -191,78 +620,114 @@ func (c *Config) ShouldSkipContent(content string) bool {
 		return true
 	}
 
-	// Skip content that looks like passwords (simple heuristic) unless allowed
-	if !c.AllowPasswords && isLikelyPassword(content) {
+	// Skip content marked as coming from another clipboard tool
+	if c.hasIgnoreSignature(content) {
+		return true
+	}
+
+	// Skip content that looks like passwords (simple heuristic) unless allowed,
+	// either wholesale or for allowlisted content
+	if !c.AllowPasswords && !c.isAllowlisted(content) && c.PasswordConfidence(content) >= c.passwordSkipThreshold() {
 		return true
 	}
 
 	return false
 }
 
-func isLikelyPassword(content string) bool {
+// IsSensitiveContent reports whether content looks like a secret, using the
+// same heuristics that ShouldSkipContent applies when passwords are disallowed
+func (c *Config) IsSensitiveContent(content string) bool {
+	return c.PasswordConfidence(content) >= c.passwordSkipThreshold()
+}
+
+// PasswordConfidence returns how sure the password heuristics are, from 0 to
+// 1, that content is a secret
+func (c *Config) PasswordConfidence(content string) float64 {
+	return passwordConfidence(content, c.PasswordEntropyThreshold)
+}
+
+// passwordSkipThreshold returns PasswordSkipThreshold, or the default if it
+// is unset; a threshold of 0 would treat everything as a password
+func (c *Config) passwordSkipThreshold() float64 {
+	if c.PasswordSkipThreshold <= 0 {
+		return DefaultPasswordSkipThreshold
+	}
+	return c.PasswordSkipThreshold
+}
+
+// passwordConfidence scores how likely content is a password from 0 to 1.
+// Structural checks rule out URLs, paths, code and the like outright; what
+// remains is scored on its character classes, falling back to the Shannon
+// entropy (bits per character) for tokens with only two classes, with a
+// bonus for random-looking, longer tokens. An entropyThreshold of 0 leaves
+// two-class tokens below DefaultPasswordSkipThreshold.
+func passwordConfidence(content string, entropyThreshold float64) float64 {
 	content = strings.TrimSpace(content)
 
 	// Basic length checks
 	if len(content) < 8 || len(content) > 128 {
-		return false
+		return 0
 	}
 
 	// Check for whitespace (passwords usually don't have spaces/tabs/newlines)
 	if strings.ContainsAny(content, " \n\t\r") {
-		return false
+		return 0
 	}
 
 	// Check if it's a URL
 	if urlRegex.MatchString(content) {
-		return false
+		return 0
 	}
 
 	// Check if it's a file path
 	if filePathRegex.MatchString(content) {
-		return false
+		return 0
 	}
 
 	// Check if it's an email
 	if emailRegex.MatchString(content) {
-		return false
+		return 0
+	}
+
+	// Check if it's a #RRGGBBAA color (shorter ones never reach the length check)
+	if hexColorRegex.MatchString(content) {
+		return 0
 	}
 
 	// Check if it's a function call (like "robotgo.Start()")
 	if functionCallRegex.MatchString(content) || methodCallRegex.MatchString(content) {
-		return false
+		return 0
 	}
 
 	// Check if it's a variable/property access
 	if variableRegex.MatchString(content) {
-		return false
+		return 0
 	}
 
 	// Check if it has a file extension
 	if fileExtRegex.MatchString(content) {
-		return false
+		return 0
 	}
 
-	// Check if it's likely Base64
+	// Encoded secrets and API keys are likely, but so are IDs and blobs
 	if len(content) > 20 && len(content)%4 == 0 && base64Regex.MatchString(content) {
-		return true
+		return 0.7
 	}
-
-	// Check if it's an API key
 	if len(content) > 32 && apiKeyRegex.MatchString(content) {
-		return true
+		return 0.7
 	}
 
 	// Check against common non-password strings
 	lowerContent := strings.ToLower(content)
 	for _, nonPassword := range commonNonPasswords {
 		if lowerContent == strings.ToLower(nonPassword) {
-			return false
+			return 0
 		}
 	}
 
 	// Check for programming language keywords/patterns
 	if isProgrammingPattern(content) {
-		return false
+		return 0
 	}
 
 	// Check character complexity
@@ -285,13 +750,50 @@ func isLikelyPassword(content string) bool {
 		charTypes++
 	}
 
-	// Must have at least 3 character types
-	if charTypes < 3 {
-		return false
+	// Three or four character types are convincing on their own; two only
+	// when spread evenly enough to look random (hex is left to the checks
+	// above, since hashes and IDs are copied far more often than hex secrets)
+	entropy := shannonEntropy(content)
+	var score float64
+	switch {
+	case charTypes == 4:
+		score = 0.8
+	case charTypes == 3:
+		score = 0.65
+	case charTypes < 2:
+		return 0.1
+	case entropyThreshold <= 0 || isHexString(content) || entropy < entropyThreshold:
+		return 0.3
+	default:
+		score = DefaultPasswordSkipThreshold
 	}
 
-	// Additional heuristics for password-like content
-	return hasPasswordLikePattern(content)
+	// Random-looking distributions and longer tokens are more convincing
+	score += 0.1*math.Min(entropy/5, 1) + 0.1*math.Min(float64(len(content)-8)/24, 1)
+
+	// Repetition and camelCase point to something typed by hand
+	if !hasPasswordLikePattern(content) {
+		return score * 0.4
+	}
+	return math.Min(score, 1)
+}
+
+// shannonEntropy returns the entropy of the character distribution of s in
+// bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
 }
 
 // Helper function to check for digits using unicode
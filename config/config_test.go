@@ -1,10 +1,14 @@
 package config
 
 import (
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -17,24 +21,79 @@ func TestNewConfig(t *testing.T) {
 	assert.True(t, cfg.MonitoringEnabled)
 	assert.Equal(t, "Cmd+Shift+Space", cfg.GlobalHotkey)
 	assert.Equal(t, "Cmd+Shift+C", cfg.PreviousHotkey)
+	assert.Equal(t, "Cmd+Shift+K", cfg.ShowWindowHotkey)
 	assert.True(t, cfg.AutoLaunch)
 	assert.False(t, cfg.EnableSounds)
 	assert.False(t, cfg.AllowPasswords)
+	assert.True(t, cfg.RespectSecureInput)
+	assert.True(t, cfg.CaptureExistingOnStart)
+	assert.Equal(t, DedupExact, cfg.DedupNormalization)
+	assert.Equal(t, 0, cfg.MinContentLength)
+	assert.Equal(t, time.Second, cfg.DedupWindow)
+	assert.Equal(t, CaptureModeAll, cfg.CaptureMode)
+	assert.False(t, cfg.ManualCaptureMode)
+	assert.Equal(t, 0, cfg.MaxImageDimension)
+	assert.False(t, cfg.StripURLTracking)
+	assert.False(t, cfg.NormalizeText)
+	assert.False(t, cfg.StackPasteMode)
+	assert.Equal(t, PreviousActionCopy, cfg.PreviousHotkeyAction)
+	assert.True(t, cfg.IgnoreSelfWrites)
+	assert.Empty(t, cfg.IgnoreSignatures)
+	assert.Equal(t, DefaultTrackingParams, cfg.TrackingParams)
+	assert.Equal(t, 200*time.Millisecond, cfg.EventCoalesceWindow)
+	assert.Equal(t, DefaultPasswordEntropyThreshold, cfg.PasswordEntropyThreshold)
+	assert.Equal(t, DefaultPasswordSkipThreshold, cfg.PasswordSkipThreshold)
+	assert.Equal(t, slog.LevelInfo, cfg.LogLevel)
+	assert.False(t, cfg.LogContentPreviews)
+	assert.Equal(t, DefaultImageExtensions, cfg.ImageExtensions)
+	assert.Equal(t, ThemeAuto, cfg.Theme)
+	assert.False(t, cfg.CompressLargeContent)
+	assert.False(t, cfg.SkipWhitespaceOnlyChanges)
+	assert.False(t, cfg.PromotePinnedOnRecopy)
+	assert.Equal(t, 0, cfg.MaxItemsPerApp)
 }
 
 func TestUpdateFromSettings(t *testing.T) {
 	cfg := NewConfig()
 
 	settings := map[string]interface{}{
-		"pollingInterval":    1000,
-		"maxItems":           200,
-		"maxDays":            14,
-		"monitoringEnabled":  false,
-		"globalHotkey":       "Ctrl+V",
-		"previousItemHotkey": "Ctrl+Shift+V",
-		"autoLaunch":         false,
-		"enableSounds":       true,
-		"allowPasswords":     true,
+		"pollingInterval":           1000,
+		"maxItems":                  200,
+		"maxDays":                   14,
+		"monitoringEnabled":         false,
+		"globalHotkey":              "Ctrl+V",
+		"previousItemHotkey":        "Ctrl+Shift+V",
+		"showWindowHotkey":          "Ctrl+Alt+K",
+		"autoLaunch":                false,
+		"enableSounds":              true,
+		"allowPasswords":            true,
+		"respectSecureInput":        false,
+		"minContentLength":          5,
+		"dedupWindowMs":             250,
+		"captureMode":               CaptureModeMultilineOnly,
+		"manualCaptureMode":         true,
+		"maxImageDimension":         2048,
+		"stripURLTracking":          true,
+		"normalizeText":             true,
+		"passwordEntropyThreshold":  4.2,
+		"passwordSkipThreshold":     0.75,
+		"captureAllowlist":          "TestUser#2024!\n",
+		"ignoreSelfWrites":          false,
+		"ignoreSignatures":          "[via OtherClip]",
+		"logLevel":                  "debug",
+		"logContentPreviews":        true,
+		"imageExtensions":           "png, HEIC",
+		"captureExistingOnStart":    false,
+		"cycleSortHotkey":           "Ctrl+Alt+S",
+		"captureHotkey":             "Ctrl+Alt+C",
+		"stackPasteMode":            true,
+		"previousHotkeyAction":      "show",
+		"dedupNormalization":        DedupTrimLower,
+		"theme":                     ThemeDark,
+		"compressLargeContent":      true,
+		"skipWhitespaceOnlyChanges": true,
+		"promotePinnedOnRecopy":     true,
+		"maxItemsPerApp":            25,
 	}
 
 	cfg.UpdateFromSettings(settings)
@@ -45,9 +104,38 @@ func TestUpdateFromSettings(t *testing.T) {
 	assert.False(t, cfg.MonitoringEnabled)
 	assert.Equal(t, "Ctrl+V", cfg.GlobalHotkey)
 	assert.Equal(t, "Ctrl+Shift+V", cfg.PreviousHotkey)
+	assert.Equal(t, "Ctrl+Alt+K", cfg.ShowWindowHotkey)
 	assert.False(t, cfg.AutoLaunch)
 	assert.True(t, cfg.EnableSounds)
 	assert.True(t, cfg.AllowPasswords)
+	assert.False(t, cfg.RespectSecureInput)
+	assert.Equal(t, 5, cfg.MinContentLength)
+	assert.Equal(t, 250*time.Millisecond, cfg.DedupWindow)
+	assert.Equal(t, CaptureModeMultilineOnly, cfg.CaptureMode)
+	assert.True(t, cfg.ManualCaptureMode)
+	assert.Equal(t, 2048, cfg.MaxImageDimension)
+	assert.True(t, cfg.StripURLTracking)
+	assert.True(t, cfg.NormalizeText)
+	assert.Equal(t, 4.2, cfg.PasswordEntropyThreshold)
+	assert.Equal(t, 0.75, cfg.PasswordSkipThreshold)
+	assert.Equal(t, []string{"TestUser#2024!"}, cfg.CaptureAllowlist)
+	assert.False(t, cfg.IgnoreSelfWrites)
+	require.Len(t, cfg.IgnoreSignatures, 1)
+	assert.Equal(t, "[via OtherClip]", cfg.IgnoreSignatures[0].Entry)
+	assert.Equal(t, slog.LevelDebug, cfg.LogLevel)
+	assert.True(t, cfg.LogContentPreviews)
+	assert.Equal(t, []string{".png", ".heic"}, cfg.ImageExtensions)
+	assert.False(t, cfg.CaptureExistingOnStart)
+	assert.Equal(t, "Ctrl+Alt+S", cfg.CycleSortHotkey)
+	assert.Equal(t, "Ctrl+Alt+C", cfg.CaptureHotkey)
+	assert.True(t, cfg.StackPasteMode)
+	assert.Equal(t, PreviousActionShow, cfg.PreviousHotkeyAction)
+	assert.Equal(t, DedupTrimLower, cfg.DedupNormalization)
+	assert.Equal(t, ThemeDark, cfg.Theme)
+	assert.True(t, cfg.CompressLargeContent)
+	assert.True(t, cfg.SkipWhitespaceOnlyChanges)
+	assert.True(t, cfg.PromotePinnedOnRecopy)
+	assert.Equal(t, 25, cfg.MaxItemsPerApp)
 }
 
 func TestUpdateFromSettingsPartial(t *testing.T) {
@@ -90,10 +178,71 @@ func TestUpdateFromSettingsInvalidTypes(t *testing.T) {
 	assert.False(t, cfg.EnableSounds)
 }
 
+func TestDedupKey(t *testing.T) {
+	cfg := NewConfig()
+	assert.Equal(t, " Hello \n", cfg.DedupKey(" Hello \n"))
+
+	cfg.DedupNormalization = DedupTrim
+	assert.Equal(t, "Hello", cfg.DedupKey(" Hello \n"))
+
+	cfg.DedupNormalization = DedupTrimLower
+	assert.Equal(t, "hello", cfg.DedupKey(" Hello \n"))
+
+	cfg.DedupNormalization = "fuzzy"
+	assert.Equal(t, " Hello \n", cfg.DedupKey(" Hello \n"))
+}
+
+func TestValidateTheme(t *testing.T) {
+	for _, theme := range []string{ThemeAuto, ThemeLight, ThemeDark} {
+		assert.NoError(t, ValidateTheme(theme))
+	}
+	for _, theme := range []string{"", "Dark", "solarized"} {
+		assert.Error(t, ValidateTheme(theme), theme)
+	}
+}
+
+func TestValidatePreviousHotkeyAction(t *testing.T) {
+	for _, action := range []string{PreviousActionCopy, PreviousActionPaste, PreviousActionShow} {
+		assert.NoError(t, ValidatePreviousHotkeyAction(action))
+	}
+	for _, action := range []string{"", "Copy", "pop"} {
+		assert.Error(t, ValidatePreviousHotkeyAction(action), action)
+	}
+}
+
+func TestWhitespaceOnlyChange(t *testing.T) {
+	assert.True(t, WhitespaceOnlyChange("func main() {\n\treturn\n}", "func main() {\n    return\n}"))
+	assert.True(t, WhitespaceOnlyChange("a b\r\n", "a b\n"))
+	assert.True(t, WhitespaceOnlyChange("hello", " hello "))
+	assert.False(t, WhitespaceOnlyChange("hello", "hello"))
+	assert.False(t, WhitespaceOnlyChange("hello", "hello!"))
+	assert.False(t, WhitespaceOnlyChange("", "   "))
+}
+
+func TestParseLogLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, ParseLogLevel("debug"))
+	assert.Equal(t, slog.LevelInfo, ParseLogLevel("info"))
+	assert.Equal(t, slog.LevelWarn, ParseLogLevel(" WARN "))
+	assert.Equal(t, slog.LevelError, ParseLogLevel("error"))
+	assert.Equal(t, slog.LevelInfo, ParseLogLevel(""))
+	assert.Equal(t, slog.LevelInfo, ParseLogLevel("verbose"))
+}
+
+func TestContentLogAttrs(t *testing.T) {
+	cfg := NewConfig()
+	assert.Equal(t, []any{"type", "text", "size", 4096}, cfg.ContentLogAttrs("text", 4096, "secret plans"))
+
+	cfg.LogContentPreviews = true
+	assert.Equal(t, []any{"type", "text", "size", 4096, "preview", "secret plans"}, cfg.ContentLogAttrs("text", 4096, "secret plans"))
+}
+
 func TestContentTypeString(t *testing.T) {
 	assert.Equal(t, "text", ContentTypeText.String())
 	assert.Equal(t, "image", ContentTypeImage.String())
 	assert.Equal(t, "file", ContentTypeFile.String())
+	assert.Equal(t, "json", ContentTypeJSON.String())
+	assert.Equal(t, "color", ContentTypeColor.String())
+	assert.Equal(t, "table", ContentTypeTable.String())
 
 	// Test unknown content type
 	var unknown ContentType = 99
@@ -107,8 +256,45 @@ func TestParseContentType(t *testing.T) {
 	assert.Equal(t, ContentTypeImage, ParseContentType("IMAGE"))
 	assert.Equal(t, ContentTypeFile, ParseContentType("file"))
 	assert.Equal(t, ContentTypeFile, ParseContentType("FILE"))
+	assert.Equal(t, ContentTypeJSON, ParseContentType("json"))
+	assert.Equal(t, ContentTypeColor, ParseContentType("color"))
+	assert.Equal(t, ContentTypeTable, ParseContentType("table"))
 	assert.Equal(t, ContentTypeText, ParseContentType("unknown"))
 	assert.Equal(t, ContentTypeText, ParseContentType(""))
+
+	// Aliases
+	assert.Equal(t, ContentTypeImage, ParseContentType("img"))
+	assert.Equal(t, ContentTypeImage, ParseContentType(" Pic "))
+	assert.Equal(t, ContentTypeText, ParseContentType("txt"))
+	assert.Equal(t, ContentTypeFile, ParseContentType("doc"))
+	assert.Equal(t, ContentTypeColor, ParseContentType("colour"))
+	assert.Equal(t, ContentTypeTable, ParseContentType("CSV"))
+}
+
+func TestResolveContentTypeFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{"", ""},
+		{"image", "image"},
+		{"img", "image"},
+		{"PIC", "image"},
+		{"screenshot", "image"},
+		{"txt", "text"},
+		{"doc", "file"},
+		{"path", "file"},
+		{"hex", "color"},
+		{"tsv", "table"},
+		{" JSON ", "json"},
+		// Unknown filters are matched exactly, as before
+		{"markdown", "markdown"},
+		{"Weird", "Weird"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ResolveContentTypeFilter(tt.filter), "filter %q", tt.filter)
+	}
 }
 
 func TestGenerateHash(t *testing.T) {
@@ -134,6 +320,45 @@ func TestGenerateHash(t *testing.T) {
 	assert.Equal(t, hash1, GenerateHash(content1))
 }
 
+func TestHashContent(t *testing.T) {
+	data := []byte{0x89, 'P', 'N', 'G'}
+
+	// Text, and images without bytes, hash their text like GenerateHash
+	assert.Equal(t, GenerateHash("hello"), HashContent("text", "hello", nil))
+	assert.Equal(t, GenerateHash("hello"), HashContent("text", "hello", data))
+	assert.Equal(t, GenerateHash("data:image/png;base64,iVBO"), HashContent("image", "data:image/png;base64,iVBO", nil))
+
+	// Images with bytes hash those, whatever text they came with
+	assert.Equal(t, HashContent("image", "", data), HashContent("image", "data:image/png;base64,iVBO", data))
+	assert.NotEqual(t, GenerateHash("data:image/png;base64,iVBO"), HashContent("image", "data:image/png;base64,iVBO", data))
+}
+
+func TestTruncatePreviewCountsRunes(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		expected  string
+	}{
+		{"CJK within limit", "你好世界", 4, "你好世界"},
+		{"CJK cut by characters", "剪贴板历史记录管理器", 4, "剪贴板历..."},
+		{"CJK breaks at space", "剪贴板 历史记录管理器", 5, "剪贴板..."},
+		{"emoji", "🎉🎉🎉🎉🎉🎉", 3, "🎉🎉🎉..."},
+		{"emoji after ASCII", "ok 👍👍👍👍👍👍", 5, "ok 👍👍..."},
+		{"combining mark kept with its base", "cafe\u0301 au lait", 5, "cafe\u0301..."},
+		{"combining mark at the cut backs off", "cafe\u0301s", 4, "caf..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TruncatePreview(tt.text, tt.maxLength)
+			assert.Equal(t, tt.expected, result)
+			assert.True(t, utf8.ValidString(result), "preview must be valid UTF-8")
+			assert.LessOrEqual(t, utf8.RuneCountInString(strings.TrimSuffix(result, "...")), tt.maxLength)
+		})
+	}
+}
+
 func TestTruncatePreview(t *testing.T) {
 	tests := []struct {
 		text      string
@@ -164,6 +389,53 @@ func TestTruncatePreview(t *testing.T) {
 	}
 }
 
+func TestTruncatePreviewWith(t *testing.T) {
+	text := "This is a very long text that needs to be truncated"
+
+	// The defaults reproduce TruncatePreview
+	assert.Equal(t, TruncatePreview(text, 20), TruncatePreviewWith(text, 20, DefaultEllipsis, DefaultMinBreakFraction))
+
+	assert.Equal(t, "This is a very long\u2026", TruncatePreviewWith(text, 20, "\u2026", DefaultMinBreakFraction))
+	assert.Equal(t, "This is a very long", TruncatePreviewWith(text, 20, "", DefaultMinBreakFraction))
+	assert.Equal(t, "This is a very long [more]", TruncatePreviewWith(text, 20, " [more]", DefaultMinBreakFraction))
+
+	// A break fraction of 1 never moves the cut back to a space
+	assert.Equal(t, "This is a very long ...", TruncatePreviewWith(text, 20, "...", 1))
+	assert.Equal(t, "No spaces in th...", TruncatePreviewWith("No spaces in this verylongtext", 15, "...", 0.9))
+	assert.Equal(t, "No spaces in...", TruncatePreviewWith("No spaces in this verylongtext", 15, "...", 0.5))
+
+	// Still never splits a character from its combining mark
+	assert.Equal(t, "caf~", TruncatePreviewWith("cafe\u0301s", 4, "~", DefaultMinBreakFraction))
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		ellipsis  string
+		expected  string
+	}{
+		{"Short text kept", "/tmp/a.txt", 20, "\u2026", "/tmp/a.txt"},
+		{"Exact length kept", "0123456789", 10, "\u2026", "0123456789"},
+		{"Path", "/Users/me/Projects/klipd/docs/report.pdf", 20, "\u2026", "/Users/me/\u2026report.pdf"},
+		{"End gets the odd character", "abcdefghij", 5, "..", "ab..hij"},
+		{"Custom ellipsis", "https://example.com/a/very/long/path/page.html", 24, " ... ", "https://exam ... th/page.html"},
+		{"Runes not bytes", "剪贴板历史记录管理器", 4, "\u2026", "剪贴\u2026理器"},
+		{"Combining mark at the head cut backs off", "cafe\u0301 au lait", 8, "\u2026", "caf\u2026lait"},
+		{"Combining mark at the tail cut moves on", "abcde\u0301x", 4, "\u2026", "ab\u2026x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TruncateMiddle(tt.text, tt.maxLength, tt.ellipsis)
+			assert.Equal(t, tt.expected, result)
+			assert.True(t, utf8.ValidString(result))
+			assert.LessOrEqual(t, utf8.RuneCountInString(result), tt.maxLength+utf8.RuneCountInString(tt.ellipsis))
+		})
+	}
+}
+
 func TestIsImageFormat(t *testing.T) {
 	tests := []struct {
 		filename string
@@ -195,6 +467,28 @@ func TestIsImageFormat(t *testing.T) {
 	}
 }
 
+func TestConfigIsImageFormat(t *testing.T) {
+	cfg := NewConfig()
+	assert.False(t, cfg.IsImageFormat("IMG_0042.HEIC"))
+	assert.True(t, cfg.IsImageFormat("photo.png"))
+
+	// Adding formats to the built-in list
+	cfg.ImageExtensions = ParseImageExtensions(strings.Join(DefaultImageExtensions, ",") + ",heic,.avif")
+	assert.True(t, cfg.IsImageFormat("IMG_0042.HEIC"))
+	assert.True(t, cfg.IsImageFormat("banner.avif"))
+	assert.True(t, cfg.IsImageFormat("photo.png"))
+
+	// Leaving one out stops it being recognized
+	cfg.ImageExtensions = ParseImageExtensions("jpg,jpeg")
+	assert.False(t, cfg.IsImageFormat("photo.png"))
+	assert.True(t, cfg.IsImageFormat("photo.JPG"))
+
+	// An unset list falls back to the defaults
+	cfg.ImageExtensions = nil
+	assert.True(t, cfg.IsImageFormat("photo.png"))
+	assert.Equal(t, DefaultImageExtensions, ParseImageExtensions(" , "))
+}
+
 func TestCleanupInterval(t *testing.T) {
 	cfg := NewConfig()
 	interval := cfg.CleanupInterval()
@@ -218,6 +512,7 @@ func TestShouldSkipContent(t *testing.T) {
 		{"Password123!@#", true, "password-like content (passwords disabled by default)"},
 		{"MySecurePass!", true, "another password-like content (passwords disabled by default)"},
 		{"simple password", false, "text with spaces (not password)"},
+		{"#ff880080", false, "hex color with alpha"},
 		{"verylongpasswordwithoutspaces123456", true, "long password-like"},
 		{"Complex123!@#$%^&*()", true, "complex password-like"},
 	}
@@ -228,6 +523,65 @@ func TestShouldSkipContent(t *testing.T) {
 	}
 }
 
+func TestShouldSkipContentMinLength(t *testing.T) {
+	cfg := NewConfig()
+
+	// The default of 0 keeps short but meaningful content
+	assert.False(t, cfg.ShouldSkipContent("a"))
+	assert.False(t, cfg.ShouldSkipContent("ok"))
+
+	cfg.MinContentLength = 3
+
+	tests := []struct {
+		content  string
+		expected bool
+		desc     string
+	}{
+		{"a", true, "single character"},
+		{"ab", true, "one below minimum"},
+		{"abc", false, "exactly minimum"},
+		{"abcd", false, "above minimum"},
+		{"  ab  ", true, "padding doesn't count"},
+		{"  abc  ", false, "trimmed length at minimum"},
+		{"日本", true, "two multibyte characters"},
+		{"日本語", false, "three multibyte characters"},
+	}
+
+	for _, test := range tests {
+		result := cfg.ShouldSkipContent(test.content)
+		assert.Equal(t, test.expected, result, "Content: %q (%s)", test.content, test.desc)
+	}
+}
+
+func TestShouldSkipContentCaptureMode(t *testing.T) {
+	singleLine := "just one line of text"
+	multiline := "func main() {\n\tfmt.Println(\"hi\")\n}"
+	paddedSingle := "\n  one line with padding  \n"
+
+	tests := []struct {
+		mode          string
+		skipSingle    bool
+		skipMultiline bool
+	}{
+		{CaptureModeAll, false, false},
+		{CaptureModeMultilineOnly, true, false},
+		{CaptureModeSingleLineOnly, false, true},
+		{"bogus", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.CaptureMode = test.mode
+
+			assert.Equal(t, test.skipSingle, cfg.ShouldSkipContent(singleLine))
+			assert.Equal(t, test.skipMultiline, cfg.ShouldSkipContent(multiline))
+			// Surrounding newlines don't make content multiline
+			assert.Equal(t, test.skipSingle, cfg.ShouldSkipContent(paddedSingle))
+		})
+	}
+}
+
 func TestShouldSkipContentLongContent(t *testing.T) {
 	cfg := NewConfig()
 
@@ -281,6 +635,84 @@ func TestIsLikelyPasswordHeuristics(t *testing.T) {
 	}
 }
 
+func TestPasswordConfidence(t *testing.T) {
+	cfg := NewConfig()
+
+	for _, secret := range []string{"Tr0ub4dor&3xQ!", "k8#Lm2$vQ9@wZ4^p", "VeryComplexP@ssw0rd!"} {
+		assert.GreaterOrEqual(t, cfg.PasswordConfidence(secret), 0.75, secret)
+	}
+	for _, prose := range []string{
+		"The quick brown fox jumps over the lazy dog.",
+		"Meeting moved to 3pm tomorrow",
+		"https://example.com/login?next=home",
+		"document.getElementById",
+	} {
+		assert.Less(t, cfg.PasswordConfidence(prose), 0.2, prose)
+	}
+
+	// Everything stays within 0 to 1
+	for _, content := range []string{"", "aaaaaaaaaaaa", "aB3$aB3$aB3$aB3$aB3$aB3$aB3$aB3$", "Password123!"} {
+		confidence := cfg.PasswordConfidence(content)
+		assert.GreaterOrEqual(t, confidence, 0.0, content)
+		assert.LessOrEqual(t, confidence, 1.0, content)
+	}
+}
+
+func TestPasswordSkipThreshold(t *testing.T) {
+	cfg := NewConfig()
+	token := "k8fj2mqx9lp3vw7z"
+	confidence := cfg.PasswordConfidence(token)
+	require.GreaterOrEqual(t, confidence, DefaultPasswordSkipThreshold)
+
+	assert.True(t, cfg.ShouldSkipContent(token))
+
+	// Raising the threshold past the score lets it through
+	cfg.PasswordSkipThreshold = confidence + 0.01
+	assert.False(t, cfg.ShouldSkipContent(token))
+	assert.False(t, cfg.IsSensitiveContent(token))
+
+	// Unset falls back to the default rather than skipping everything
+	cfg.PasswordSkipThreshold = 0
+	assert.True(t, cfg.ShouldSkipContent(token))
+	assert.False(t, cfg.ShouldSkipContent("hello world"))
+}
+
+func TestShannonEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, shannonEntropy("aaaaaaaa"))
+	assert.Equal(t, 2.0, shannonEntropy("abcd"))
+	assert.Equal(t, 4.0, shannonEntropy("k8fj2mqx9lp3vw7z"))
+	assert.InDelta(t, 3.02, shannonEntropy("helloworld42"), 0.01)
+}
+
+func TestPasswordConfidenceEntropy(t *testing.T) {
+	tests := []struct {
+		content   string
+		threshold float64
+		expected  bool
+		desc      string
+	}{
+		{"k8fj2mqx9lp3vw7z", DefaultPasswordEntropyThreshold, true, "random two-class token above the threshold"},
+		{"helloworld42", DefaultPasswordEntropyThreshold, false, "word with digits below the threshold"},
+		{"k8fj2mqx9lp3vw7z", 4.1, false, "same token once the threshold is raised past it"},
+		{"helloworld42", 3.0, true, "word with digits once the threshold is lowered under it"},
+		{"k8fj2mqx9lp3vw7z", 0, false, "entropy check disabled"},
+		{"3f2a9c1e5b7d8064", DefaultPasswordEntropyThreshold, false, "hex digests are left alone"},
+		{"abcdefghijklmnop", 1, false, "a single character class never counts"},
+		{"Password123!", 0, true, "three classes are caught without entropy"},
+	}
+
+	for _, test := range tests {
+		confidence := passwordConfidence(test.content, test.threshold)
+		assert.Equal(t, test.expected, confidence >= DefaultPasswordSkipThreshold, "%s (%.2f)", test.desc, confidence)
+	}
+
+	cfg := NewConfig()
+	assert.True(t, cfg.ShouldSkipContent("k8fj2mqx9lp3vw7z"))
+	assert.True(t, cfg.IsSensitiveContent("k8fj2mqx9lp3vw7z"))
+	cfg.PasswordEntropyThreshold = 0
+	assert.False(t, cfg.ShouldSkipContent("k8fj2mqx9lp3vw7z"))
+}
+
 func TestShouldSkipContentWithPasswordsAllowed(t *testing.T) {
 	cfg := NewConfig()
 	cfg.AllowPasswords = true
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// IgnoreSignature is a parsed IgnoreSignatures entry. Patterns are compiled
+// once when the settings load rather than on every poll.
+type IgnoreSignature struct {
+	Entry   string         // As written in the settings
+	pattern *regexp.Regexp // Nil for plain strings
+}
+
+// matches reports whether content contains the signature
+func (s IgnoreSignature) matches(content string) bool {
+	if s.pattern != nil {
+		return s.pattern.MatchString(content)
+	}
+	return strings.Contains(content, s.Entry)
+}
+
+// ParseIgnoreSignatures splits a newline-separated IgnoreSignatures list.
+// Entries take the same form as the capture allowlist: /regex/ or a plain
+// string, except that both match anywhere in the content. Invalid
+// expressions are dropped with a warning.
+func ParseIgnoreSignatures(list string) []IgnoreSignature {
+	var signatures []IgnoreSignature
+	for _, entry := range strings.Split(list, "\n") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		signature := IgnoreSignature{Entry: entry}
+		if pattern, ok := allowlistPattern(entry); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				slog.Warn("Ignoring invalid ignore signature", "entry", entry, "err", err)
+				continue
+			}
+			signature.pattern = re
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures
+}
+
+// hasIgnoreSignature reports whether content contains an entry of
+// IgnoreSignatures, such as a marker another clipboard manager adds to the
+// text it writes
+func (c *Config) hasIgnoreSignature(content string) bool {
+	for _, signature := range c.IgnoreSignatures {
+		if signature.matches(content) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIgnoreSignatures(t *testing.T) {
+	list := "[via OtherClip]\n\n  /^#clip-[0-9]+/  \n/[unclosed/\n"
+	signatures := ParseIgnoreSignatures(list)
+	require.Len(t, signatures, 2)
+	assert.Equal(t, "[via OtherClip]", signatures[0].Entry)
+	assert.Nil(t, signatures[0].pattern)
+	assert.Equal(t, "/^#clip-[0-9]+/", signatures[1].Entry)
+	assert.NotNil(t, signatures[1].pattern)
+	assert.Empty(t, ParseIgnoreSignatures(""))
+}
+
+func TestShouldSkipContentIgnoreSignatures(t *testing.T) {
+	cfg := NewConfig()
+	cfg.IgnoreSignatures = ParseIgnoreSignatures("[via OtherClip]\n/^#clip-[0-9]+/")
+
+	tests := []struct {
+		content  string
+		expected bool
+		desc     string
+	}{
+		{"meeting notes [via OtherClip]", true, "plain signature matches anywhere"},
+		{"#clip-42 shopping list", true, "pattern signature matches"},
+		{"shopping list #clip-42", false, "anchored pattern only matches where it says"},
+		{"meeting notes", false, "unmarked content is captured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cfg.ShouldSkipContent(tt.content))
+		})
+	}
+
+	cfg.IgnoreSignatures = nil
+	assert.False(t, cfg.ShouldSkipContent("meeting notes [via OtherClip]"))
+}
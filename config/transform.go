@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+)
+
+// captureTransforms are the normalizations that can be applied to content on
+// capture, keyed by the name used in CaptureTransforms
+var captureTransforms = map[string]func(string) string{
+	"trim-trailing":    trimTrailingWhitespace,
+	"crlf-to-lf":       crlfToLF,
+	"strip-zero-width": stripZeroWidth,
+}
+
+// ParseCaptureTransforms splits a comma-separated transform list, dropping
+// (and logging) names that aren't known
+func ParseCaptureTransforms(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := captureTransforms[name]; !ok {
+			slog.Warn("Ignoring unknown capture transform", "name", name)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// ApplyCaptureTransforms runs the configured transforms over content in
+// order, then NormalizeText if enabled. Unknown names are skipped;
+// ParseCaptureTransforms already warned.
+func (c *Config) ApplyCaptureTransforms(content string) string {
+	for _, name := range c.CaptureTransforms {
+		if transform, ok := captureTransforms[name]; ok {
+			content = transform(content)
+		}
+	}
+	if c.NormalizeText {
+		content = NormalizeText(content)
+	}
+	return content
+}
+
+// typographicChars maps what word processors substitute while typing back to
+// what was typed:
+//
+//	‘ ’ ‚ ‛ (U+2018, U+2019, U+201A, U+201B)  → '
+//	“ ” „ ‟ (U+201C, U+201D, U+201E, U+201F)  → "
+//	no-break spaces (U+00A0, U+2007, U+202F)  → space
+var typographicChars = map[rune]rune{
+	'\u2018': '\'',
+	'\u2019': '\'',
+	'\u201a': '\'',
+	'\u201b': '\'',
+	'\u201c': '"',
+	'\u201d': '"',
+	'\u201e': '"',
+	'\u201f': '"',
+	'\u00a0': ' ',
+	'\u2007': ' ',
+	'\u202f': ' ',
+}
+
+// NormalizeText undoes typographic substitutions that break code and
+// searches: smart quotes become straight ones and no-break spaces plain
+// spaces, per typographicChars, and zero-width characters are dropped as by
+// the "strip-zero-width" transform
+func NormalizeText(s string) string {
+	return stripZeroWidth(strings.Map(func(r rune) rune {
+		if plain, ok := typographicChars[r]; ok {
+			return plain
+		}
+		return r
+	}, s))
+}
+
+// trimTrailingWhitespace strips whitespace from the end of every line and of
+// the content as a whole
+func trimTrailingWhitespace(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.TrimRightFunc(strings.Join(lines, "\n"), unicode.IsSpace)
+}
+
+// lineEndings maps the styles accepted by ConvertLineEndings to their
+// separators
+var lineEndings = map[string]string{
+	"lf":   "\n",
+	"crlf": "\r\n",
+	"cr":   "\r",
+}
+
+// ConvertLineEndings rewrites every line ending in content, whether CRLF, LF
+// or a lone CR, to style: "lf", "crlf" or "cr"
+func ConvertLineEndings(content string, style string) (string, error) {
+	ending, ok := lineEndings[style]
+	if !ok {
+		return "", fmt.Errorf("unknown line ending style: %s", style)
+	}
+
+	normalized := strings.ReplaceAll(crlfToLF(content), "\r", "\n")
+	return strings.ReplaceAll(normalized, "\n", ending), nil
+}
+
+func crlfToLF(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// stripZeroWidth removes invisible characters that often ride along when
+// copying from web pages and chat apps
+func stripZeroWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+			return -1
+		}
+		return r
+	}, s)
+}
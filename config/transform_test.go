@@ -0,0 +1,130 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertLineEndings(t *testing.T) {
+	mixed := "one\r\ntwo\nthree\rfour\n"
+
+	tests := []struct {
+		style    string
+		expected string
+	}{
+		{"lf", "one\ntwo\nthree\nfour\n"},
+		{"crlf", "one\r\ntwo\r\nthree\r\nfour\r\n"},
+		{"cr", "one\rtwo\rthree\rfour\r"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			converted, err := ConvertLineEndings(mixed, tt.style)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, converted)
+		})
+	}
+
+	unchanged, err := ConvertLineEndings("single line", "crlf")
+	assert.NoError(t, err)
+	assert.Equal(t, "single line", unchanged)
+
+	_, err = ConvertLineEndings(mixed, "CRLF")
+	assert.Error(t, err)
+}
+
+func TestCaptureTransforms(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"trim-trailing", "line one  \nline two\t\n\n", "line one\nline two"},
+		{"trim-trailing", "  leading kept", "  leading kept"},
+		{"crlf-to-lf", "a\r\nb\r\n", "a\nb\n"},
+		{"crlf-to-lf", "lone\rcarriage", "lone\rcarriage"},
+		{"strip-zero-width", "zero\u200bwidth\u200d\ufeff", "zerowidth"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.CaptureTransforms = []string{test.name}
+			assert.Equal(t, test.expected, cfg.ApplyCaptureTransforms(test.input))
+		})
+	}
+}
+
+func TestCaptureTransformsOrder(t *testing.T) {
+	// A zero-width space isn't whitespace, so trimming only reaches the
+	// space behind it once it has been stripped
+	input := "text \u200b"
+
+	cfg := NewConfig()
+	cfg.CaptureTransforms = []string{"strip-zero-width", "trim-trailing"}
+	assert.Equal(t, "text", cfg.ApplyCaptureTransforms(input))
+
+	cfg.CaptureTransforms = []string{"trim-trailing", "strip-zero-width"}
+	assert.Equal(t, "text ", cfg.ApplyCaptureTransforms(input))
+}
+
+func TestCaptureTransformsDefaultsAndUnknown(t *testing.T) {
+	cfg := NewConfig()
+	assert.Empty(t, cfg.CaptureTransforms)
+	assert.Equal(t, "untouched \r\n", cfg.ApplyCaptureTransforms("untouched \r\n"))
+
+	assert.Equal(t, []string{"crlf-to-lf", "trim-trailing"},
+		ParseCaptureTransforms(" crlf-to-lf, shout ,,trim-trailing"))
+	assert.Empty(t, ParseCaptureTransforms(""))
+
+	cfg.UpdateFromSettings(map[string]interface{}{"captureTransforms": "strip-zero-width,bogus"})
+	assert.Equal(t, []string{"strip-zero-width"}, cfg.CaptureTransforms)
+
+	// Unknown names set directly are skipped rather than failing
+	cfg.CaptureTransforms = []string{"bogus", "crlf-to-lf"}
+	assert.Equal(t, "a\nb", cfg.ApplyCaptureTransforms("a\r\nb"))
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Single quotes", "\u2018it\u2019s\u2019", "'it's'"},
+		{"Low and reversed single quotes", "\u201aa\u201b", "'a'"},
+		{"Double quotes", "\u201chello\u201d", `"hello"`},
+		{"Low and reversed double quotes", "\u201ea\u201f", `"a"`},
+		{"No-break space", "a\u00a0b", "a b"},
+		{"Figure space", "1\u20070", "1 0"},
+		{"Narrow no-break space", "10\u202f%", "10 %"},
+		{"Zero-width space", "ab\u200bc", "abc"},
+		{"Zero-width joiners", "a\u200cb\u200dc", "abc"},
+		{"Word joiner", "a\u2060b", "ab"},
+		{"Byte order mark", "\ufeffx", "x"},
+		{"Mixed", "print(\u201chi\u201d)\u00a0\u200b# \u2018ok\u2019", `print("hi") # 'ok'`},
+		{"Plain text untouched", `say "hi" it's fine`, `say "hi" it's fine`},
+		{"Other typography kept", "\u00abguillemets\u00bb \u2014 dash", "\u00abguillemets\u00bb \u2014 dash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeText(tt.input))
+		})
+	}
+}
+
+func TestCaptureTransformsNormalizeText(t *testing.T) {
+	cfg := NewConfig()
+	assert.False(t, cfg.NormalizeText)
+	assert.Equal(t, "\u201cx\u201d ", cfg.ApplyCaptureTransforms("\u201cx\u201d "))
+
+	// Combines with the named transforms
+	cfg.NormalizeText = true
+	cfg.CaptureTransforms = []string{"trim-trailing"}
+	assert.Equal(t, `"x" y`, cfg.ApplyCaptureTransforms("\u201cx\u201d\u00a0y  "))
+
+	cfg.UpdateFromSettings(map[string]interface{}{"normalizeText": false})
+	assert.False(t, cfg.NormalizeText)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultTrackingParams are the query parameters StripTrackingParams removes
+// unless configured otherwise. A trailing "*" matches any suffix.
+var DefaultTrackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"dclid",
+	"msclkid",
+	"yclid",
+	"igshid",
+	"mc_cid",
+	"mc_eid",
+	"_hsenc",
+	"_hsmi",
+}
+
+// StripTrackingParams removes query parameters matching params from a single
+// http(s) URL. Anything that isn't one is returned unchanged, as is the order
+// and encoding of the parameters that are kept.
+func StripTrackingParams(rawURL string, params []string) string {
+	trimmed := strings.TrimSpace(rawURL)
+	if trimmed == "" || strings.ContainsAny(trimmed, " \t\r\n") {
+		return rawURL
+	}
+
+	u, err := url.Parse(trimmed)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery == "" {
+		return rawURL
+	}
+
+	pairs := strings.Split(u.RawQuery, "&")
+	var kept []string
+	for _, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && isTrackingParam(name, params) {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+
+	if len(kept) == len(pairs) {
+		return rawURL
+	}
+
+	u.RawQuery = strings.Join(kept, "&")
+	u.ForceQuery = false
+	return u.String()
+}
+
+func isTrackingParam(name string, params []string) bool {
+	name = strings.ToLower(name)
+	for _, param := range params {
+		param = strings.ToLower(param)
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == param {
+			return true
+		}
+	}
+	return false
+}
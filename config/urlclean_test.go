@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripTrackingParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"utm params", "https://example.com/page?utm_source=x&utm_medium=email", "https://example.com/page"},
+		{"keeps other params in order", "https://example.com/?b=2&fbclid=abc&a=1", "https://example.com/?b=2&a=1"},
+		{"keeps fragment", "https://example.com/doc?gclid=1#section", "https://example.com/doc#section"},
+		{"case insensitive", "https://example.com/?UTM_Source=x&q=go", "https://example.com/?q=go"},
+		{"nothing to strip", "https://example.com/?q=a+b&page=2", "https://example.com/?q=a+b&page=2"},
+		{"not a url", "utm_source=x&fbclid=y", "utm_source=x&fbclid=y"},
+		{"other scheme", "ftp://example.com/?utm_source=x", "ftp://example.com/?utm_source=x"},
+		{"text around url", "see https://example.com/?utm_source=x", "see https://example.com/?utm_source=x"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, StripTrackingParams(test.input, DefaultTrackingParams))
+		})
+	}
+}
+
+func TestStripTrackingParamsCustomList(t *testing.T) {
+	input := "https://example.com/?ref=home&utm_source=x&src_id=4"
+
+	assert.Equal(t, "https://example.com/?utm_source=x", StripTrackingParams(input, []string{"ref", "src_*"}))
+	assert.Equal(t, input, StripTrackingParams(input, nil))
+}
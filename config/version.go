@@ -0,0 +1,16 @@
+package config
+
+// Version is the release Klipd was built as, set at build time with
+//
+//	-ldflags "-X klipd/config.Version=1.2.3"
+//
+// Builds without it, such as `wails dev`, report "dev".
+var Version = "dev"
+
+// AppVersion returns Version, or "dev" if the build set it to nothing
+func AppVersion() string {
+	if Version == "" {
+		return "dev"
+	}
+	return Version
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppVersion(t *testing.T) {
+	original := Version
+	t.Cleanup(func() { Version = original })
+
+	assert.Equal(t, "dev", AppVersion())
+
+	Version = "1.2.3"
+	assert.Equal(t, "1.2.3", AppVersion())
+
+	Version = ""
+	assert.Equal(t, "dev", AppVersion())
+}
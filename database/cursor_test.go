@@ -0,0 +1,197 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"klipd/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedCursorItems(t *testing.T, db *Database, count int) {
+	now := time.Now()
+	for i := 0; i < count; i++ {
+		at := now.Add(-time.Duration(count-i) * time.Minute)
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID:           fmt.Sprintf("item-%02d", i),
+			ContentType:  "text",
+			ContentText:  fmt.Sprintf("content %d", i),
+			Hash:         fmt.Sprintf("cursor-hash-%d", i),
+			CreatedAt:    at,
+			LastAccessed: at,
+			UseCount:     i % 3,
+			IsPinned:     i == 2,
+		}))
+	}
+}
+
+// collectPages walks every page, calling between after each fetch
+func collectPages(t *testing.T, db *Database, mode SortMode, ascending bool, between func(page int)) []string {
+	var ids []string
+	cursor := models.Cursor{}
+	for page := 0; ; page++ {
+		result, err := db.GetClipboardItemsAfter(cursor, 3, "", mode, ascending)
+		require.NoError(t, err)
+		ids = append(ids, itemIDs(result.Items)...)
+		if result.Next == nil {
+			return ids
+		}
+		cursor = *result.Next
+		between(page)
+	}
+}
+
+func TestGetClipboardItemsAfterMatchesOrdering(t *testing.T) {
+	for _, mode := range SortModes {
+		for _, ascending := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/ascending=%v", mode, ascending), func(t *testing.T) {
+				db := setupTestDB(t)
+				seedCursorItems(t, db, 8)
+
+				all, err := db.GetClipboardItemsOrdered(100, 0, "", mode, ascending)
+				require.NoError(t, err)
+
+				paged := collectPages(t, db, mode, ascending, func(int) {})
+				assert.Equal(t, itemIDs(all), paged)
+			})
+		}
+	}
+}
+
+func TestGetClipboardItemsAfterStableWithInserts(t *testing.T) {
+	db := setupTestDB(t)
+	seedCursorItems(t, db, 8)
+
+	before, err := db.GetClipboardItems(100, 0, "", SortCopied)
+	require.NoError(t, err)
+
+	// New items arrive at the top while scrolling
+	paged := collectPages(t, db, SortCopied, false, func(page int) {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID:          fmt.Sprintf("new-%d", page),
+			ContentType: "text",
+			ContentText: fmt.Sprintf("arrived during page %d", page),
+			Hash:        fmt.Sprintf("cursor-new-%d", page),
+		}))
+	})
+
+	// Every original row exactly once, in order, and none of the new ones
+	assert.Equal(t, itemIDs(before), paged)
+}
+
+func TestGetClipboardItemsAfterContentType(t *testing.T) {
+	db := setupTestDB(t)
+	seedCursorItems(t, db, 4)
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "img", ContentType: "image", ContentText: "/tmp/a.png", Hash: "cursor-img"}))
+
+	page, err := db.GetClipboardItemsAfter(models.Cursor{}, 10, "image", SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"img"}, itemIDs(page.Items))
+	assert.Nil(t, page.Next)
+}
+
+func TestGetAdjacentItems(t *testing.T) {
+	db := setupTestDB(t)
+	seedCursorItems(t, db, 5)
+
+	// item-02 is pinned, so the order is 02, 04, 03, 01, 00
+	prev, next, err := db.GetAdjacentItems("item-04", SortCopied, false)
+	require.NoError(t, err)
+	require.NotNil(t, prev)
+	require.NotNil(t, next)
+	assert.Equal(t, "item-02", prev.ID)
+	assert.Equal(t, "item-03", next.ID)
+
+	prev, next, err = db.GetAdjacentItems("item-02", SortCopied, false)
+	require.NoError(t, err)
+	assert.Nil(t, prev)
+	require.NotNil(t, next)
+	assert.Equal(t, "item-04", next.ID)
+
+	prev, next, err = db.GetAdjacentItems("item-00", SortCopied, false)
+	require.NoError(t, err)
+	require.NotNil(t, prev)
+	assert.Equal(t, "item-01", prev.ID)
+	assert.Nil(t, next)
+
+	_, _, err = db.GetAdjacentItems("missing", SortCopied, false)
+	assert.Error(t, err)
+}
+
+func TestGetAdjacentItemsMatchesOrdering(t *testing.T) {
+	db := setupTestDB(t)
+	seedCursorItems(t, db, 7)
+
+	for _, mode := range SortModes {
+		for _, ascending := range []bool{false, true} {
+			ids := collectPages(t, db, mode, ascending, func(int) {})
+			for i, id := range ids {
+				prev, next, err := db.GetAdjacentItems(id, mode, ascending)
+				require.NoError(t, err)
+				if i > 0 {
+					require.NotNil(t, prev, mode)
+					assert.Equal(t, ids[i-1], prev.ID, mode)
+				} else {
+					assert.Nil(t, prev, mode)
+				}
+				if i < len(ids)-1 {
+					require.NotNil(t, next, mode)
+					assert.Equal(t, ids[i+1], next.ID, mode)
+				} else {
+					assert.Nil(t, next, mode)
+				}
+			}
+		}
+	}
+}
+
+func TestGetAdjacentItemsAscending(t *testing.T) {
+	db := setupTestDB(t)
+	seedCursorItems(t, db, 5)
+
+	// Oldest first with item-02 still pinned on top: 02, 00, 01, 03, 04
+	prev, next, err := db.GetAdjacentItems("item-01", SortCopied, true)
+	require.NoError(t, err)
+	require.NotNil(t, prev)
+	require.NotNil(t, next)
+	assert.Equal(t, "item-00", prev.ID)
+	assert.Equal(t, "item-03", next.ID)
+
+	prev, next, err = db.GetAdjacentItems("item-02", SortCopied, true)
+	require.NoError(t, err)
+	assert.Nil(t, prev)
+	require.NotNil(t, next)
+	assert.Equal(t, "item-00", next.ID)
+
+	prev, next, err = db.GetAdjacentItems("item-04", SortCopied, true)
+	require.NoError(t, err)
+	require.NotNil(t, prev)
+	assert.Equal(t, "item-03", prev.ID)
+	assert.Nil(t, next)
+
+	// Stepping through matches the ascending list in every mode
+	for _, mode := range SortModes {
+		all, err := db.GetClipboardItemsOrdered(100, 0, "", mode, true)
+		require.NoError(t, err)
+		ids := itemIDs(all)
+		for i, id := range ids {
+			prev, next, err := db.GetAdjacentItems(id, mode, true)
+			require.NoError(t, err)
+			if i > 0 {
+				require.NotNil(t, prev, mode)
+				assert.Equal(t, ids[i-1], prev.ID, mode)
+			} else {
+				assert.Nil(t, prev, mode)
+			}
+			if i < len(ids)-1 {
+				require.NotNil(t, next, mode)
+				assert.Equal(t, ids[i+1], next.ID, mode)
+			} else {
+				assert.Nil(t, next, mode)
+			}
+		}
+	}
+}
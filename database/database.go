@@ -1,36 +1,327 @@
 package database
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"klipd/models"
 
+	"github.com/mattn/go-sqlite3"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 type Database struct {
+	// DB is the pool reads go through. Under WAL readers don't block each
+	// other or the writer, so the UI isn't held up by captures.
 	DB *gorm.DB
+
+	writer *gorm.DB    // single connection all writes run on
+	writes *writeQueue // serializes access to writer
+
+	recoveredBackup string // where a corrupt database replaced on open was moved
+}
+
+// WithContext returns a Database whose queries run under ctx, so a caller
+// can bound or cancel them. The methods on the original keep using the
+// background context.
+func (d *Database) WithContext(ctx context.Context) *Database {
+	return &Database{DB: d.DB.WithContext(ctx), writer: d.writer.WithContext(ctx), writes: d.writes, recoveredBackup: d.recoveredBackup}
+}
+
+// write runs fn against the writer connection once every write queued
+// before it has finished
+func (d *Database) write(fn func(db *gorm.DB) error) error {
+	return d.writes.do(d.writer.Statement.Context, func() error {
+		return fn(d.writer)
+	})
+}
+
+// DataDirEnv overrides the directory the database is stored in, e.g. to keep
+// history on an external or synced drive (portable mode).
+const DataDirEnv = "KLIPD_DATA_DIR"
+
+const (
+	appDirName = "Klipd"
+	dbFileName = "clipboard.db"
+)
+
+// dataDirResolver locates the per-OS data directory. The lookups are
+// swappable so tests can fake each platform's layout.
+type dataDirResolver struct {
+	configDir func() (string, error)
+	homeDir   func() (string, error)
+}
+
+var defaultResolver = dataDirResolver{
+	configDir: os.UserConfigDir,
+	homeDir:   os.UserHomeDir,
 }
 
-func New() (*Database, error) {
-	homeDir, err := os.UserHomeDir()
+// dataDir returns <config>/Klipd for the current platform
+func (r dataDirResolver) dataDir() (string, error) {
+	configDir, err := r.configDir()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	return filepath.Join(configDir, appDirName), nil
+}
+
+// legacyDataDir is where older releases put the database on every OS
+func (r dataDirResolver) legacyDataDir() (string, error) {
+	homeDir, err := r.homeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, "Library", "Application Support", appDirName), nil
+}
+
+// migrateLegacy moves a database left in the legacy location into dir and
+// returns the directory that should be opened. On macOS both locations are
+// the same, so this is a no-op there. If the files can't be moved the legacy
+// directory is returned so existing history is never orphaned.
+func (r dataDirResolver) migrateLegacy(dir string) string {
+	legacyDir, err := r.legacyDataDir()
+	if err != nil || filepath.Clean(legacyDir) == filepath.Clean(dir) {
+		return dir
+	}
+
+	legacyPath := filepath.Join(legacyDir, dbFileName)
+	if _, err := os.Stat(legacyPath); err != nil {
+		return dir
+	}
+
+	// Never clobber a database that already exists in the new location
+	if _, err := os.Stat(filepath.Join(dir, dbFileName)); err == nil {
+		return dir
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		slog.Warn("Failed to create data directory, using legacy location", "dir", dir, "err", err)
+		return legacyDir
+	}
+
+	if err := os.Rename(legacyPath, filepath.Join(dir, dbFileName)); err != nil {
+		slog.Warn("Failed to migrate database, using legacy location", "from", legacyDir, "err", err)
+		return legacyDir
+	}
+
+	// WAL sidecar files carry uncheckpointed writes, so they must move too
+	for _, suffix := range []string{"-wal", "-shm"} {
+		sidecar := legacyPath + suffix
+		if _, err := os.Stat(sidecar); err != nil {
+			continue
+		}
+		if err := os.Rename(sidecar, filepath.Join(dir, dbFileName+suffix)); err != nil {
+			slog.Warn("Failed to migrate database sidecar file", "path", sidecar, "err", err)
+		}
+	}
+
+	slog.Info("Migrated database", "from", legacyDir, "to", dir)
+	return dir
+}
+
+// New opens the database in the default data directory, migrating it from
+// the legacy location if needed. If that directory can't be used (e.g. a
+// read-only volume) it falls back to an in-memory database and reports
+// ephemeral, so the session still works but nothing outlives it.
+func New() (db *Database, ephemeral bool, err error) {
+	appDir, err := DefaultDataDir()
+	if err == nil {
+		if os.Getenv(DataDirEnv) == "" {
+			appDir = defaultResolver.migrateLegacy(appDir)
+		}
+		if db, err = NewWithPath(appDir, nil); err == nil {
+			return db, false, nil
+		}
 	}
 
+	slog.Error("Failed to open database in the data directory, persistence disabled", "err", err)
+	if db, err = NewInMemory(); err != nil {
+		return nil, false, err
+	}
+	return db, true, nil
+}
+
+// DefaultDataDir resolves the app data directory, preferring KLIPD_DATA_DIR
+// over the per-OS user config directory
+func DefaultDataDir() (string, error) {
+	if dir := os.Getenv(DataDirEnv); dir != "" {
+		return dir, nil
+	}
+	return defaultResolver.dataDir()
+}
+
+// PragmasEnv adds SQLite pragmas on top of the defaults and any passed to
+// NewWithPath, as semicolon-separated name=value pairs, e.g.
+// "busy_timeout=5000;cache_size=-20000"
+const PragmasEnv = "KLIPD_SQLITE_PRAGMAS"
+
+// NewWithPath opens (creating if needed) the database inside dir. pragmas
+// are applied after the built-in ones, so they can override them; nil keeps
+// the defaults. A corrupt database is moved aside and replaced with an empty
+// one, see RecoveredBackup.
+func NewWithPath(dir string, pragmas map[string]string) (*Database, error) {
 	// Create app data directory
-	appDir := filepath.Join(homeDir, "Library", "Application Support", "Klipd")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, dbFileName)
+	db, err := open(path, false, pragmas)
+	if err == nil || !isCorrupt(err) {
+		return db, err
+	}
+
+	backup, moveErr := moveAside(path)
+	if moveErr != nil {
+		return nil, fmt.Errorf("%w (moving it aside failed: %v)", err, moveErr)
+	}
+	slog.Error("Database is corrupt, starting with empty history", "backup", backup, "err", err)
 
-	if err := os.MkdirAll(appDir, 0755); err != nil {
+	if db, err = open(path, false, pragmas); err != nil {
 		return nil, err
 	}
+	db.recoveredBackup = backup
+	return db, nil
+}
+
+// RecoveredBackup returns where the database file was moved when it was
+// found corrupt on open and history was reset, or "" if it opened normally
+func (d *Database) RecoveredBackup() string {
+	return d.recoveredBackup
+}
 
-	dbPath := filepath.Join(appDir, "clipboard.db")
+// ErrCorrupt is returned when a database fails SQLite's quick check
+var ErrCorrupt = errors.New("database is corrupt")
 
+// checkIntegrity runs SQLite's quick check, which also catches a file that
+// isn't a database at all. It leaves out the full integrity check's
+// verification that indexes match their tables, the slow part on a large
+// history, since it runs on every launch.
+func checkIntegrity(db *gorm.DB) error {
+	var problems []string
+	if err := db.Raw("PRAGMA quick_check").Scan(&problems).Error; err != nil {
+		return err
+	}
+	if len(problems) != 1 || problems[0] != "ok" {
+		return fmt.Errorf("%w: %s", ErrCorrupt, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// isCorrupt tells a damaged database file apart from failures that say
+// nothing about its contents, like a permissions problem
+func isCorrupt(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrCorrupt || sqliteErr.Code == sqlite3.ErrNotADB
+	}
+	return errors.Is(err, ErrCorrupt)
+}
+
+// moveAside renames a corrupt database, with its WAL sidecar files, to a
+// timestamped backup next to it and returns the backup's path. The sidecars
+// have to go too, or SQLite would replay them into the fresh database.
+func moveAside(path string) (string, error) {
+	backup := path + ".corrupt-" + time.Now().Format("20060102-150405")
+	if err := os.Rename(path, backup); err != nil {
+		return "", err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(path + suffix); err != nil {
+			continue
+		}
+		if err := os.Rename(path+suffix, backup+suffix); err != nil {
+			return "", err
+		}
+	}
+	return backup, nil
+}
+
+// NewInMemory opens a database that lives only as long as the process
+func NewInMemory() (*Database, error) {
+	return open(":memory:", true, nil)
+}
+
+// pragmaNamePattern and pragmaValuePattern limit custom pragmas to what can
+// safely be spliced into a PRAGMA statement
+var (
+	pragmaNamePattern  = regexp.MustCompile(`^[a-z_]+$`)
+	pragmaValuePattern = regexp.MustCompile(`^-?[A-Za-z0-9_]+$`)
+)
+
+// ParsePragmas reads "name=value" pairs separated by semicolons, as used by
+// PragmasEnv. Malformed pairs are logged and skipped.
+func ParsePragmas(list string) map[string]string {
+	pragmas := make(map[string]string)
+	for _, pair := range strings.Split(list, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			slog.Warn("Ignoring SQLite pragma without a value", "pragma", pair)
+			continue
+		}
+		pragmas[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return pragmas
+}
+
+// applyPragmas runs each custom pragma in name order. Invalid names or
+// values and pragmas SQLite rejects are logged and skipped, so one bad
+// entry can't keep the database from opening.
+func applyPragmas(exec func(query string) error, pragmas map[string]string) {
+	names := make([]string, 0, len(pragmas))
+	for name := range pragmas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := pragmas[name]
+		if !pragmaNamePattern.MatchString(name) || !pragmaValuePattern.MatchString(value) {
+			slog.Warn("Ignoring invalid SQLite pragma", "name", name, "value", value)
+			continue
+		}
+		if err := exec("PRAGMA " + name + " = " + value); err != nil {
+			slog.Warn("Failed to apply SQLite pragma", "name", name, "value", value, "err", err)
+			continue
+		}
+		slog.Debug("Applied SQLite pragma", "name", name, "value", value)
+	}
+}
+
+// readConns is how many connections the read pool keeps open
+const readConns = 4
+
+// connectionPragmas are the defaults every connection gets. Unlike
+// journal_mode they only last as long as the connection that ran them.
+var connectionPragmas = []string{
+	"PRAGMA busy_timeout=5000",
+	"PRAGMA synchronous=NORMAL",
+	"PRAGMA temp_store=MEMORY",
+	"PRAGMA mmap_size=268435456",
+}
+
+// connect opens a pool of exactly conns connections to dsn and runs the
+// default and custom pragmas on each of them. The connections are never
+// recycled, since a replacement would come up without those pragmas.
+func connect(dsn string, conns int, custom []map[string]string) (*gorm.DB, error) {
 	config := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent), // Silent in production
 		NowFunc: func() time.Time {
@@ -38,29 +329,83 @@ func New() (*Database, error) {
 		},
 	}
 
-	db, err := gorm.Open(sqlite.Open(dbPath), config)
+	db, err := gorm.Open(sqlite.Open(dsn), config)
 	if err != nil {
 		return nil, err
 	}
 
-	// Configure SQLite for better performance
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
 
-	sqlDB.SetMaxOpenConns(1)
-	sqlDB.SetMaxIdleConns(1)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxOpenConns(conns)
+	sqlDB.SetMaxIdleConns(conns)
+	sqlDB.SetConnMaxLifetime(0)
 
-	// Execute SQLite pragmas for performance
-	db.Exec("PRAGMA journal_mode=WAL")
-	db.Exec("PRAGMA synchronous=NORMAL")
-	db.Exec("PRAGMA temp_store=MEMORY")
-	db.Exec("PRAGMA mmap_size=268435456")
-	db.Exec("PRAGMA optimize")
+	// Check out every connection at once so each one is opened and set up
+	// now, then hand them all back to sit idle in the pool
+	ctx := context.Background()
+	held := make([]*sql.Conn, 0, conns)
+	defer func() {
+		for _, conn := range held {
+			conn.Close()
+		}
+	}()
+	for range conns {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		held = append(held, conn)
 
-	database := &Database{DB: db}
+		exec := func(query string) error {
+			_, err := conn.ExecContext(ctx, query)
+			return err
+		}
+		for _, pragma := range connectionPragmas {
+			exec(pragma)
+		}
+		// User tuning goes last so it wins over the defaults above
+		for _, pragmas := range custom {
+			applyPragmas(exec, pragmas)
+		}
+	}
+
+	return db, nil
+}
+
+// open connects to dsn and brings the schema and settings up to date.
+// Writes go through a single connection fed by a queue, reads through a
+// separate pool; an in-memory database uses its one connection for both,
+// since every connection to :memory: is a separate, empty database.
+func open(dsn string, inMemory bool, pragmas map[string]string) (_ *Database, err error) {
+	custom := []map[string]string{pragmas}
+	if env := os.Getenv(PragmasEnv); env != "" {
+		custom = append(custom, ParsePragmas(env))
+	}
+
+	writer, err := connect(dsn, 1, custom)
+	if err != nil {
+		return nil, err
+	}
+	database := &Database{DB: writer, writer: writer}
+
+	// A failed open must not leave connections behind, least of all on a
+	// corrupt file that NewWithPath is about to move aside
+	defer func() {
+		if err != nil {
+			database.closeConns()
+		}
+	}()
+
+	if !inMemory {
+		if err := checkIntegrity(writer); err != nil {
+			return nil, err
+		}
+	}
+	writer.Exec("PRAGMA journal_mode=WAL")
+	writer.Exec("PRAGMA optimize")
 
 	if err := database.migrate(); err != nil {
 		return nil, err
@@ -70,46 +415,245 @@ func New() (*Database, error) {
 		return nil, err
 	}
 
+	if err := database.migrateSettings(); err != nil {
+		return nil, err
+	}
+
+	if !inMemory {
+		reader, err := connect(dsn, readConns, custom)
+		if err != nil {
+			return nil, err
+		}
+		database.DB = reader
+	}
+	database.writes = newWriteQueue()
+
 	return database, nil
 }
 
 func (d *Database) migrate() error {
-	return d.DB.AutoMigrate(
+	// Rows created before size tracking need a one-time backfill
+	backfillSizes := d.writer.Migrator().HasTable(&models.ClipboardItem{}) &&
+		!d.writer.Migrator().HasColumn(&models.ClipboardItem{}, "SizeBytes")
+	// Before LastSeen was split out, LastAccessed covered both events
+	backfillLastSeen := d.writer.Migrator().HasTable(&models.ClipboardItem{}) &&
+		!d.writer.Migrator().HasColumn(&models.ClipboardItem{}, "LastSeen")
+
+	if err := d.writer.AutoMigrate(
 		&models.ClipboardItem{},
 		&models.Settings{},
-	)
+		&models.RecentSearch{},
+	); err != nil {
+		return err
+	}
+
+	// Items from before normalized dedup were matched exactly. Runs every
+	// time since it's cheap once there's nothing left to fill.
+	if err := d.writer.Exec(`UPDATE clipboard_items SET dedup_hash = hash
+		WHERE dedup_hash IS NULL OR dedup_hash = ''`).Error; err != nil {
+		return err
+	}
+
+	if backfillSizes {
+		// CAST to BLOB so length() counts bytes rather than characters
+		if err := d.writer.Exec(`UPDATE clipboard_items SET size_bytes =
+			COALESCE(length(CAST(content_text AS BLOB)), 0) + COALESCE(length(content_binary), 0)`).Error; err != nil {
+			return err
+		}
+	}
+
+	if backfillLastSeen {
+		if err := d.writer.Exec(`UPDATE clipboard_items SET last_seen = last_accessed`).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settingsMigrations bring a settings row from one schema version to the
+// next; entry i upgrades version i to i+1. Add an entry (never edit an old
+// one) whenever a settings field needs a non-zero value for existing rows.
+var settingsMigrations = []func(tx *gorm.DB) error{
+	// 0 -> 1: rows from before versioning may have blank strings where a
+	// default is expected
+	func(tx *gorm.DB) error {
+		defaults := map[string]string{
+			"global_hotkey":        "Cmd+Shift+Space",
+			"previous_item_hotkey": "Cmd+Shift+C",
+			"show_window_hotkey":   "Cmd+Shift+K",
+			"sort_by_recent":       "copied",
+			"capture_mode":         "all",
+		}
+		for column, value := range defaults {
+			if err := tx.Model(&models.Settings{}).
+				Where(column+" IS NULL OR "+column+" = ''").
+				Update(column, value).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// settingsSchemaVersion is the version a fully migrated settings row has
+var settingsSchemaVersion = len(settingsMigrations)
+
+// migrateSettings runs the settings migrations newer than the stored
+// schema version and records the new version
+func (d *Database) migrateSettings() error {
+	var settings models.Settings
+	if err := d.writer.First(&settings).Error; err != nil {
+		return err
+	}
+	if settings.SchemaVersion >= settingsSchemaVersion {
+		return nil
+	}
+
+	return d.writer.Transaction(func(tx *gorm.DB) error {
+		for version := settings.SchemaVersion; version < settingsSchemaVersion; version++ {
+			if err := settingsMigrations[version](tx); err != nil {
+				return fmt.Errorf("settings migration to version %d: %w", version+1, err)
+			}
+		}
+		return tx.Model(&models.Settings{}).
+			Where("1 = 1").
+			Update("schema_version", settingsSchemaVersion).Error
+	})
+}
+
+// DefaultSettings returns the settings a fresh install starts with
+func DefaultSettings() *models.Settings {
+	return &models.Settings{
+		GlobalHotkey:             "Cmd+Shift+Space",
+		PreviousItemHotkey:       "Cmd+Shift+C",
+		ShowWindowHotkey:         "Cmd+Shift+K",
+		PreviousHotkeyAction:     "copy",
+		PollingInterval:          500,
+		MaxItems:                 100,
+		MaxDays:                  7,
+		AutoLaunch:               true,
+		EnableSounds:             false,
+		MonitoringEnabled:        true,
+		AllowPasswords:           false,
+		SortByRecent:             "copied",
+		RespectSecureInput:       true,
+		CaptureExistingOnStart:   true,
+		IgnoreSelfWrites:         true,
+		DedupNormalization:       "exact",
+		DedupWindowMs:            1000,
+		PasswordEntropyThreshold: 3.7,
+		PasswordSkipThreshold:    0.5,
+		CaptureMode:              "all",
+		LogLevel:                 "info",
+		Theme:                    "auto",
+		SchemaVersion:            settingsSchemaVersion,
+	}
+}
+
+// settingsSections groups settings that can be reset to their defaults
+// together, copying each field of a section from defaults to s
+var settingsSections = map[string]func(s, defaults *models.Settings){
+	"hotkeys": func(s, defaults *models.Settings) {
+		s.GlobalHotkey = defaults.GlobalHotkey
+		s.PreviousItemHotkey = defaults.PreviousItemHotkey
+		s.ShowWindowHotkey = defaults.ShowWindowHotkey
+		s.ForgetHotkey = defaults.ForgetHotkey
+		s.CycleSortHotkey = defaults.CycleSortHotkey
+		s.CaptureHotkey = defaults.CaptureHotkey
+		s.StackPasteMode = defaults.StackPasteMode
+		s.PreviousHotkeyAction = defaults.PreviousHotkeyAction
+	},
+	"cleanup": func(s, defaults *models.Settings) {
+		s.MaxItems = defaults.MaxItems
+		s.MaxItemsPerApp = defaults.MaxItemsPerApp
+		s.MaxDays = defaults.MaxDays
+		s.CompressLargeContent = defaults.CompressLargeContent
+	},
+	"privacy": func(s, defaults *models.Settings) {
+		s.AllowPasswords = defaults.AllowPasswords
+		s.PasswordEntropyThreshold = defaults.PasswordEntropyThreshold
+		s.PasswordSkipThreshold = defaults.PasswordSkipThreshold
+		s.CaptureAllowlist = defaults.CaptureAllowlist
+		s.RespectSecureInput = defaults.RespectSecureInput
+		s.StripURLTracking = defaults.StripURLTracking
+		s.LogContentPreviews = defaults.LogContentPreviews
+	},
+	"capture": func(s, defaults *models.Settings) {
+		s.PollingInterval = defaults.PollingInterval
+		s.MonitoringEnabled = defaults.MonitoringEnabled
+		s.CaptureExistingOnStart = defaults.CaptureExistingOnStart
+		s.CaptureMode = defaults.CaptureMode
+		s.ManualCaptureMode = defaults.ManualCaptureMode
+		s.CaptureTransforms = defaults.CaptureTransforms
+		s.NormalizeText = defaults.NormalizeText
+		s.MinContentLength = defaults.MinContentLength
+		s.DedupNormalization = defaults.DedupNormalization
+		s.DedupWindowMs = defaults.DedupWindowMs
+		s.SkipWhitespaceOnlyChanges = defaults.SkipWhitespaceOnlyChanges
+		s.PromotePinnedOnRecopy = defaults.PromotePinnedOnRecopy
+		s.ImageExtensions = defaults.ImageExtensions
+		s.MaxImageDimension = defaults.MaxImageDimension
+		s.IgnoreSelfWrites = defaults.IgnoreSelfWrites
+		s.IgnoreSignatures = defaults.IgnoreSignatures
+	},
+	"appearance": func(s, defaults *models.Settings) {
+		s.Theme = defaults.Theme
+		s.SortByRecent = defaults.SortByRecent
+		s.SortAscending = defaults.SortAscending
+	},
+	"general": func(s, defaults *models.Settings) {
+		s.AutoLaunch = defaults.AutoLaunch
+		s.EnableSounds = defaults.EnableSounds
+		s.LogLevel = defaults.LogLevel
+	},
+}
+
+// ResetSettingsSection puts the fields of one section of settings back to
+// their defaults. Sections are "hotkeys", "cleanup", "privacy", "capture",
+// "appearance" and "general".
+func ResetSettingsSection(settings *models.Settings, section string) error {
+	reset, ok := settingsSections[section]
+	if !ok {
+		return fmt.Errorf("unknown settings section: %s", section)
+	}
+	reset(settings, DefaultSettings())
+	return nil
 }
 
 func (d *Database) initializeSettings() error {
 	var count int64
-	if err := d.DB.Model(&models.Settings{}).Count(&count).Error; err != nil {
+	if err := d.writer.Model(&models.Settings{}).Count(&count).Error; err != nil {
 		return err
 	}
 
 	if count == 0 {
-		defaultSettings := &models.Settings{
-			GlobalHotkey:       "Cmd+Shift+Space",
-			PreviousItemHotkey: "Cmd+Shift+C",
-			PollingInterval:    500,
-			MaxItems:           100,
-			MaxDays:            7,
-			AutoLaunch:         true,
-			EnableSounds:       false,
-			MonitoringEnabled:  true,
-			AllowPasswords:     false,
-		}
-		return d.DB.Create(defaultSettings).Error
+		return d.writer.Create(DefaultSettings()).Error
 	}
 
 	return nil
 }
 
+// Close waits for queued writes to finish, then closes every connection
 func (d *Database) Close() error {
-	sqlDB, err := d.DB.DB()
+	d.writes.close()
+	return d.closeConns()
+}
+
+// closeConns closes the reader and writer connection pools
+func (d *Database) closeConns() error {
+	writer, err := d.writer.DB()
+	if err != nil {
+		return err
+	}
+	reader, err := d.DB.DB()
 	if err != nil {
 		return err
 	}
-	return sqlDB.Close()
+	if reader == writer {
+		return writer.Close()
+	}
+	return errors.Join(reader.Close(), writer.Close())
 }
 
 func (d *Database) GetSettings() (*models.Settings, error) {
@@ -119,14 +663,261 @@ func (d *Database) GetSettings() (*models.Settings, error) {
 }
 
 func (d *Database) UpdateSettings(settings *models.Settings) error {
-	return d.DB.Save(settings).Error
+	// The frontend round-trips settings without caring about the version
+	settings.SchemaVersion = settingsSchemaVersion
+	return d.write(func(db *gorm.DB) error {
+		return db.Save(settings).Error
+	})
 }
 
 func (d *Database) CreateClipboardItem(item *models.ClipboardItem) error {
-	return d.DB.Create(item).Error
+	return d.write(func(db *gorm.DB) error {
+		return db.Create(item).Error
+	})
+}
+
+// SortMode is a Settings.SortByRecent value, deciding how items are ordered
+// after the pinned ones
+type SortMode string
+
+const (
+	SortCopied   SortMode = "copied"   // By capture time
+	SortPasted   SortMode = "pasted"   // By last access or last sighting, whichever is newer
+	SortAccessed SortMode = "accessed" // By the last copy from history
+	SortSmart    SortMode = "smart"    // By a blend of use count and recency
+)
+
+// SortModes are the accepted Settings.SortByRecent values, in the order
+// CycleSortMode steps through them
+var SortModes = []SortMode{SortCopied, SortPasted, SortAccessed, SortSmart}
+
+// ParseSortMode checks that s is one of SortModes
+func ParseSortMode(s string) (SortMode, error) {
+	switch mode := SortMode(s); mode {
+	case SortCopied, SortPasted, SortAccessed, SortSmart:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown sort mode: %q", s)
+	}
+}
+
+// NextSortMode returns the mode after current in SortModes, wrapping around.
+// An unknown mode starts over at the first one.
+func NextSortMode(current string) SortMode {
+	i := slices.Index(SortModes, SortMode(current))
+	return SortModes[(i+1)%len(SortModes)]
+}
+
+// CycleSortMode advances the stored sort mode to the next one and returns it
+func (d *Database) CycleSortMode() (SortMode, error) {
+	var mode SortMode
+	err := d.write(func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			var settings models.Settings
+			if err := tx.First(&settings).Error; err != nil {
+				return err
+			}
+			mode = NextSortMode(settings.SortByRecent)
+			return tx.Model(&settings).Update("sort_by_recent", string(mode)).Error
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return mode, nil
+}
+
+// Weights for the "smart" ordering: each use counts as much as the item
+// having been accessed smartAgeWeight^-1 days more recently
+const (
+	smartUseWeight = 1.0
+	smartAgeWeight = 0.5 // per day since last access
+)
+
+// orderBy returns the ORDER BY clause for a sort mode. ascending flips the
+// order after is_pinned so the oldest come first; pinned items always come
+// first either way.
+func orderBy(mode SortMode, ascending bool) (string, error) {
+	dir := "DESC"
+	if ascending {
+		dir = "ASC"
+	}
+
+	switch mode {
+	case SortCopied:
+		return "is_pinned DESC, created_at " + dir, nil
+	case SortPasted:
+		return "is_pinned DESC, max(last_accessed, last_seen) " + dir, nil
+	case SortAccessed:
+		return "is_pinned DESC, last_accessed " + dir, nil
+	case SortSmart:
+		return fmt.Sprintf("is_pinned DESC, (use_count * %g - (julianday('now') - julianday(last_accessed)) * %g) %s, last_accessed %s",
+			smartUseWeight, smartAgeWeight, dir, dir), nil
+	default:
+		return "", fmt.Errorf("unknown sort mode: %q", mode)
+	}
+}
+
+// sortKey returns a numeric expression that orders items the same way as
+// orderBy does after is_pinned, so it can be compared against a cursor.
+// Timestamps go through julianday; the smart score drops the "now" term,
+// which shifts every score equally and can't change the order.
+func sortKey(mode SortMode) (string, error) {
+	switch mode {
+	case SortCopied:
+		return "julianday(created_at)", nil
+	case SortPasted:
+		return "julianday(max(last_accessed, last_seen))", nil
+	case SortAccessed:
+		return "julianday(last_accessed)", nil
+	case SortSmart:
+		return fmt.Sprintf("(use_count * %g + julianday(last_accessed) * %g)", smartUseWeight, smartAgeWeight), nil
+	default:
+		return "", fmt.Errorf("unknown sort mode: %q", mode)
+	}
+}
+
+const (
+	// DefaultLimit stands in for a limit of zero or less, which GORM would
+	// take as no limit and load the whole table
+	DefaultLimit = 100
+	// MaxLimit caps how many items a single list or search returns
+	MaxLimit = 1000
+)
+
+// clampLimit brings a caller's limit into [1, MaxLimit]. Every list and
+// search method runs its limit through this, so a bad value from the
+// frontend can't pull the entire history over IPC.
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	return min(limit, MaxLimit)
 }
 
-func (d *Database) GetClipboardItems(limit int, offset int, contentType string, sortByRecent string) ([]models.ClipboardItem, error) {
+// GetClipboardItemsAfter returns the page of items following cursor in the
+// order for mode and ascending. Unlike offsets, a cursor keeps its
+// place when new items are captured mid-scroll.
+func (d *Database) GetClipboardItemsAfter(cursor models.Cursor, limit int, contentType string, mode SortMode, ascending bool) (*models.ClipboardPage, error) {
+	limit = clampLimit(limit)
+	key, err := sortKey(mode)
+	if err != nil {
+		return nil, err
+	}
+	query := d.DB.Model(&models.ClipboardItem{})
+
+	// Pinned items come first either way; within each group the sort key
+	// and then the id run down, or up when ascending
+	keyOp, dir := "<", " DESC"
+	if ascending {
+		keyOp, dir = ">", " ASC"
+	}
+
+	if contentType != "" {
+		query = query.Where("content_type = ?", contentType)
+	}
+	if cursor.ID != "" {
+		query = query.Where("is_pinned < ? OR (is_pinned = ? AND ("+key+" "+keyOp+" ? OR ("+key+" = ? AND id "+keyOp+" ?)))",
+			cursor.IsPinned, cursor.IsPinned, cursor.SortKey, cursor.SortKey, cursor.ID)
+	}
+
+	page := &models.ClipboardPage{}
+	if err := query.Order("is_pinned DESC, " + key + dir + ", id" + dir).
+		Limit(limit).
+		Find(&page.Items).Error; err != nil {
+		return nil, err
+	}
+
+	if len(page.Items) < limit {
+		return page, nil
+	}
+
+	// Read the key back from SQLite so the next comparison is exact
+	last := page.Items[len(page.Items)-1]
+	next := &models.Cursor{IsPinned: last.IsPinned, ID: last.ID}
+	if err := d.DB.Model(&models.ClipboardItem{}).
+		Select(key).
+		Where("id = ?", last.ID).
+		Row().Scan(&next.SortKey); err != nil {
+		return nil, err
+	}
+	page.Next = next
+	return page, nil
+}
+
+// GetAdjacentItems returns the items just before and after id in the list
+// order for mode and ascending, pinned items first. Either is nil at
+// the ends.
+func (d *Database) GetAdjacentItems(id string, mode SortMode, ascending bool) (prev, next *models.ClipboardItem, err error) {
+	key, err := sortKey(mode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var item models.ClipboardItem
+	if err := d.DB.Where("id = ?", id).First(&item).Error; err != nil {
+		return nil, nil, err
+	}
+	current := models.Cursor{IsPinned: item.IsPinned, ID: item.ID}
+	if err := d.DB.Model(&models.ClipboardItem{}).
+		Select(key).
+		Where("id = ?", id).
+		Row().Scan(&current.SortKey); err != nil {
+		return nil, nil, err
+	}
+
+	// Within a pinned group the list runs down the sort key, or up it when
+	// ascending, with ids breaking ties the same way
+	down, up := "<", ">"
+	if ascending {
+		down, up = up, down
+	}
+	if next, err = d.adjacentItem(current, key, "<", down); err != nil {
+		return nil, nil, err
+	}
+	if prev, err = d.adjacentItem(current, key, ">", up); err != nil {
+		return nil, nil, err
+	}
+	return prev, next, nil
+}
+
+// adjacentItem returns the item closest to current among those whose
+// is_pinned compares to it by pinnedOp, or that share it and whose sort key
+// compares by keyOp. Nil means there is none.
+func (d *Database) adjacentItem(current models.Cursor, key string, pinnedOp string, keyOp string) (*models.ClipboardItem, error) {
+	direction := func(op string) string {
+		if op == "<" {
+			return " DESC"
+		}
+		return " ASC"
+	}
+
+	var items []models.ClipboardItem
+	if err := d.DB.Where("is_pinned "+pinnedOp+" ? OR (is_pinned = ? AND ("+key+" "+keyOp+" ? OR ("+key+" = ? AND id "+keyOp+" ?)))",
+		current.IsPinned, current.IsPinned, current.SortKey, current.SortKey, current.ID).
+		Order("is_pinned" + direction(pinnedOp) + ", " + key + direction(keyOp) + ", id" + direction(keyOp)).
+		Limit(1).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return &items[0], nil
+}
+
+func (d *Database) GetClipboardItems(limit int, offset int, contentType string, mode SortMode) ([]models.ClipboardItem, error) {
+	return d.GetClipboardItemsOrdered(limit, offset, contentType, mode, false)
+}
+
+// GetClipboardItemsOrdered is GetClipboardItems with a choice of direction;
+// ascending lists the oldest unpinned items first
+func (d *Database) GetClipboardItemsOrdered(limit int, offset int, contentType string, mode SortMode, ascending bool) ([]models.ClipboardItem, error) {
+	return d.orderedItems(clampLimit(limit), offset, contentType, mode, ascending)
+}
+
+// orderedItems is GetClipboardItemsOrdered without the limit clamped
+func (d *Database) orderedItems(limit int, offset int, contentType string, mode SortMode, ascending bool) ([]models.ClipboardItem, error) {
 	var items []models.ClipboardItem
 	query := d.DB.Model(&models.ClipboardItem{})
 
@@ -134,14 +925,12 @@ func (d *Database) GetClipboardItems(limit int, offset int, contentType string,
 		query = query.Where("content_type = ?", contentType)
 	}
 
-	var orderClause string
-	if sortByRecent == "copied" {
-		orderClause = "is_pinned DESC, created_at DESC"
-	} else {
-		orderClause = "is_pinned DESC, last_accessed DESC"
+	orderClause, err := orderBy(mode, ascending)
+	if err != nil {
+		return nil, err
 	}
 
-	err := query.Order(orderClause).
+	err = query.Order(orderClause).
 		Limit(limit).
 		Offset(offset).
 		Find(&items).Error
@@ -149,17 +938,123 @@ func (d *Database) GetClipboardItems(limit int, offset int, contentType string,
 	return items, err
 }
 
-func (d *Database) SearchClipboardItems(searchTerm string, limit int, offset int, sortByRecent string) ([]models.ClipboardItem, error) {
+// likeEscaper escapes LIKE wildcards so search terms match literally; the
+// pattern must be used with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern returns a LIKE pattern matching text containing term
+func containsPattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
+
+// GetClipboardItemsPage is GetClipboardItemsOrdered with whether more items
+// follow the page. It asks for one extra row and trims it, which is cheaper
+// than counting.
+func (d *Database) GetClipboardItemsPage(limit int, offset int, contentType string, mode SortMode, ascending bool) (*models.ItemsPage, error) {
+	limit = clampLimit(limit)
+	items, err := d.orderedItems(limit+1, offset, contentType, mode, ascending)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ItemsPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+	}
+	return page, nil
+}
+
+// SearchClipboardItems finds items whose preview contains searchTerm.
+// pinnedOnly limits the search to pinned items, as do the other searches.
+func (d *Database) SearchClipboardItems(searchTerm string, limit int, offset int, mode SortMode, pinnedOnly bool) ([]models.ClipboardItem, error) {
+	var items []models.ClipboardItem
+	limit = clampLimit(limit)
+
+	orderClause, err := orderBy(mode, false)
+	if err != nil {
+		return nil, err
+	}
+
+	query := d.DB.Where(`preview_text LIKE ? ESCAPE '\'`, containsPattern(searchTerm))
+	if pinnedOnly {
+		query = query.Where("is_pinned = true")
+	}
+	err = query.
+		Order(orderClause).
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error
+	return items, err
+}
+
+// searchFields maps the field names accepted by SearchAllFields to columns
+var searchFields = map[string]string{
+	"preview":   "preview_text",
+	"note":      "note",
+	"tags":      "tags",
+	"sourceApp": "source_app",
+}
+
+// searchFieldValue returns the value of a searchFields field of item
+func searchFieldValue(item *models.ClipboardItem, field string) string {
+	switch field {
+	case "preview":
+		return item.PreviewText
+	case "note":
+		return item.Note
+	case "tags":
+		return item.Tags
+	case "sourceApp":
+		return item.SourceApp
+	}
+	return ""
+}
+
+// wholeWordPattern matches term case-insensitively where it isn't part of a
+// longer word. \b is avoided since it has no boundary next to punctuation,
+// so terms like "c++" would never match.
+func wholeWordPattern(term string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|[^\pL\pN_])` + regexp.QuoteMeta(term) + `($|[^\pL\pN_])`)
+}
+
+// SearchAllFields matches searchTerm against the preview text and item
+// metadata. fields limits which of "preview", "note", "tags" and "sourceApp"
+// are searched; nil or empty searches all of them. Results are in the list
+// order for mode and ascending. wholeWord only matches searchTerm as
+// a whole word, so "cat" doesn't find "category".
+func (d *Database) SearchAllFields(searchTerm string, fields []string, limit int, offset int, mode SortMode, ascending bool, pinnedOnly bool, wholeWord bool) ([]models.ClipboardItem, error) {
 	var items []models.ClipboardItem
+	limit = clampLimit(limit)
 
-	var orderClause string
-	if sortByRecent == "copied" {
-		orderClause = "is_pinned DESC, created_at DESC"
-	} else {
-		orderClause = "is_pinned DESC, last_accessed DESC"
+	if len(fields) == 0 {
+		fields = []string{"preview", "note", "tags", "sourceApp"}
 	}
 
-	err := d.DB.Where("preview_text LIKE ?", "%"+searchTerm+"%").
+	var clauses []string
+	var args []interface{}
+	for _, field := range fields {
+		column, ok := searchFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown search field: %s", field)
+		}
+		clauses = append(clauses, column+` LIKE ? ESCAPE '\'`)
+		args = append(args, containsPattern(searchTerm))
+	}
+
+	orderClause, err := orderBy(mode, ascending)
+	if err != nil {
+		return nil, err
+	}
+
+	query := d.DB.Where(strings.Join(clauses, " OR "), args...)
+	if pinnedOnly {
+		query = query.Where("is_pinned = true")
+	}
+	if wholeWord && searchTerm != "" {
+		return d.filterWholeWord(query.Order(orderClause), searchTerm, fields, limit, offset)
+	}
+	err = query.
 		Order(orderClause).
 		Limit(limit).
 		Offset(offset).
@@ -167,17 +1062,83 @@ func (d *Database) SearchClipboardItems(searchTerm string, limit int, offset int
 	return items, err
 }
 
-func (d *Database) SearchClipboardItemsRegex(regexPattern string, limit int, offset int, sortByRecent string) ([]models.ClipboardItem, error) {
+// wholeWordBatchSize is how many substring matches filterWholeWord reads
+// per query
+const wholeWordBatchSize = 200
+
+// filterWholeWord narrows the substring matches of query down to those where
+// term is a whole word. Paging happens after filtering, since SQLite can't
+// tell which rows survive it, so candidates are read in batches of just the
+// searched columns until the page is filled. Only the page is loaded in full.
+func (d *Database) filterWholeWord(query *gorm.DB, term string, fields []string, limit int, offset int) ([]models.ClipboardItem, error) {
+	columns := []string{"id"}
+	for _, field := range fields {
+		columns = append(columns, searchFields[field])
+	}
+
+	pattern := wholeWordPattern(term)
+	var ids []string
+	for start := 0; len(ids) < offset+limit; start += wholeWordBatchSize {
+		var candidates []models.ClipboardItem
+		if err := query.Session(&gorm.Session{}).
+			Select(columns).
+			Limit(wholeWordBatchSize).
+			Offset(start).
+			Find(&candidates).Error; err != nil {
+			return nil, err
+		}
+
+		for i := range candidates {
+			for _, field := range fields {
+				if pattern.MatchString(searchFieldValue(&candidates[i], field)) {
+					ids = append(ids, candidates[i].ID)
+					break
+				}
+			}
+		}
+		if len(candidates) < wholeWordBatchSize {
+			break
+		}
+	}
+
+	if offset >= len(ids) {
+		return []models.ClipboardItem{}, nil
+	}
+	ids = ids[offset:min(len(ids), offset+limit)]
+
+	var rows []models.ClipboardItem
+	if err := d.DB.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.ClipboardItem, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+
+	// Keep the search order; rows deleted since the scan are dropped
+	items := make([]models.ClipboardItem, 0, len(ids))
+	for _, id := range ids {
+		if item, ok := byID[id]; ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (d *Database) SearchClipboardItemsRegex(regexPattern string, limit int, offset int, mode SortMode, ascending bool, pinnedOnly bool) ([]models.ClipboardItem, error) {
 	var items []models.ClipboardItem
-	var orderClause string
-	if sortByRecent == "copied" {
-		orderClause = "is_pinned DESC, created_at DESC"
-	} else {
-		orderClause = "is_pinned DESC, last_accessed DESC"
+	limit = clampLimit(limit)
+	orderClause, err := orderBy(mode, ascending)
+	if err != nil {
+		return nil, err
 	}
 
 	// SQLite REGEXP operator (if available)
-	err := d.DB.Where("preview_text REGEXP ?", regexPattern).
+	query := d.DB.Where("preview_text REGEXP ?", regexPattern)
+	if pinnedOnly {
+		query = query.Where("is_pinned = true")
+	}
+	err = query.
 		Order(orderClause).
 		Limit(limit).
 		Offset(offset).
@@ -192,78 +1153,683 @@ func (d *Database) GetClipboardItemByID(id string) (*models.ClipboardItem, error
 }
 
 func (d *Database) UpdateClipboardItem(item *models.ClipboardItem) error {
-	return d.DB.Save(item).Error
+	return d.write(func(db *gorm.DB) error {
+		return db.Save(item).Error
+	})
 }
 
 func (d *Database) DeleteClipboardItem(id string) error {
-	return d.DB.Where("id = ?", id).Delete(&models.ClipboardItem{}).Error
+	return d.write(func(db *gorm.DB) error {
+		return db.Where("id = ?", id).Delete(&models.ClipboardItem{}).Error
+	})
 }
 
 func (d *Database) PinClipboardItem(id string, pinned bool) error {
-	return d.DB.Model(&models.ClipboardItem{}).
-		Where("id = ?", id).
-		Update("is_pinned", pinned).Error
+	return d.write(func(db *gorm.DB) error {
+		return db.Model(&models.ClipboardItem{}).
+			Where("id = ?", id).
+			Update("is_pinned", pinned).Error
+	})
 }
 
-func (d *Database) CleanupOldItems(maxItems int, maxDays int) error {
-	// Delete items older than maxDays (excluding pinned items)
-	cutoffDate := time.Now().AddDate(0, 0, -maxDays)
-	if err := d.DB.Where("created_at < ? AND is_pinned = false", cutoffDate).
-		Delete(&models.ClipboardItem{}).Error; err != nil {
-		return err
+// SetItemOneTime marks an item to be deleted the next time it's copied back
+func (d *Database) SetItemOneTime(id string, oneTime bool) error {
+	return d.write(func(db *gorm.DB) error {
+		return db.Model(&models.ClipboardItem{}).
+			Where("id = ?", id).
+			Update("is_one_time", oneTime).Error
+	})
+}
+
+// PinSearchResults sets the pin state of the first limit items that
+// SearchAllFields finds for query, returning how many changed; items already
+// in that state aren't counted. The update is a single statement, so either
+// every match changes or none do. An empty query is rejected so a slip can't
+// pin or unpin the whole history.
+func (d *Database) PinSearchResults(query string, pinned bool, limit int, mode SortMode, ascending bool) (int, error) {
+	if strings.TrimSpace(query) == "" {
+		return 0, fmt.Errorf("search query is empty")
 	}
 
-	// Count total items (excluding pinned)
-	var count int64
-	if err := d.DB.Model(&models.ClipboardItem{}).
-		Where("is_pinned = false").
-		Count(&count).Error; err != nil {
-		return err
+	items, err := d.SearchAllFields(query, nil, limit, 0, mode, ascending, false, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
 	}
 
-	// If we have more than maxItems, delete the oldest ones
-	if int(count) > maxItems {
-		itemsToDelete := int(count) - maxItems
-		var oldestItems []models.ClipboardItem
+	var affected int64
+	err = d.write(func(db *gorm.DB) error {
+		result := db.Model(&models.ClipboardItem{}).
+			Where("id IN ? AND is_pinned <> ?", ids, pinned).
+			Update("is_pinned", pinned)
+		affected = result.RowsAffected
+		return result.Error
+	})
+	return int(affected), err
+}
 
-		if err := d.DB.Where("is_pinned = false").
-			Order("created_at ASC").
-			Limit(itemsToDelete).
-			Find(&oldestItems).Error; err != nil {
+// PromoteItem moves an item to the top of the recency sorts by stamping its
+// capture, access and seen times with now. Content and pin state are kept.
+func (d *Database) PromoteItem(id string) error {
+	now := time.Now()
+	return d.write(func(db *gorm.DB) error {
+		result := db.Model(&models.ClipboardItem{}).
+			Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{
+				"created_at":    now,
+				"last_accessed": now,
+				"last_seen":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// CleanupOldItems deletes unpinned items older than maxDays, then the oldest
+// unpinned items beyond maxItems, and returns how many it deleted. A limit
+// of 0 or less means unlimited, so that step is skipped.
+func (d *Database) CleanupOldItems(maxItems int, maxDays int) (int, error) {
+	deleted := 0
+	err := d.write(func(db *gorm.DB) error {
+		// Delete items older than maxDays (excluding pinned items)
+		if maxDays > 0 {
+			cutoffDate := time.Now().AddDate(0, 0, -maxDays)
+			result := db.Where("created_at < ? AND is_pinned = false", cutoffDate).
+				Delete(&models.ClipboardItem{})
+			if result.Error != nil {
+				return result.Error
+			}
+			deleted += int(result.RowsAffected)
+		}
+
+		if maxItems <= 0 {
+			return nil
+		}
+
+		// Count total items (excluding pinned)
+		var count int64
+		if err := db.Model(&models.ClipboardItem{}).
+			Where("is_pinned = false").
+			Count(&count).Error; err != nil {
 			return err
 		}
 
-		for _, item := range oldestItems {
-			if err := d.DB.Delete(&item).Error; err != nil {
+		// If we have more than maxItems, delete the oldest ones
+		if int(count) > maxItems {
+			itemsToDelete := int(count) - maxItems
+			var oldestItems []models.ClipboardItem
+
+			if err := db.Where("is_pinned = false").
+				Order("created_at ASC").
+				Limit(itemsToDelete).
+				Find(&oldestItems).Error; err != nil {
 				return err
 			}
+
+			for _, item := range oldestItems {
+				result := db.Delete(&item)
+				if result.Error != nil {
+					return result.Error
+				}
+				deleted += int(result.RowsAffected)
+			}
 		}
+
+		return nil
+	})
+	return deleted, err
+}
+
+// TrimItemsPerApp deletes the oldest unpinned items of every source app
+// holding more than maxPerApp of them. Items with no known source app are
+// left alone, as is everything when maxPerApp is 0 or less. It returns how
+// many items it deleted.
+func (d *Database) TrimItemsPerApp(maxPerApp int) (int, error) {
+	if maxPerApp <= 0 {
+		return 0, nil
 	}
 
-	return nil
+	deleted := 0
+	err := d.write(func(db *gorm.DB) error {
+		var over []struct {
+			SourceApp string
+			Count     int
+		}
+		if err := db.Model(&models.ClipboardItem{}).
+			Select("source_app, COUNT(*) AS count").
+			Where("is_pinned = false AND source_app != ''").
+			Group("source_app").
+			Having("COUNT(*) > ?", maxPerApp).
+			Scan(&over).Error; err != nil {
+			return err
+		}
+
+		for _, app := range over {
+			var ids []string
+			if err := db.Model(&models.ClipboardItem{}).
+				Where("is_pinned = false AND source_app = ?", app.SourceApp).
+				Order("created_at ASC").
+				Limit(app.Count-maxPerApp).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			result := db.Where("id IN ?", ids).Delete(&models.ClipboardItem{})
+			if result.Error != nil {
+				return result.Error
+			}
+			deleted += int(result.RowsAffected)
+		}
+		return nil
+	})
+	return deleted, err
 }
 
+// GetItemByHash returns the item with the given content hash. Older
+// normalization and imports can leave several; a pinned one wins, then the
+// most recent.
 func (d *Database) GetItemByHash(hash string) (*models.ClipboardItem, error) {
 	var item models.ClipboardItem
-	err := d.DB.Where("hash = ?", hash).First(&item).Error
+	err := d.DB.Where("hash = ?", hash).Order("is_pinned DESC, created_at DESC").First(&item).Error
 	if err != nil {
 		return nil, err
 	}
 	return &item, nil
 }
 
-func (d *Database) ClearAllItems(preservePinned bool) error {
-	query := d.DB
-	if preservePinned {
-		query = query.Where("is_pinned = false")
+// FindItemsByHash returns every item whose exact or normalized content hash
+// matches, newest first
+func (d *Database) FindItemsByHash(hash string, dedupHash string) ([]models.ClipboardItem, error) {
+	var items []models.ClipboardItem
+	err := d.DB.Where("hash = ? OR dedup_hash = ?", hash, dedupHash).
+		Order("created_at DESC").
+		Find(&items).Error
+	return items, err
+}
+
+// FindImageItemsByContent returns image items storing exactly data, newest
+// first. Only blobs of the same length are loaded to compare.
+func (d *Database) FindImageItemsByContent(data []byte) ([]models.ClipboardItem, error) {
+	var candidates []models.ClipboardItem
+	if err := d.DB.Select("id", "content_binary").
+		Where("content_type = ? AND encrypted = false AND length(content_binary) = ?", "image", len(data)).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, candidate := range candidates {
+		if bytes.Equal(candidate.ContentBinary, data) {
+			ids = append(ids, candidate.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
 	}
-	return query.Delete(&models.ClipboardItem{}).Error
+
+	var items []models.ClipboardItem
+	err := d.DB.Where("id IN ?", ids).Order("created_at DESC").Find(&items).Error
+	return items, err
+}
+
+// GetItemByDedupHash finds the item a capture with the given normalized hash
+// duplicates, preferring the oldest if several match
+func (d *Database) GetItemByDedupHash(dedupHash string) (*models.ClipboardItem, error) {
+	var item models.ClipboardItem
+	err := d.DB.Where("dedup_hash = ?", dedupHash).Order("created_at ASC").First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Classification is what content detection says about an item's text
+type Classification struct {
+	ContentType string
+	Language    string
+	ColorHex    string
+	Rows        int // Table size, 0 unless ContentType is "table"
+	Cols        int
+}
+
+// ProgressFunc is told how many of total rows a long maintenance operation
+// has processed so far. Operations accept a nil ProgressFunc.
+type ProgressFunc func(processed, total int)
+
+// importProgressInterval is how many items ImportItems processes between
+// progress reports
+const importProgressInterval = 100
+
+// reclassifyBatchSize is how many items ReclassifyItems reads and updates per
+// transaction, so the capture loop isn't locked out for long
+const reclassifyBatchSize = 200
+
+// ReclassifyItems runs classify over the text of every item without binary
+// content and updates the items whose type, language, color or table size
+// changed. Encrypted items are skipped since their text isn't readable here.
+// progress, if set, is called after each batch.
+func (d *Database) ReclassifyItems(classify func(content string) Classification, progress ProgressFunc) (int, error) {
+	const eligible = "encrypted = false AND (content_binary IS NULL OR length(content_binary) = 0)"
+
+	var total int64
+	if progress != nil {
+		if err := d.DB.Model(&models.ClipboardItem{}).Where(eligible).Count(&total).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	updated := 0
+	processed := 0
+	lastID := ""
+
+	for {
+		var batch []models.ClipboardItem
+		if err := d.DB.Select("id", "content_type", "content_text", "language", "color_hex", "rows", "cols").
+			Where("id > ? AND "+eligible, lastID).
+			Order("id").
+			Limit(reclassifyBatchSize).
+			Find(&batch).Error; err != nil {
+			return updated, err
+		}
+		if len(batch) == 0 {
+			return updated, nil
+		}
+		lastID = batch[len(batch)-1].ID
+
+		err := d.write(func(db *gorm.DB) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				for _, item := range batch {
+					result := classify(item.ContentText)
+					if result.ContentType == item.ContentType && result.Language == item.Language && result.ColorHex == item.ColorHex &&
+						result.Rows == item.Rows && result.Cols == item.Cols {
+						continue
+					}
+					if err := tx.Model(&models.ClipboardItem{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+						"content_type": result.ContentType,
+						"language":     result.Language,
+						"color_hex":    result.ColorHex,
+						"rows":         result.Rows,
+						"cols":         result.Cols,
+					}).Error; err != nil {
+						return err
+					}
+					updated++
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return updated, err
+		}
+
+		if progress != nil {
+			processed += len(batch)
+			progress(processed, int(total))
+		}
+	}
+}
+
+// GetItemsSince returns up to limit items created after since, oldest first,
+// so a client can catch up from the newest item it already has. afterID, if
+// set, is that item's id: items created at the same instant are ordered by
+// id, and those after it are included rather than skipped.
+func (d *Database) GetItemsSince(since time.Time, afterID string, limit int) ([]models.ClipboardItem, error) {
+	var items []models.ClipboardItem
+	limit = clampLimit(limit)
+	// created_at is stored as text in local time, so the bound has to be in
+	// the same zone to compare correctly
+	since = since.Local()
+	query := d.DB.Where("created_at > ?", since)
+	if afterID != "" {
+		query = d.DB.Where("created_at > ? OR (created_at = ? AND id > ?)", since, since, afterID)
+	}
+	err := query.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// HasItems reports whether history holds any item at all. It stops at the
+// first row, so it stays cheap however large history grows.
+func (d *Database) HasItems() (bool, error) {
+	var found int
+	result := d.DB.Model(&models.ClipboardItem{}).Select("1").Limit(1).Scan(&found)
+	return result.RowsAffected > 0, result.Error
+}
+
+// MaxRecentSearches is how many past search queries are kept
+const MaxRecentSearches = 20
+
+// AddRecentSearch records query as the latest search. A query searched
+// before moves to the front instead of appearing twice, and the oldest are
+// dropped beyond MaxRecentSearches. Blank queries are ignored.
+func (d *Database) AddRecentSearch(query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	return d.write(func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("query = ?", query).Delete(&models.RecentSearch{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.RecentSearch{Query: query, SearchedAt: time.Now()}).Error; err != nil {
+				return err
+			}
+
+			newest := tx.Model(&models.RecentSearch{}).
+				Select("query").
+				Order("searched_at DESC").
+				Limit(MaxRecentSearches)
+			return tx.Where("query NOT IN (?)", newest).Delete(&models.RecentSearch{}).Error
+		})
+	})
+}
+
+// GetRecentSearches returns past search queries, most recent first
+func (d *Database) GetRecentSearches() ([]string, error) {
+	var queries []string
+	err := d.DB.Model(&models.RecentSearch{}).
+		Order("searched_at DESC").
+		Limit(MaxRecentSearches).
+		Pluck("query", &queries).Error
+	return queries, err
+}
+
+// GetLatestUnpinnedItem returns the most recently captured unpinned item
+func (d *Database) GetLatestUnpinnedItem() (*models.ClipboardItem, error) {
+	var item models.ClipboardItem
+	err := d.DB.Where("is_pinned = false").Order("created_at DESC").First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ClearAllItems removes every item, or every unpinned one if preservePinned
+func (d *Database) ClearAllItems(preservePinned bool) error {
+	return d.write(func(db *gorm.DB) error {
+		// GORM refuses a delete with no conditions, so match all rows explicitly
+		query := db.Where("1 = 1")
+		if preservePinned {
+			query = query.Where("is_pinned = false")
+		}
+		return query.Delete(&models.ClipboardItem{}).Error
+	})
+}
+
+// Vacuum rebuilds the database file to release space left by deleted rows
+func (d *Database) Vacuum() error {
+	return d.write(func(db *gorm.DB) error {
+		return db.Exec("VACUUM").Error
+	})
 }
 
 func (d *Database) ClearItemsByType(contentType string, preservePinned bool) error {
-	query := d.DB.Where("content_type = ?", contentType)
-	if preservePinned {
-		query = query.Where("is_pinned = false")
+	return d.write(func(db *gorm.DB) error {
+		query := db.Where("content_type = ?", contentType)
+		if preservePinned {
+			query = query.Where("is_pinned = false")
+		}
+		return query.Delete(&models.ClipboardItem{}).Error
+	})
+}
+
+// ClearItemsInRange removes items created within [start, end). A zero bound
+// leaves that side of the range open.
+func (d *Database) ClearItemsInRange(start, end time.Time, preservePinned bool) error {
+	return d.write(func(db *gorm.DB) error {
+		// created_at is stored as text in local time, so the bounds have to
+		// be in the same zone to compare correctly
+		query := db.Where("1 = 1")
+		if !start.IsZero() {
+			query = query.Where("created_at >= ?", start.Local())
+		}
+		if !end.IsZero() {
+			query = query.Where("created_at < ?", end.Local())
+		}
+		if preservePinned {
+			query = query.Where("is_pinned = false")
+		}
+		return query.Delete(&models.ClipboardItem{}).Error
+	})
+}
+
+// splitTags parses a comma-separated Tags value, dropping blanks
+func splitTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// GetAllTags returns every tag in use, sorted and de-duplicated
+func (d *Database) GetAllTags() ([]string, error) {
+	var values []string
+	if err := d.DB.Model(&models.ClipboardItem{}).
+		Where("tags <> ''").
+		Distinct().
+		Pluck("tags", &values).Error; err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, value := range values {
+		for _, tag := range splitTags(value) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// TagItems adds tags to each of the given items, keeping their existing tags.
+// Either every item is tagged or, on error, none are.
+func (d *Database) TagItems(ids []string, tags []string) error {
+	tags = splitTags(strings.Join(tags, ","))
+	if len(ids) == 0 || len(tags) == 0 {
+		return nil
+	}
+
+	return d.write(func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			for _, id := range ids {
+				var item models.ClipboardItem
+				if err := tx.Select("id", "tags").First(&item, "id = ?", id).Error; err != nil {
+					return fmt.Errorf("item %s: %w", id, err)
+				}
+
+				merged := splitTags(item.Tags)
+				for _, tag := range tags {
+					if !slices.Contains(merged, tag) {
+						merged = append(merged, tag)
+					}
+				}
+
+				if err := tx.Model(&models.ClipboardItem{}).
+					Where("id = ?", id).
+					Update("tags", strings.Join(merged, ",")).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// Strategies for ImportItems when an imported item's hash is already stored
+const (
+	ImportSkip      = "skip"      // Leave the stored item alone
+	ImportOverwrite = "overwrite" // Take pin state, tags and note from the import
+	ImportKeepBoth  = "keep-both" // Store the import as a separate item
+)
+
+// ImportItems stores items in one transaction, resolving hash collisions with
+// strategy ("" means ImportSkip). Items must already carry an ID and the hash
+// of their content. It returns how many items were inserted or overwritten.
+// progress, if set, is called every importProgressInterval items and at the end.
+func (d *Database) ImportItems(items []models.ClipboardItem, strategy string, progress ProgressFunc) (int, error) {
+	if strategy == "" {
+		strategy = ImportSkip
+	}
+	if strategy != ImportSkip && strategy != ImportOverwrite && strategy != ImportKeepBoth {
+		return 0, fmt.Errorf("unknown import strategy: %s", strategy)
+	}
+
+	imported := 0
+	err := d.write(func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			for i := range items {
+				if progress != nil && i > 0 && i%importProgressInterval == 0 {
+					progress(i, len(items))
+				}
+				item := items[i]
+
+				var existing models.ClipboardItem
+				err := tx.Where("hash = ?", item.Hash).First(&existing).Error
+				switch {
+				case errors.Is(err, gorm.ErrRecordNotFound):
+				case err != nil:
+					return err
+				case strategy == ImportSkip:
+					continue
+				case strategy == ImportOverwrite:
+					if err := tx.Model(&existing).Select("is_pinned", "tags", "note").Updates(&models.ClipboardItem{
+						IsPinned: item.IsPinned,
+						Tags:     item.Tags,
+						Note:     item.Note,
+					}).Error; err != nil {
+						return err
+					}
+					imported++
+					continue
+				case strategy == ImportKeepBoth:
+					// Hashes must stay unique for dedup; new captures of this
+					// content keep matching the item that was already stored
+					item.Hash = item.Hash + ":" + item.ID
+				}
+
+				if item.CreatedAt.IsZero() {
+					item.CreatedAt = time.Now()
+				}
+				if item.LastAccessed.IsZero() {
+					item.LastAccessed = item.CreatedAt
+				}
+				item.SizeBytes = item.ContentSize()
+
+				if err := tx.Create(&item).Error; err != nil {
+					return fmt.Errorf("item %s: %w", item.ID, err)
+				}
+				imported++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if progress != nil {
+		progress(len(items), len(items))
+	}
+	return imported, nil
+}
+
+func (d *Database) GetStatistics() (*models.Statistics, error) {
+	stats := &models.Statistics{ItemsByType: make(map[string]int64)}
+
+	var totals struct {
+		TotalItems     int64
+		TotalSizeBytes int64
+	}
+	if err := d.DB.Model(&models.ClipboardItem{}).
+		Select("COUNT(*) AS total_items, COALESCE(SUM(size_bytes), 0) AS total_size_bytes").
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	stats.TotalItems = totals.TotalItems
+	stats.TotalSizeBytes = totals.TotalSizeBytes
+
+	if err := d.DB.Model(&models.ClipboardItem{}).
+		Where("is_pinned = true").
+		Count(&stats.PinnedItems).Error; err != nil {
+		return nil, err
+	}
+
+	var typeCounts []struct {
+		ContentType string
+		Count       int64
+	}
+	if err := d.DB.Model(&models.ClipboardItem{}).
+		Select("content_type, COUNT(*) AS count").
+		Group("content_type").
+		Scan(&typeCounts).Error; err != nil {
+		return nil, err
+	}
+	for _, tc := range typeCounts {
+		stats.ItemsByType[tc.ContentType] = tc.Count
+	}
+
+	return stats, nil
+}
+
+// MaxCountDays caps how many days GetCaptureCountsByDay reports, since it
+// returns an entry for every day whether or not anything was captured
+const MaxCountDays = 366
+
+// GetCaptureCountsByDay returns how many items were captured on each of the
+// last days local calendar days, oldest first and ending today. Days without
+// captures are included with a count of 0. days is capped at MaxCountDays.
+func (d *Database) GetCaptureCountsByDay(days int) ([]models.DayCount, error) {
+	if days <= 0 {
+		return []models.DayCount{}, nil
+	}
+	days = min(days, MaxCountDays)
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day()-(days-1), 0, 0, 0, 0, now.Location())
+
+	var rows []struct {
+		Day   string
+		Count int64
+	}
+	if err := d.DB.Model(&models.ClipboardItem{}).
+		Select("date(created_at, 'localtime') AS day, COUNT(*) AS count").
+		Where("created_at >= ?", start).
+		Group("day").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		byDay[row.Day] = row.Count
+	}
+
+	counts := make([]models.DayCount, days)
+	for i := range counts {
+		day := start.AddDate(0, 0, i).Format("2006-01-02")
+		counts[i] = models.DayCount{Date: day, Count: byDay[day]}
 	}
-	return query.Delete(&models.ClipboardItem{}).Error
+	return counts, nil
 }
@@ -1,7 +1,12 @@
 package database
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -9,24 +14,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func setupTestDB(t *testing.T) *Database {
-	tempDir := t.TempDir()
-
-	originalHome := os.Getenv("HOME")
-	testHome := tempDir
-	if err := os.Setenv("HOME", testHome); err != nil {
-		t.Fatalf("Failed to set HOME: %v", err)
-	}
-
-	t.Cleanup(func() {
-		if err := os.Setenv("HOME", originalHome); err != nil {
-			t.Logf("Failed to restore HOME: %v", err)
-		}
-	})
-
-	db, err := New()
+	db, err := NewWithPath(t.TempDir(), nil)
 	require.NoError(t, err)
 	require.NotNil(t, db)
 
@@ -45,6 +37,270 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, int64(2), tableCount)
 }
 
+func TestNewWithPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "external", "Klipd")
+
+	db, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The directory and database file should both be created
+	_, err = os.Stat(filepath.Join(dir, "clipboard.db"))
+	assert.NoError(t, err)
+
+	item := &models.ClipboardItem{
+		ID:          "custom-path-item",
+		ContentType: "text",
+		ContentText: "Stored on a custom path",
+		PreviewText: "Stored on a custom path",
+		Hash:        "custom-path-hash",
+	}
+	require.NoError(t, db.CreateClipboardItem(item))
+	require.NoError(t, db.Close())
+
+	// Reopening the same path should see the stored item
+	reopened, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	retrieved, err := reopened.GetClipboardItemByID("custom-path-item")
+	assert.NoError(t, err)
+	assert.Equal(t, "Stored on a custom path", retrieved.ContentText)
+}
+
+func TestNewWithPathRecoversCorruptDatabase(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "clipboard.db")
+	garbage := bytes.Repeat([]byte("not a database "), 512)
+	require.NoError(t, os.WriteFile(path, garbage, 0644))
+
+	db, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The corrupt file is kept for inspection
+	backup := db.RecoveredBackup()
+	require.NotEmpty(t, backup)
+	assert.Equal(t, dir, filepath.Dir(backup))
+	saved, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, garbage, saved)
+
+	// The replacement is a working, empty database
+	_, err = db.GetSettings()
+	require.NoError(t, err)
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "after-recovery", ContentType: "text", ContentText: "fresh", PreviewText: "fresh", Hash: "recovery-hash",
+	}))
+	items, err := db.GetClipboardItems(10, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"after-recovery"}, itemIDs(items))
+	require.NoError(t, db.Close())
+
+	// A healthy database reopens without another reset
+	reopened, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Empty(t, reopened.RecoveredBackup())
+	_, err = reopened.GetClipboardItemByID("after-recovery")
+	assert.NoError(t, err)
+}
+
+func TestNewWithPathCustomPragmas(t *testing.T) {
+	db, err := NewWithPath(t.TempDir(), map[string]string{
+		"busy_timeout": "5000",
+		"cache_size":   "-4000",
+		"bad;name":     "1",
+		"foreign_keys": "ON; DROP TABLE clipboard_items",
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	var busyTimeout, cacheSize int
+	require.NoError(t, db.DB.Raw("PRAGMA busy_timeout").Scan(&busyTimeout).Error)
+	require.NoError(t, db.DB.Raw("PRAGMA cache_size").Scan(&cacheSize).Error)
+	assert.Equal(t, 5000, busyTimeout)
+	assert.Equal(t, -4000, cacheSize)
+
+	// Invalid entries are skipped rather than executed
+	assert.True(t, db.DB.Migrator().HasTable(&models.ClipboardItem{}))
+}
+
+func TestPragmasEnvOverride(t *testing.T) {
+	t.Setenv(PragmasEnv, "busy_timeout=7000; malformed ;")
+
+	db, err := NewWithPath(t.TempDir(), map[string]string{"busy_timeout": "5000"})
+	require.NoError(t, err)
+	defer db.Close()
+
+	var busyTimeout int
+	require.NoError(t, db.DB.Raw("PRAGMA busy_timeout").Scan(&busyTimeout).Error)
+	assert.Equal(t, 7000, busyTimeout)
+}
+
+func TestParsePragmas(t *testing.T) {
+	assert.Equal(t, map[string]string{"busy_timeout": "5000", "cache_size": "-2000"},
+		ParsePragmas(" busy_timeout = 5000;cache_size=-2000;;novalue"))
+	assert.Empty(t, ParsePragmas(""))
+}
+
+func TestDefaultDataDirEnvOverride(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "portable")
+	t.Setenv(DataDirEnv, dir)
+
+	resolved, err := DefaultDataDir()
+	assert.NoError(t, err)
+	assert.Equal(t, dir, resolved)
+
+	db, ephemeral, err := New()
+	require.NoError(t, err)
+	defer db.Close()
+	assert.False(t, ephemeral)
+
+	_, err = os.Stat(filepath.Join(dir, "clipboard.db"))
+	assert.NoError(t, err)
+}
+
+func TestNewFallsBackToMemoryWhenDirUnwritable(t *testing.T) {
+	// A regular file where a parent directory should be can't be created
+	// over, even when running as root
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	require.NoError(t, os.WriteFile(blocker, nil, 0644))
+	t.Setenv(DataDirEnv, filepath.Join(blocker, "Klipd"))
+
+	db, ephemeral, err := New()
+	require.NoError(t, err)
+	defer db.Close()
+	assert.True(t, ephemeral)
+
+	// The session still works, settings included
+	settings, err := db.GetSettings()
+	require.NoError(t, err)
+	assert.Equal(t, 100, settings.MaxItems)
+
+	item := &models.ClipboardItem{ID: "ephemeral", ContentType: "text", ContentText: "kept for now", Hash: "mem-1"}
+	require.NoError(t, db.CreateClipboardItem(item))
+	retrieved, err := db.GetClipboardItemByID("ephemeral")
+	require.NoError(t, err)
+	assert.Equal(t, "kept for now", retrieved.ContentText)
+}
+
+func TestDefaultDataDir(t *testing.T) {
+	t.Setenv(DataDirEnv, "")
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Skipf("No user config dir available: %v", err)
+	}
+
+	resolved, err := DefaultDataDir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "Klipd"), resolved)
+}
+
+// fakeResolvers mimics os.UserConfigDir's layout on each supported GOOS
+func fakeResolvers(home string) map[string]dataDirResolver {
+	homeDir := func() (string, error) { return home, nil }
+	configDirFor := func(dir string) func() (string, error) {
+		return func() (string, error) { return dir, nil }
+	}
+
+	return map[string]dataDirResolver{
+		"darwin":  {configDir: configDirFor(filepath.Join(home, "Library", "Application Support")), homeDir: homeDir},
+		"linux":   {configDir: configDirFor(filepath.Join(home, ".config")), homeDir: homeDir},
+		"windows": {configDir: configDirFor(filepath.Join(home, "AppData", "Roaming")), homeDir: homeDir},
+	}
+}
+
+func seedLegacyDatabase(t *testing.T, resolver dataDirResolver) string {
+	legacyDir, err := resolver.legacyDataDir()
+	require.NoError(t, err)
+
+	db, err := NewWithPath(legacyDir, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID:          "legacy-item",
+		ContentType: "text",
+		ContentText: "From an older release",
+		PreviewText: "From an older release",
+		Hash:        "legacy-hash",
+	}))
+	require.NoError(t, db.Close())
+
+	return legacyDir
+}
+
+func TestDataDirResolverPerOS(t *testing.T) {
+	home := t.TempDir()
+	expected := map[string]string{
+		"darwin":  filepath.Join(home, "Library", "Application Support", "Klipd"),
+		"linux":   filepath.Join(home, ".config", "Klipd"),
+		"windows": filepath.Join(home, "AppData", "Roaming", "Klipd"),
+	}
+
+	for goos, resolver := range fakeResolvers(home) {
+		t.Run(goos, func(t *testing.T) {
+			dir, err := resolver.dataDir()
+			assert.NoError(t, err)
+			assert.Equal(t, expected[goos], dir)
+		})
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	for _, goos := range []string{"darwin", "linux", "windows"} {
+		t.Run(goos, func(t *testing.T) {
+			resolver := fakeResolvers(t.TempDir())[goos]
+			legacyDir := seedLegacyDatabase(t, resolver)
+
+			dir, err := resolver.dataDir()
+			require.NoError(t, err)
+
+			migrated := resolver.migrateLegacy(dir)
+			assert.Equal(t, dir, migrated)
+
+			if goos != "darwin" {
+				// The legacy file should have been moved, not copied
+				_, err := os.Stat(filepath.Join(legacyDir, "clipboard.db"))
+				assert.True(t, os.IsNotExist(err))
+			}
+
+			db, err := NewWithPath(migrated, nil)
+			require.NoError(t, err)
+			defer db.Close()
+
+			item, err := db.GetClipboardItemByID("legacy-item")
+			assert.NoError(t, err)
+			assert.Equal(t, "From an older release", item.ContentText)
+		})
+	}
+}
+
+func TestMigrateLegacyKeepsExistingDatabase(t *testing.T) {
+	resolver := fakeResolvers(t.TempDir())["linux"]
+	legacyDir := seedLegacyDatabase(t, resolver)
+
+	dir, err := resolver.dataDir()
+	require.NoError(t, err)
+
+	current, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, current.Close())
+
+	assert.Equal(t, dir, resolver.migrateLegacy(dir))
+
+	// Both databases should be left alone
+	_, err = os.Stat(filepath.Join(legacyDir, "clipboard.db"))
+	assert.NoError(t, err)
+
+	db, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.GetClipboardItemByID("legacy-item")
+	assert.Error(t, err)
+}
+
 func TestCreateClipboardItem(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -91,7 +347,7 @@ func TestCreateClipboardItemDuplicate(t *testing.T) {
 	err = db.CreateClipboardItem(item2)
 	assert.NoError(t, err)
 
-	items, err := db.GetClipboardItems(10, 0, "", "copied")
+	items, err := db.GetClipboardItems(10, 0, "", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, items, 2)
 }
@@ -134,22 +390,22 @@ func TestGetClipboardItems(t *testing.T) {
 	}
 
 	// Test pagination
-	retrieved, err := db.GetClipboardItems(2, 0, "", "copied")
+	retrieved, err := db.GetClipboardItems(2, 0, "", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, retrieved, 2)
 
 	// Test with offset
-	retrieved, err = db.GetClipboardItems(2, 1, "", "copied")
+	retrieved, err = db.GetClipboardItems(2, 1, "", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, retrieved, 2)
 
 	// Test content type filter
-	retrieved, err = db.GetClipboardItems(10, 0, "text", "copied")
+	retrieved, err = db.GetClipboardItems(10, 0, "text", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, retrieved, 3)
 
 	// Test non-matching content type filter
-	retrieved, err = db.GetClipboardItems(10, 0, "image", "copied")
+	retrieved, err = db.GetClipboardItems(10, 0, "image", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, retrieved, 0)
 }
@@ -168,25 +424,154 @@ func TestSearchClipboardItems(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
-	results, err := db.SearchClipboardItems("Hello", 10, 0, "copied")
+	results, err := db.SearchClipboardItems("Hello", 10, 0, SortCopied, false)
 	assert.NoError(t, err)
 	assert.Len(t, results, 1)
 	assert.Equal(t, "search-1", results[0].ID)
 
-	results, err = db.SearchClipboardItems("hello", 10, 0, "copied")
+	results, err = db.SearchClipboardItems("hello", 10, 0, SortCopied, false)
 	assert.NoError(t, err)
 	assert.Len(t, results, 1)
 
-	results, err = db.SearchClipboardItems("program", 10, 0, "copied")
+	results, err = db.SearchClipboardItems("program", 10, 0, SortCopied, false)
 	assert.NoError(t, err)
 	assert.Len(t, results, 1)
 	assert.Equal(t, "search-2", results[0].ID)
 
-	results, err = db.SearchClipboardItems("nonexistent", 10, 0, "copied")
+	results, err = db.SearchClipboardItems("nonexistent", 10, 0, SortCopied, false)
 	assert.NoError(t, err)
 	assert.Len(t, results, 0)
 }
 
+func TestClipboardItemSizeBytes(t *testing.T) {
+	db := setupTestDB(t)
+
+	textItem := &models.ClipboardItem{
+		ID:          "size-text",
+		ContentType: "text",
+		ContentText: "héllo", // é is two bytes
+		PreviewText: "héllo",
+		Hash:        "size-text-hash",
+	}
+	imageItem := &models.ClipboardItem{
+		ID:            "size-image",
+		ContentType:   "image",
+		ContentBinary: []byte{0x89, 'P', 'N', 'G'},
+		PreviewText:   "Image",
+		Hash:          "size-image-hash",
+	}
+	require.NoError(t, db.CreateClipboardItem(textItem))
+	require.NoError(t, db.CreateClipboardItem(imageItem))
+
+	retrieved, err := db.GetClipboardItemByID("size-text")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, retrieved.SizeBytes)
+
+	retrieved, err = db.GetClipboardItemByID("size-image")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, retrieved.SizeBytes)
+}
+
+func TestSizeBytesBackfill(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+
+	// Simulate a database created before size tracking existed
+	require.NoError(t, db.DB.Migrator().DropColumn(&models.ClipboardItem{}, "size_bytes"))
+	require.NoError(t, db.DB.Exec(`INSERT INTO clipboard_items (id, content_type, content_text, preview_text, hash)
+		VALUES ('legacy', 'text', 'legacy content', 'legacy content', 'legacy-hash')`).Error)
+	require.NoError(t, db.Close())
+
+	db, err = NewWithPath(dir, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	retrieved, err := db.GetClipboardItemByID("legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, len("legacy content"), retrieved.SizeBytes)
+}
+
+func TestLastSeenBackfill(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+
+	// Simulate a database from before LastSeen was split out
+	require.NoError(t, db.DB.Migrator().DropColumn(&models.ClipboardItem{}, "last_seen"))
+	accessed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, db.DB.Exec(`INSERT INTO clipboard_items (id, content_type, content_text, preview_text, hash, last_accessed)
+		VALUES ('legacy', 'text', 'legacy content', 'legacy content', 'legacy-hash', ?)`, accessed).Error)
+	require.NoError(t, db.Close())
+
+	db, err = NewWithPath(dir, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	retrieved, err := db.GetClipboardItemByID("legacy")
+	require.NoError(t, err)
+	assert.True(t, accessed.Equal(retrieved.LastAccessed))
+	assert.True(t, accessed.Equal(retrieved.LastSeen))
+}
+
+func TestGetStatistics(t *testing.T) {
+	db := setupTestDB(t)
+
+	items := []models.ClipboardItem{
+		{ID: "stats-1", ContentType: "text", ContentText: "12345", PreviewText: "12345", Hash: "stats-hash-1", IsPinned: true},
+		{ID: "stats-2", ContentType: "text", ContentText: "123", PreviewText: "123", Hash: "stats-hash-2"},
+		{ID: "stats-3", ContentType: "image", ContentBinary: []byte{1, 2}, PreviewText: "Image", Hash: "stats-hash-3"},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(&item))
+	}
+
+	stats, err := db.GetStatistics()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalItems)
+	assert.Equal(t, int64(1), stats.PinnedItems)
+	assert.Equal(t, int64(10), stats.TotalSizeBytes)
+	assert.Equal(t, map[string]int64{"text": 2, "image": 1}, stats.ItemsByType)
+}
+
+func TestGetCaptureCountsByDay(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	noon := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, now.Location())
+	seeded := map[string]int{"today-a": 0, "today-b": 0, "yesterday": 1, "three-ago": 3, "too-old": 10}
+	for id, daysAgo := range seeded {
+		item := &models.ClipboardItem{ID: id, ContentType: "text", ContentText: id, Hash: id}
+		require.NoError(t, db.CreateClipboardItem(item))
+		require.NoError(t, db.DB.Model(item).Update("created_at", noon.AddDate(0, 0, -daysAgo)).Error)
+	}
+
+	counts, err := db.GetCaptureCountsByDay(5)
+	require.NoError(t, err)
+	require.Len(t, counts, 5)
+
+	day := func(daysAgo int) string { return noon.AddDate(0, 0, -daysAgo).Format("2006-01-02") }
+	assert.Equal(t, []models.DayCount{
+		{Date: day(4), Count: 0},
+		{Date: day(3), Count: 1},
+		{Date: day(2), Count: 0},
+		{Date: day(1), Count: 1},
+		{Date: day(0), Count: 2},
+	}, counts)
+
+	empty, err := db.GetCaptureCountsByDay(0)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	capped, err := db.GetCaptureCountsByDay(1 << 30)
+	require.NoError(t, err)
+	require.Len(t, capped, MaxCountDays)
+	assert.Equal(t, day(0), capped[MaxCountDays-1].Date)
+}
+
 func TestUpdateClipboardItemPin(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -245,6 +630,542 @@ func TestDeleteClipboardItem(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetClipboardItemsSmartOrder(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	items := []*models.ClipboardItem{
+		{ID: "favourite", ContentType: "text", ContentText: "often", PreviewText: "often", Hash: "smart-1",
+			CreatedAt: now.Add(-72 * time.Hour), LastAccessed: now.Add(-72 * time.Hour), UseCount: 12},
+		{ID: "fresh", ContentType: "text", ContentText: "new", PreviewText: "new", Hash: "smart-2",
+			CreatedAt: now, LastAccessed: now, UseCount: 1},
+		{ID: "pinned", ContentType: "text", ContentText: "pinned", PreviewText: "pinned", Hash: "smart-3",
+			CreatedAt: now.Add(-240 * time.Hour), LastAccessed: now.Add(-240 * time.Hour), IsPinned: true},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	results, err := db.GetClipboardItems(10, 0, "", SortSmart)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned", "favourite", "fresh"}, itemIDs(results))
+
+	// Recency-only ordering still puts the new item first
+	results, err = db.GetClipboardItems(10, 0, "", SortPasted)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned", "fresh", "favourite"}, itemIDs(results))
+}
+
+func TestImportItemsStrategies(t *testing.T) {
+	stored := func() *models.ClipboardItem {
+		return &models.ClipboardItem{ID: "stored", ContentType: "text", ContentText: "shared", Hash: "imp-1", Note: "mine"}
+	}
+	incoming := func() []models.ClipboardItem {
+		return []models.ClipboardItem{
+			{ID: "dup", ContentType: "text", ContentText: "shared", Hash: "imp-1", IsPinned: true, Tags: "work", Note: "theirs"},
+			{ID: "new", ContentType: "text", ContentText: "fresh", Hash: "imp-2"},
+		}
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		db := setupTestDB(t)
+		require.NoError(t, db.CreateClipboardItem(stored()))
+
+		count, err := db.ImportItems(incoming(), ImportSkip, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		item, err := db.GetClipboardItemByID("stored")
+		require.NoError(t, err)
+		assert.False(t, item.IsPinned)
+		assert.Equal(t, "mine", item.Note)
+
+		_, err = db.GetClipboardItemByID("dup")
+		assert.Error(t, err)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		db := setupTestDB(t)
+		require.NoError(t, db.CreateClipboardItem(stored()))
+
+		count, err := db.ImportItems(incoming(), ImportOverwrite, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		item, err := db.GetClipboardItemByID("stored")
+		require.NoError(t, err)
+		assert.True(t, item.IsPinned)
+		assert.Equal(t, "work", item.Tags)
+		assert.Equal(t, "theirs", item.Note)
+
+		_, err = db.GetClipboardItemByID("dup")
+		assert.Error(t, err)
+	})
+
+	t.Run("keep-both", func(t *testing.T) {
+		db := setupTestDB(t)
+		require.NoError(t, db.CreateClipboardItem(stored()))
+
+		count, err := db.ImportItems(incoming(), ImportKeepBoth, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		dup, err := db.GetClipboardItemByID("dup")
+		require.NoError(t, err)
+		assert.Equal(t, "shared", dup.ContentText)
+		assert.NotEqual(t, "imp-1", dup.Hash)
+
+		// Dedup still finds the item that was there first
+		byHash, err := db.GetItemByHash("imp-1")
+		require.NoError(t, err)
+		assert.Equal(t, "stored", byHash.ID)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		db := setupTestDB(t)
+		_, err := db.ImportItems(incoming(), "replace", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestPinSearchResults(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	for _, item := range []models.ClipboardItem{
+		{ID: "invoice-1", ContentType: "text", ContentText: "invoice 1", PreviewText: "invoice 1", Hash: "pin-search-1"},
+		{ID: "invoice-2", ContentType: "text", ContentText: "invoice 2", PreviewText: "invoice 2", Hash: "pin-search-2"},
+		{ID: "invoice-3", ContentType: "text", ContentText: "invoice 3", PreviewText: "invoice 3", Hash: "pin-search-3", IsPinned: true},
+		{ID: "receipt", ContentType: "text", ContentText: "receipt", PreviewText: "receipt", Hash: "pin-search-4"},
+	} {
+		require.NoError(t, db.CreateClipboardItem(&item))
+	}
+
+	pinnedIDs := func() []string {
+		var ids []string
+		require.NoError(t, db.DB.Model(&models.ClipboardItem{}).Where("is_pinned = ?", true).Order("id").Pluck("id", &ids).Error)
+		return ids
+	}
+
+	// Every match ends up pinned; the one already pinned isn't counted
+	affected, err := db.PinSearchResults("invoice", true, 10, SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, affected)
+	assert.Equal(t, []string{"invoice-1", "invoice-2", "invoice-3"}, pinnedIDs())
+
+	affected, err = db.PinSearchResults("invoice", false, 10, SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, affected)
+	assert.Empty(t, pinnedIDs())
+
+	affected, err = db.PinSearchResults("nothing matches", true, 10, SortCopied, false)
+	require.NoError(t, err)
+	assert.Zero(t, affected)
+
+	_, err = db.PinSearchResults("  ", true, 10, SortCopied, false)
+	assert.Error(t, err)
+	assert.Empty(t, pinnedIDs())
+}
+
+func TestTagItems(t *testing.T) {
+	db := setupTestDB(t)
+
+	items := []*models.ClipboardItem{
+		{ID: "a", ContentType: "text", ContentText: "a", Hash: "tag-1", Tags: "work"},
+		{ID: "b", ContentType: "text", ContentText: "b", Hash: "tag-2"},
+		{ID: "c", ContentType: "text", ContentText: "c", Hash: "tag-3", Tags: "home"},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	tags, err := db.GetAllTags()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home", "work"}, tags)
+
+	// Existing tags are kept and not repeated
+	require.NoError(t, db.TagItems([]string{"a", "b"}, []string{"sql", " work ", ""}))
+
+	a, err := db.GetClipboardItemByID("a")
+	require.NoError(t, err)
+	assert.Equal(t, "work,sql", a.Tags)
+
+	b, err := db.GetClipboardItemByID("b")
+	require.NoError(t, err)
+	assert.Equal(t, "sql,work", b.Tags)
+
+	c, err := db.GetClipboardItemByID("c")
+	require.NoError(t, err)
+	assert.Equal(t, "home", c.Tags)
+
+	tags, err = db.GetAllTags()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home", "sql", "work"}, tags)
+
+	// An unknown id rolls back the whole batch
+	err = db.TagItems([]string{"c", "missing"}, []string{"urgent"})
+	assert.Error(t, err)
+
+	c, err = db.GetClipboardItemByID("c")
+	require.NoError(t, err)
+	assert.Equal(t, "home", c.Tags)
+}
+
+func TestGetAllTagsEmpty(t *testing.T) {
+	db := setupTestDB(t)
+
+	tags, err := db.GetAllTags()
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+}
+
+func TestGetClipboardItemsAccessedOrder(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	items := []*models.ClipboardItem{
+		// Copied from history recently, not seen since
+		{ID: "accessed", ContentType: "text", ContentText: "a", Hash: "order-1",
+			CreatedAt: now.Add(-3 * time.Hour), LastAccessed: now.Add(-time.Hour), LastSeen: now.Add(-time.Hour)},
+		// Seen again on the clipboard just now, never copied from history
+		{ID: "seen", ContentType: "text", ContentText: "b", Hash: "order-2",
+			CreatedAt: now.Add(-2 * time.Hour), LastAccessed: now.Add(-2 * time.Hour), LastSeen: now},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	results, err := db.GetClipboardItems(10, 0, "", SortAccessed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"accessed", "seen"}, itemIDs(results))
+
+	// "pasted" goes by whichever happened last
+	results, err = db.GetClipboardItems(10, 0, "", SortPasted)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"seen", "accessed"}, itemIDs(results))
+}
+
+func TestImportItemsProgress(t *testing.T) {
+	db := setupTestDB(t)
+
+	count := importProgressInterval*2 + 10
+	items := make([]models.ClipboardItem, count)
+	for i := range items {
+		items[i] = models.ClipboardItem{
+			ID:          fmt.Sprintf("import-%04d", i),
+			ContentType: "text",
+			ContentText: fmt.Sprintf("imported %d", i),
+			Hash:        fmt.Sprintf("import-hash-%d", i),
+		}
+	}
+
+	var processed []int
+	imported, err := db.ImportItems(items, ImportSkip, func(done, total int) {
+		assert.Equal(t, count, total)
+		processed = append(processed, done)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, count, imported)
+	assert.Equal(t, []int{importProgressInterval, importProgressInterval * 2, count}, processed)
+}
+
+func TestReclassifyItemsBatches(t *testing.T) {
+	db := setupTestDB(t)
+
+	// More items than fit in one batch
+	total := reclassifyBatchSize + 25
+	for i := 0; i < total; i++ {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID:          fmt.Sprintf("item-%04d", i),
+			ContentType: "text",
+			ContentText: fmt.Sprintf("content %d", i),
+			Hash:        fmt.Sprintf("batch-hash-%d", i),
+		}))
+	}
+
+	seen := 0
+	var reports [][2]int
+	updated, err := db.ReclassifyItems(func(content string) Classification {
+		seen++
+		if content == "content 7" || content == fmt.Sprintf("content %d", total-1) {
+			return Classification{ContentType: "code", Language: "go"}
+		}
+		return Classification{ContentType: "text"}
+	}, func(processed, total int) {
+		reports = append(reports, [2]int{processed, total})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, total, seen)
+	assert.Equal(t, 2, updated)
+	assert.Equal(t, [][2]int{{reclassifyBatchSize, total}, {total, total}}, reports)
+
+	last, err := db.GetClipboardItemByID(fmt.Sprintf("item-%04d", total-1))
+	require.NoError(t, err)
+	assert.Equal(t, "code", last.ContentType)
+	assert.Equal(t, "go", last.Language)
+}
+
+func TestPromoteItem(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	for i, id := range []string{"old", "middle", "new"} {
+		at := now.Add(time.Duration(i-3) * time.Hour)
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID:           id,
+			ContentType:  "text",
+			ContentText:  id + " content",
+			Hash:         "promote-hash-" + id,
+			CreatedAt:    at,
+			LastAccessed: at,
+			IsPinned:     id == "middle",
+		}))
+	}
+
+	require.NoError(t, db.PromoteItem("old"))
+
+	for _, mode := range SortModes[:3] {
+		items, err := db.GetClipboardItems(10, 0, "", mode)
+		require.NoError(t, err)
+		// The pinned item stays first, the promoted one leads the rest
+		assert.Equal(t, []string{"middle", "old", "new"}, itemIDs(items), mode)
+	}
+
+	// Pinned items can be promoted too, and nothing else changes
+	require.NoError(t, db.PromoteItem("middle"))
+	promoted, err := db.GetClipboardItemByID("middle")
+	require.NoError(t, err)
+	assert.True(t, promoted.IsPinned)
+	assert.Equal(t, "middle content", promoted.ContentText)
+	assert.WithinDuration(t, time.Now(), promoted.CreatedAt, time.Minute)
+
+	var count int64
+	require.NoError(t, db.DB.Model(&models.ClipboardItem{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+
+	assert.ErrorIs(t, db.PromoteItem("missing"), gorm.ErrRecordNotFound)
+}
+
+func TestGetClipboardItemsAscending(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	items := []*models.ClipboardItem{
+		{ID: "newest", ContentType: "text", ContentText: "c", Hash: "asc-1", CreatedAt: now},
+		{ID: "oldest", ContentType: "text", ContentText: "a", Hash: "asc-2", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "middle", ContentType: "text", ContentText: "b", Hash: "asc-3", CreatedAt: now.Add(-time.Hour)},
+		{ID: "pinned", ContentType: "text", ContentText: "p", Hash: "asc-4", CreatedAt: now.Add(-30 * time.Minute), IsPinned: true},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	results, err := db.GetClipboardItemsOrdered(10, 0, "", SortCopied, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned", "oldest", "middle", "newest"}, itemIDs(results))
+
+	results, err = db.GetClipboardItemsOrdered(10, 0, "", SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned", "newest", "middle", "oldest"}, itemIDs(results))
+}
+
+func TestGetClipboardItemsPage(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	for i := range 5 {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: fmt.Sprintf("page-%d", i), ContentType: "text", ContentText: fmt.Sprint(i), Hash: fmt.Sprintf("page-hash-%d", i),
+			CreatedAt: now.Add(-time.Duration(i) * time.Minute),
+		}))
+	}
+
+	page, err := db.GetClipboardItemsPage(2, 0, "", SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"page-0", "page-1"}, itemIDs(page.Items))
+	assert.True(t, page.HasMore)
+
+	page, err = db.GetClipboardItemsPage(2, 2, "", SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"page-2", "page-3"}, itemIDs(page.Items))
+	assert.True(t, page.HasMore)
+
+	// The last page, partly filled
+	page, err = db.GetClipboardItemsPage(2, 4, "", SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"page-4"}, itemIDs(page.Items))
+	assert.False(t, page.HasMore)
+
+	// A page that ends exactly at the last item
+	page, err = db.GetClipboardItemsPage(5, 0, "", SortCopied, false)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 5)
+	assert.False(t, page.HasMore)
+}
+
+func TestReadsProceedWhileWriteInFlight(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "committed", ContentType: "text", ContentText: "committed", Hash: "committed",
+	}))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	writeDone := make(chan error, 1)
+	go func() {
+		writeDone <- db.write(func(tx *gorm.DB) error {
+			return tx.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Create(&models.ClipboardItem{
+					ID: "pending", ContentType: "text", ContentText: "pending", Hash: "pending",
+				}).Error; err != nil {
+					return err
+				}
+				close(started)
+				<-release
+				return nil
+			})
+		})
+	}()
+	<-started
+
+	// Several reads at once, none of which should wait for the write
+	results := make(chan []models.ClipboardItem, 3)
+	for range cap(results) {
+		go func() {
+			items, err := db.GetClipboardItems(10, 0, "", SortCopied)
+			assert.NoError(t, err)
+			results <- items
+		}()
+	}
+	for range cap(results) {
+		select {
+		case items := <-results:
+			require.Len(t, items, 1, "uncommitted write should not be visible")
+			assert.Equal(t, "committed", items[0].ID)
+		case <-time.After(2 * time.Second):
+			t.Fatal("read blocked behind an in-flight write")
+		}
+	}
+
+	close(release)
+	require.NoError(t, <-writeDone)
+
+	items, err := db.GetClipboardItems(10, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+}
+
+func TestConcurrentWritesAreSerialized(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("item-%d", i)
+			assert.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+				ID: id, ContentType: "text", ContentText: id, Hash: id,
+			}))
+			assert.NoError(t, db.PinClipboardItem(id, true))
+		}()
+	}
+	wg.Wait()
+
+	var pinned int64
+	require.NoError(t, db.DB.Model(&models.ClipboardItem{}).Where("is_pinned = ?", true).Count(&pinned).Error)
+	assert.Equal(t, int64(20), pinned)
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.Close())
+
+	err := db.CreateClipboardItem(&models.ClipboardItem{ID: "late", ContentText: "late", Hash: "late"})
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestWithContextDeadlineExceeded(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "ctx-1", ContentType: "text", ContentText: "still here", PreviewText: "still here", Hash: "ctx-hash-1",
+	}))
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err := db.WithContext(ctx).SearchAllFields("still", nil, 10, 0, SortCopied, false, false, false)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = db.WithContext(ctx).GetClipboardItems(10, 0, "", SortCopied)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The original handle is unaffected
+	items, err := db.SearchAllFields("still", nil, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestParseSortMode(t *testing.T) {
+	for _, want := range SortModes {
+		mode, err := ParseSortMode(string(want))
+		require.NoError(t, err, want)
+		assert.Equal(t, want, mode)
+	}
+
+	for _, s := range []string{"", "recent", "Copied", "copied "} {
+		_, err := ParseSortMode(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestQueriesRejectUnknownSortMode(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "item", ContentType: "text", ContentText: "item", PreviewText: "item", Hash: "item-hash",
+	}))
+
+	for _, mode := range []SortMode{"", "recent"} {
+		_, err := db.GetClipboardItems(10, 0, "", mode)
+		assert.Error(t, err, mode)
+		_, err = db.GetClipboardItemsAfter(models.Cursor{}, 10, "", mode, false)
+		assert.Error(t, err, mode)
+		_, _, err = db.GetAdjacentItems("item", mode, false)
+		assert.Error(t, err, mode)
+		_, err = db.SearchAllFields("item", nil, 10, 0, mode, false, false, false)
+		assert.Error(t, err, mode)
+		_, err = db.SearchClipboardItemsRegex("item", 10, 0, mode, false, false)
+		assert.Error(t, err, mode)
+	}
+}
+
+func TestCycleSortMode(t *testing.T) {
+	db := setupTestDB(t)
+
+	var modes []SortMode
+	for range SortModes {
+		mode, err := db.CycleSortMode()
+		require.NoError(t, err)
+		modes = append(modes, mode)
+	}
+	// Starts from the default "copied", so a full cycle ends back on it
+	assert.Equal(t, []SortMode{SortPasted, SortAccessed, SortSmart, SortCopied}, modes)
+
+	settings, err := db.GetSettings()
+	require.NoError(t, err)
+	assert.Equal(t, "copied", settings.SortByRecent)
+
+	// An unknown stored mode restarts at the first one
+	settings.SortByRecent = "recent"
+	require.NoError(t, db.UpdateSettings(settings))
+	mode, err := db.CycleSortMode()
+	require.NoError(t, err)
+	assert.Equal(t, SortModes[0], mode)
+}
+
 func TestClearAllClipboardItems(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -265,22 +1186,252 @@ func TestClearAllClipboardItems(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify only pinned item remains
-	allItems, err := db.GetClipboardItems(10, 0, "", "copied")
+	allItems, err := db.GetClipboardItems(10, 0, "", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, allItems, 1)
 	assert.Equal(t, "clear-2", allItems[0].ID)
 	assert.True(t, allItems[0].IsPinned)
 
 	// Clear all items including pinned
-	err = db.DB.Unscoped().Where("1 = 1").Delete(&models.ClipboardItem{}).Error
+	err = db.ClearAllItems(false)
 	assert.NoError(t, err)
 
 	// Verify no items remain
-	allItems, err = db.GetClipboardItems(10, 0, "", "copied")
+	allItems, err = db.GetClipboardItems(10, 0, "", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, allItems, 0)
 }
 
+func TestClearAllItemsWithoutPreservingPinned(t *testing.T) {
+	db := setupTestDB(t)
+
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "unpinned", ContentType: "text", ContentText: "a", Hash: "clear-all-1"}))
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "pinned", ContentType: "text", ContentText: "b", Hash: "clear-all-2", IsPinned: true}))
+
+	require.NoError(t, db.ClearAllItems(false))
+
+	var count int64
+	require.NoError(t, db.DB.Model(&models.ClipboardItem{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// Clearing an already empty table is fine too
+	assert.NoError(t, db.ClearAllItems(false))
+}
+
+func TestClearItemsInRange(t *testing.T) {
+	db := setupTestDB(t)
+
+	base := time.Date(2024, 3, 12, 0, 0, 0, 0, time.Local)
+	items := []*models.ClipboardItem{
+		{ID: "before", ContentType: "text", ContentText: "a", Hash: "range-1", CreatedAt: base.Add(-time.Hour)},
+		{ID: "start", ContentType: "text", ContentText: "b", Hash: "range-2", CreatedAt: base},
+		{ID: "inside", ContentType: "text", ContentText: "c", Hash: "range-3", CreatedAt: base.Add(12 * time.Hour)},
+		{ID: "pinned", ContentType: "text", ContentText: "d", Hash: "range-4", CreatedAt: base.Add(13 * time.Hour), IsPinned: true},
+		{ID: "end", ContentType: "text", ContentText: "e", Hash: "range-5", CreatedAt: base.Add(24 * time.Hour)},
+		{ID: "after", ContentType: "text", ContentText: "f", Hash: "range-6", CreatedAt: base.Add(48 * time.Hour)},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	require.NoError(t, db.ClearItemsInRange(base, base.Add(24*time.Hour), true))
+
+	remaining, err := db.GetClipboardItems(10, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"before", "pinned", "end", "after"}, itemIDs(remaining))
+
+	// Open-ended on the start, pinned items included this time
+	require.NoError(t, db.ClearItemsInRange(time.Time{}, base.Add(24*time.Hour), false))
+
+	remaining, err = db.GetClipboardItems(10, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"end", "after"}, itemIDs(remaining))
+
+	// Both bounds open clears everything
+	require.NoError(t, db.ClearItemsInRange(time.Time{}, time.Time{}, false))
+
+	remaining, err = db.GetClipboardItems(10, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+func TestClearItemsInRangeOtherZone(t *testing.T) {
+	// Bounds parsed in UTC still match local-time created_at values
+	local := time.Local
+	time.Local = time.FixedZone("UTC-7", -7*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	db := setupTestDB(t)
+
+	day := time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)
+	items := []*models.ClipboardItem{
+		{ID: "before", ContentType: "text", ContentText: "a", Hash: "zone-1", CreatedAt: day.Add(-time.Hour).Local()},
+		{ID: "inside", ContentType: "text", ContentText: "b", Hash: "zone-2", CreatedAt: day.Add(20 * time.Hour).Local()},
+		{ID: "after", ContentType: "text", ContentText: "c", Hash: "zone-3", CreatedAt: day.Add(25 * time.Hour).Local()},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	require.NoError(t, db.ClearItemsInRange(day, day.Add(24*time.Hour), false))
+
+	remaining, err := db.GetClipboardItems(10, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"before", "after"}, itemIDs(remaining))
+}
+
+func TestGetItemsSince(t *testing.T) {
+	db := setupTestDB(t)
+
+	boundary := time.Date(2024, 3, 12, 9, 0, 0, 0, time.Local)
+	items := []*models.ClipboardItem{
+		{ID: "old", ContentType: "text", ContentText: "a", Hash: "since-1", CreatedAt: boundary.Add(-time.Hour)},
+		{ID: "at-boundary", ContentType: "text", ContentText: "b", Hash: "since-2", CreatedAt: boundary},
+		{ID: "newer", ContentType: "text", ContentText: "c", Hash: "since-3", CreatedAt: boundary.Add(time.Minute)},
+		{ID: "newest", ContentType: "text", ContentText: "d", Hash: "since-4", CreatedAt: boundary.Add(time.Hour), IsPinned: true},
+		{ID: "newer-still", ContentType: "text", ContentText: "e", Hash: "since-5", CreatedAt: boundary.Add(30 * time.Minute)},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	since, err := db.GetItemsSince(boundary, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"newer", "newer-still", "newest"}, itemIDs(since))
+
+	// The same instant written in another zone gives the same answer
+	since, err = db.GetItemsSince(boundary.In(time.FixedZone("UTC+5", 5*60*60)), "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"newer", "newer-still", "newest"}, itemIDs(since))
+
+	since, err = db.GetItemsSince(boundary, "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"newer", "newer-still"}, itemIDs(since))
+
+	since, err = db.GetItemsSince(boundary.Add(2*time.Hour), "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, since)
+}
+
+func TestGetItemsSinceSameTimestamp(t *testing.T) {
+	db := setupTestDB(t)
+
+	at := time.Date(2024, 3, 12, 9, 0, 0, 0, time.Local)
+	for _, id := range []string{"tie-a", "tie-b", "tie-c", "tie-d"} {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: id, ContentType: "text", ContentText: id, Hash: "tie-hash-" + id, CreatedAt: at,
+		}))
+	}
+
+	// A batch ending inside a run of identical timestamps picks up where it
+	// stopped instead of skipping the rest of the run
+	first, err := db.GetItemsSince(at.Add(-time.Second), "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tie-a", "tie-b"}, itemIDs(first))
+
+	last := first[len(first)-1]
+	rest, err := db.GetItemsSince(last.CreatedAt, last.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tie-c", "tie-d"}, itemIDs(rest))
+}
+
+func TestHasItems(t *testing.T) {
+	db := setupTestDB(t)
+
+	hasItems, err := db.HasItems()
+	require.NoError(t, err)
+	assert.False(t, hasItems)
+
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "only", ContentType: "text", ContentText: "a", Hash: "has-1"}))
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "second", ContentType: "text", ContentText: "b", Hash: "has-2"}))
+	hasItems, err = db.HasItems()
+	require.NoError(t, err)
+	assert.True(t, hasItems)
+
+	require.NoError(t, db.DeleteClipboardItem("only"))
+	require.NoError(t, db.DeleteClipboardItem("second"))
+	hasItems, err = db.HasItems()
+	require.NoError(t, err)
+	assert.False(t, hasItems)
+}
+
+func TestClampLimit(t *testing.T) {
+	tests := []struct {
+		limit    int
+		expected int
+	}{
+		{0, DefaultLimit},
+		{-1, DefaultLimit},
+		{-500, DefaultLimit},
+		{1, 1},
+		{50, 50},
+		{MaxLimit, MaxLimit},
+		{MaxLimit + 1, MaxLimit},
+		{1 << 30, MaxLimit},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, clampLimit(tc.limit), "limit %d", tc.limit)
+	}
+}
+
+func TestListLimitsAreClamped(t *testing.T) {
+	db := setupTestDB(t)
+
+	base := time.Now().Add(-time.Hour)
+	items := make([]models.ClipboardItem, MaxLimit+10)
+	for i := range items {
+		text := fmt.Sprintf("limit item %d", i)
+		items[i] = models.ClipboardItem{
+			ID: fmt.Sprintf("limit-%04d", i), ContentType: "text", ContentText: text, PreviewText: text,
+			Hash: fmt.Sprintf("limit-hash-%d", i), CreatedAt: base.Add(time.Duration(i) * time.Second),
+		}
+	}
+	require.NoError(t, db.write(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(items, 200).Error
+	}))
+
+	for _, limit := range []int{0, -1} {
+		listed, err := db.GetClipboardItems(limit, 0, "", SortCopied)
+		require.NoError(t, err)
+		assert.Len(t, listed, DefaultLimit, "limit %d", limit)
+
+		found, err := db.SearchAllFields("limit item", nil, limit, 0, SortCopied, false, false, false)
+		require.NoError(t, err)
+		assert.Len(t, found, DefaultLimit, "limit %d", limit)
+
+		found, err = db.SearchClipboardItems("limit item", limit, 0, SortCopied, false)
+		require.NoError(t, err)
+		assert.Len(t, found, DefaultLimit, "limit %d", limit)
+
+		since, err := db.GetItemsSince(base.Add(-time.Minute), "", limit)
+		require.NoError(t, err)
+		assert.Len(t, since, DefaultLimit, "limit %d", limit)
+	}
+
+	listed, err := db.GetClipboardItems(MaxLimit*10, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, listed, MaxLimit)
+
+	found, err := db.SearchAllFields("limit item", nil, MaxLimit*10, 0, SortCopied, false, false, true)
+	require.NoError(t, err)
+	assert.Len(t, found, MaxLimit)
+
+	cursorPage, err := db.GetClipboardItemsAfter(models.Cursor{}, MaxLimit*10, "", SortCopied, false)
+	require.NoError(t, err)
+	assert.Len(t, cursorPage.Items, MaxLimit)
+
+	// A capped page still knows there is more beyond it
+	page, err := db.GetClipboardItemsPage(MaxLimit*10, 0, "", SortCopied, false)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, MaxLimit)
+	assert.True(t, page.HasMore)
+
+	page, err = db.GetClipboardItemsPage(MaxLimit*10, 20, "", SortCopied, false)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, MaxLimit-10)
+	assert.False(t, page.HasMore)
+}
+
 func TestClearClipboardItemsByType(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -301,7 +1452,7 @@ func TestClearClipboardItemsByType(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify results
-	allItems, err := db.GetClipboardItems(10, 0, "", "copied")
+	allItems, err := db.GetClipboardItems(10, 0, "", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, allItems, 2) // Should have image item and pinned text item
 
@@ -310,12 +1461,59 @@ func TestClearClipboardItemsByType(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify only image item remains
-	allItems, err = db.GetClipboardItems(10, 0, "", "copied")
+	allItems, err = db.GetClipboardItems(10, 0, "", SortCopied)
 	assert.NoError(t, err)
 	assert.Len(t, allItems, 1)
 	assert.Equal(t, "image", allItems[0].ContentType)
 }
 
+func TestTrimItemsPerApp(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	create := func(id string, app string, age time.Duration, pinned bool) {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID:          id,
+			ContentType: "text",
+			ContentText: id,
+			Hash:        "per-app-" + id,
+			SourceApp:   app,
+			CreatedAt:   now.Add(-age),
+			IsPinned:    pinned,
+		}))
+	}
+	for i := 0; i < 5; i++ {
+		create(fmt.Sprintf("term-%d", i), "Terminal", time.Duration(5-i)*time.Minute, false)
+	}
+	create("term-pinned", "Terminal", time.Hour, true)
+	create("notes-0", "Notes", 2*time.Hour, false)
+	create("notes-1", "Notes", time.Hour, false)
+	create("unknown-0", "", 3*time.Hour, false)
+	create("unknown-1", "", 3*time.Hour, false)
+	create("unknown-2", "", 3*time.Hour, false)
+
+	// Disabled
+	trimmed, err := db.TrimItemsPerApp(0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, trimmed)
+	items, err := db.GetClipboardItems(100, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 11)
+
+	trimmed, err = db.TrimItemsPerApp(2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, trimmed)
+	items, err = db.GetClipboardItems(100, 0, "", SortCopied)
+	require.NoError(t, err)
+	// Terminal keeps its newest two plus the pinned item; the others are
+	// at or under the cap, or have no known app
+	assert.ElementsMatch(t, []string{
+		"term-pinned", "term-3", "term-4",
+		"notes-0", "notes-1",
+		"unknown-0", "unknown-1", "unknown-2",
+	}, itemIDs(items))
+}
+
 func TestCleanupOldItems(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -375,11 +1573,12 @@ func TestCleanupOldItems(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Cleanup items older than 7 days
-	err = db.CleanupOldItems(100, 7) // Use 100 max items, 7 max days
+	removed, err := db.CleanupOldItems(100, 7) // Use 100 max items, 7 max days
 	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
 
 	// Verify results - old unpinned items should be removed
-	allItems, err := db.GetClipboardItems(10, 0, "", "copied")
+	allItems, err := db.GetClipboardItems(10, 0, "", SortCopied)
 	assert.NoError(t, err)
 
 	// Should have recent item and old pinned item (old unpinned item should be removed)
@@ -403,16 +1602,57 @@ func TestCleanupOldItems(t *testing.T) {
 	assert.False(t, foundOld, "Old unpinned item should be cleaned up")
 }
 
+func TestCleanupOldItemsZeroIsUnlimited(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: fmt.Sprintf("keep-%d", i), ContentType: "text", ContentText: fmt.Sprintf("item %d", i),
+			Hash: fmt.Sprintf("keep-hash-%d", i), CreatedAt: now.AddDate(0, 0, -30*i),
+		}))
+	}
+
+	// No count limit: only age applies, keeping the two within 45 days
+	deleted, err := db.CleanupOldItems(0, 45)
+	require.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+
+	for i := 5; i < 10; i++ {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: fmt.Sprintf("keep-%d", i), ContentType: "text", ContentText: fmt.Sprintf("item %d", i),
+			Hash: fmt.Sprintf("keep-hash-%d", i), CreatedAt: now.AddDate(-1, 0, -i),
+		}))
+	}
+
+	// No age limit: only count applies, however old the items are
+	deleted, err = db.CleanupOldItems(4, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+
+	// Both unlimited keeps everything
+	deleted, err = db.CleanupOldItems(0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+
+	items, err := db.GetClipboardItems(100, 0, "", SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 4)
+}
+
 func TestSettings(t *testing.T) {
 	db := setupTestDB(t)
 
 	// First get the default settings that were created during initialization
 	defaultSettings, err := db.GetSettings()
 	assert.NoError(t, err)
+	assert.Equal(t, "Cmd+Shift+K", defaultSettings.ShowWindowHotkey)
+	assert.Equal(t, "auto", defaultSettings.Theme)
 
 	// Update the existing settings
 	defaultSettings.GlobalHotkey = "Cmd+V"
 	defaultSettings.PreviousItemHotkey = "Cmd+Shift+V"
+	defaultSettings.ShowWindowHotkey = "Ctrl+Alt+K"
 	defaultSettings.PollingInterval = 1000
 	defaultSettings.MaxItems = 50
 	defaultSettings.MaxDays = 14
@@ -420,6 +1660,7 @@ func TestSettings(t *testing.T) {
 	defaultSettings.EnableSounds = true
 	defaultSettings.MonitoringEnabled = false
 	defaultSettings.AllowPasswords = true
+	defaultSettings.Theme = "dark"
 
 	err = db.UpdateSettings(defaultSettings)
 	assert.NoError(t, err)
@@ -428,9 +1669,11 @@ func TestSettings(t *testing.T) {
 	retrieved, err := db.GetSettings()
 	assert.NoError(t, err)
 	assert.Equal(t, "Cmd+V", retrieved.GlobalHotkey)
+	assert.Equal(t, "Ctrl+Alt+K", retrieved.ShowWindowHotkey)
 	assert.Equal(t, 1000, retrieved.PollingInterval)
 	assert.False(t, retrieved.AutoLaunch)
 	assert.True(t, retrieved.AllowPasswords)
+	assert.Equal(t, "dark", retrieved.Theme)
 
 	// Test updating settings again
 	defaultSettings.MaxItems = 200
@@ -447,12 +1690,155 @@ func TestSettings(t *testing.T) {
 	assert.False(t, retrieved.AllowPasswords)
 }
 
+func TestResetSettingsSection(t *testing.T) {
+	db := setupTestDB(t)
+
+	settings, err := db.GetSettings()
+	require.NoError(t, err)
+	settings.GlobalHotkey = "Ctrl+Alt+V"
+	settings.ForgetHotkey = "Cmd+Shift+X"
+	settings.MaxItems = 42
+	settings.Theme = "dark"
+	require.NoError(t, db.UpdateSettings(settings))
+
+	require.NoError(t, ResetSettingsSection(settings, "hotkeys"))
+	require.NoError(t, db.UpdateSettings(settings))
+
+	retrieved, err := db.GetSettings()
+	require.NoError(t, err)
+	assert.Equal(t, "Cmd+Shift+Space", retrieved.GlobalHotkey)
+	assert.Empty(t, retrieved.ForgetHotkey)
+	assert.Equal(t, 42, retrieved.MaxItems)
+	assert.Equal(t, "dark", retrieved.Theme)
+
+	require.NoError(t, ResetSettingsSection(retrieved, "cleanup"))
+	assert.Equal(t, 100, retrieved.MaxItems)
+	assert.Equal(t, "dark", retrieved.Theme)
+
+	assert.Error(t, ResetSettingsSection(retrieved, "everything"))
+}
+
+func TestDefaultSettingsMatchFreshInstall(t *testing.T) {
+	db := setupTestDB(t)
+
+	stored, err := db.GetSettings()
+	require.NoError(t, err)
+
+	// Every section reset on a fresh install is a no-op
+	for section := range settingsSections {
+		reset := *stored
+		require.NoError(t, ResetSettingsSection(&reset, section))
+		assert.Equal(t, *stored, reset, section)
+	}
+}
+
+func TestSettingsSchemaMigration(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := NewWithPath(dir, nil)
+	require.NoError(t, err)
+
+	settings, err := db.GetSettings()
+	require.NoError(t, err)
+	assert.Equal(t, settingsSchemaVersion, settings.SchemaVersion)
+
+	// Simulate a row written before versioning, with blanks where newer code
+	// expects defaults and a user choice that must survive
+	require.NoError(t, db.DB.Exec(`UPDATE settings SET schema_version = 0,
+		sort_by_recent = '', capture_mode = NULL, show_window_hotkey = '', global_hotkey = 'Ctrl+Alt+V'`).Error)
+	require.NoError(t, db.Close())
+
+	db, err = NewWithPath(dir, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	settings, err = db.GetSettings()
+	require.NoError(t, err)
+	assert.Equal(t, settingsSchemaVersion, settings.SchemaVersion)
+	assert.Equal(t, "copied", settings.SortByRecent)
+	assert.Equal(t, "all", settings.CaptureMode)
+	assert.Equal(t, "Cmd+Shift+K", settings.ShowWindowHotkey)
+	assert.Equal(t, "Ctrl+Alt+V", settings.GlobalHotkey)
+
+	// Saving from the frontend without a version keeps the row current
+	settings.SchemaVersion = 0
+	require.NoError(t, db.UpdateSettings(settings))
+	settings, err = db.GetSettings()
+	require.NoError(t, err)
+	assert.Equal(t, settingsSchemaVersion, settings.SchemaVersion)
+}
+
 func TestClose(t *testing.T) {
 	db := setupTestDB(t)
 
 	err := db.Close()
 	assert.NoError(t, err)
 
-	_, err = db.GetClipboardItems(10, 0, "", "copied")
+	_, err = db.GetClipboardItems(10, 0, "", SortCopied)
+	assert.Error(t, err)
+}
+
+func TestRecentSearches(t *testing.T) {
+	db := setupTestDB(t)
+
+	searches, err := db.GetRecentSearches()
+	require.NoError(t, err)
+	assert.Empty(t, searches)
+
+	for _, query := range []string{"alpha", "beta", "  ", "gamma", " alpha ", ""} {
+		require.NoError(t, db.AddRecentSearch(query))
+	}
+
+	// Most recent first; repeats move to the front and blanks are skipped
+	searches, err = db.GetRecentSearches()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "gamma", "beta"}, searches)
+}
+
+func TestRecentSearchesAreCapped(t *testing.T) {
+	db := setupTestDB(t)
+
+	total := MaxRecentSearches + 5
+	for i := 0; i < total; i++ {
+		require.NoError(t, db.AddRecentSearch(fmt.Sprintf("query %d", i)))
+	}
+
+	searches, err := db.GetRecentSearches()
+	require.NoError(t, err)
+	require.Len(t, searches, MaxRecentSearches)
+	assert.Equal(t, fmt.Sprintf("query %d", total-1), searches[0])
+	assert.Equal(t, "query 5", searches[MaxRecentSearches-1])
+
+	// The oldest are gone from the table, not just hidden by the limit
+	var stored int64
+	require.NoError(t, db.DB.Model(&models.RecentSearch{}).Count(&stored).Error)
+	assert.Equal(t, int64(MaxRecentSearches), stored)
+}
+
+func TestGetItemByHashPrefersPinned(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	items := []*models.ClipboardItem{
+		{ID: "pinned-old", ContentType: "text", ContentText: "same", Hash: "shared", IsPinned: true, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "unpinned-new", ContentType: "text", ContentText: "same", Hash: "shared", CreatedAt: now},
+		{ID: "unpinned-old", ContentType: "text", ContentText: "same", Hash: "shared", CreatedAt: now.Add(-time.Hour)},
+		{ID: "other", ContentType: "text", ContentText: "other", Hash: "different", CreatedAt: now},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	item, err := db.GetItemByHash("shared")
+	require.NoError(t, err)
+	assert.Equal(t, "pinned-old", item.ID)
+
+	// Without a pinned match the most recent wins
+	require.NoError(t, db.DeleteClipboardItem("pinned-old"))
+	item, err = db.GetItemByHash("shared")
+	require.NoError(t, err)
+	assert.Equal(t, "unpinned-new", item.ID)
+
+	_, err = db.GetItemByHash("missing")
 	assert.Error(t, err)
 }
@@ -53,7 +53,7 @@ func TestSearchClipboardItemsRegex(t *testing.T) {
 	// Test regex search for email pattern
 	// Note: This test may fail if SQLite doesn't have regex support compiled in
 	// In that case, we'll just verify the method exists and handles the query
-	results, err := db.SearchClipboardItemsRegex(`.*@.*\.com`, 10, 0, "copied")
+	results, err := db.SearchClipboardItemsRegex(`.*@.*\.com`, 10, 0, SortCopied, false, false)
 
 	// The test might fail with "no such function: REGEXP" if regex isn't available
 	// That's expected behavior for basic SQLite installations
@@ -140,7 +140,7 @@ func TestSearchClipboardItemsRegexPatterns(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			results, err := db.SearchClipboardItemsRegex(tc.pattern, 10, 0, "copied")
+			results, err := db.SearchClipboardItemsRegex(tc.pattern, 10, 0, SortCopied, false, false)
 
 			if err != nil && err.Error() == "no such function: REGEXP" {
 				t.Skip("SQLite REGEXP function not available - this is expected for basic installations")
@@ -198,7 +198,7 @@ func TestSearchClipboardItemsRegexOrdering(t *testing.T) {
 	}
 
 	// Search for email pattern - should return all 3, ordered by pinned first, then last_accessed DESC
-	results, err := db.SearchClipboardItemsRegex(`.*@.*\.com`, 10, 0, "copied")
+	results, err := db.SearchClipboardItemsRegex(`.*@.*\.com`, 10, 0, SortCopied, false, false)
 
 	if err != nil && err.Error() == "no such function: REGEXP" {
 		t.Skip("SQLite REGEXP function not available - this is expected for basic installations")
@@ -239,7 +239,7 @@ func TestSearchClipboardItemsRegexLimits(t *testing.T) {
 	}
 
 	// Test limit functionality
-	results, err := db.SearchClipboardItemsRegex(`test.*@example\.com`, 3, 0, "copied")
+	results, err := db.SearchClipboardItemsRegex(`test.*@example\.com`, 3, 0, SortCopied, false, false)
 
 	if err != nil && err.Error() == "no such function: REGEXP" {
 		t.Skip("SQLite REGEXP function not available - this is expected for basic installations")
@@ -249,8 +249,228 @@ func TestSearchClipboardItemsRegexLimits(t *testing.T) {
 		assert.Len(t, results, 3)
 
 		// Test with limit larger than available items
-		results, err = db.SearchClipboardItemsRegex(`test.*@example\.com`, 10, 0, "copied")
+		results, err = db.SearchClipboardItemsRegex(`test.*@example\.com`, 10, 0, SortCopied, false, false)
 		require.NoError(t, err)
 		assert.Len(t, results, 5)
 	}
 }
+
+func TestSearchAllFields(t *testing.T) {
+	db := setupTestDB(t)
+
+	items := []*models.ClipboardItem{
+		{ID: "preview", ContentType: "text", ContentText: "deploy script", PreviewText: "deploy script", Hash: "all-hash-1"},
+		{ID: "note", ContentType: "text", ContentText: "kubectl rollout", PreviewText: "kubectl rollout", Hash: "all-hash-2", Note: "restart the staging deploy"},
+		{ID: "tags", ContentType: "text", ContentText: "SELECT 1", PreviewText: "SELECT 1", Hash: "all-hash-3", Tags: "sql,reporting"},
+		{ID: "app", ContentType: "text", ContentText: "meeting notes", PreviewText: "meeting notes", Hash: "all-hash-4", SourceApp: "Slack"},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	// "deploy" appears in one preview and one note
+	results, err := db.SearchAllFields("deploy", nil, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"preview", "note"}, itemIDs(results))
+
+	// Matches that only exist in a tag or the source app
+	results, err = db.SearchAllFields("reporting", nil, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tags"}, itemIDs(results))
+
+	results, err = db.SearchAllFields("slack", nil, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app"}, itemIDs(results))
+
+	// Scoping to specific fields
+	results, err = db.SearchAllFields("deploy", []string{"note"}, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"note"}, itemIDs(results))
+
+	results, err = db.SearchAllFields("reporting", []string{"preview"}, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = db.SearchAllFields("deploy", []string{"content_binary"}, 10, 0, SortCopied, false, false, false)
+	assert.Error(t, err)
+}
+
+func TestSearchAscending(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	for i, id := range []string{"oldest", "middle", "newest"} {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: id, ContentType: "text", ContentText: "report " + id, PreviewText: "report " + id,
+			Hash: "asc-hash-" + id, CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	results, err := db.SearchAllFields("report", nil, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"newest", "middle", "oldest"}, itemIDs(results))
+
+	results, err = db.SearchAllFields("report", nil, 10, 0, SortCopied, true, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oldest", "middle", "newest"}, itemIDs(results))
+
+	results, err = db.SearchAllFields("report", nil, 2, 0, SortCopied, true, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oldest", "middle"}, itemIDs(results))
+
+	results, err = db.SearchClipboardItemsRegex("^report", 10, 0, SortCopied, true, false)
+	if err != nil && err.Error() == "no such function: REGEXP" {
+		t.Skip("SQLite REGEXP function not available - this is expected for basic installations")
+	}
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oldest", "middle", "newest"}, itemIDs(results))
+}
+
+func TestSearchPinnedOnly(t *testing.T) {
+	db := setupTestDB(t)
+
+	items := []*models.ClipboardItem{
+		{ID: "pinned-snippet", ContentType: "text", ContentText: "git rebase -i", PreviewText: "git rebase -i", Hash: "pinned-hash-1", IsPinned: true},
+		{ID: "pinned-note", ContentType: "text", ContentText: "ssh prod", PreviewText: "ssh prod", Hash: "pinned-hash-2", IsPinned: true, Note: "git host"},
+		{ID: "unpinned", ContentType: "text", ContentText: "git status", PreviewText: "git status", Hash: "pinned-hash-3"},
+		{ID: "pinned-other", ContentType: "text", ContentText: "docker ps", PreviewText: "docker ps", Hash: "pinned-hash-4", IsPinned: true},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	results, err := db.SearchAllFields("git", nil, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pinned-snippet", "pinned-note", "unpinned"}, itemIDs(results))
+
+	results, err = db.SearchAllFields("git", nil, 10, 0, SortCopied, false, true, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pinned-snippet", "pinned-note"}, itemIDs(results))
+
+	results, err = db.SearchClipboardItems("git", 10, 0, SortCopied, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned-snippet"}, itemIDs(results))
+
+	// An empty query lists the whole snippet library
+	results, err = db.SearchAllFields("", nil, 10, 0, SortCopied, false, true, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pinned-snippet", "pinned-note", "pinned-other"}, itemIDs(results))
+
+	results, err = db.SearchClipboardItemsRegex("^git ", 10, 0, SortCopied, false, true)
+	if err != nil && err.Error() == "no such function: REGEXP" {
+		t.Skip("SQLite REGEXP function not available - this is expected for basic installations")
+	}
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned-snippet"}, itemIDs(results))
+}
+
+func TestSearchTreatsWildcardsLiterally(t *testing.T) {
+	db := setupTestDB(t)
+
+	items := []*models.ClipboardItem{
+		{ID: "percent", ContentType: "text", ContentText: "50% off", PreviewText: "50% off", Hash: "like-hash-1"},
+		{ID: "digits", ContentType: "text", ContentText: "500 items", PreviewText: "500 items", Hash: "like-hash-2"},
+		{ID: "underscore", ContentType: "text", ContentText: "a_b", PreviewText: "a_b", Hash: "like-hash-3", Note: `C:\tmp`},
+		{ID: "letters", ContentType: "text", ContentText: "axb", PreviewText: "axb", Hash: "like-hash-4"},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	results, err := db.SearchClipboardItems("50%", 10, 0, SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"percent"}, itemIDs(results))
+
+	results, err = db.SearchClipboardItems("a_b", 10, 0, SortCopied, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"underscore"}, itemIDs(results))
+
+	results, err = db.SearchAllFields("50%", nil, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"percent"}, itemIDs(results))
+
+	results, err = db.SearchAllFields(`C:\tmp`, []string{"note"}, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"underscore"}, itemIDs(results))
+}
+
+func TestSearchWholeWord(t *testing.T) {
+	db := setupTestDB(t)
+
+	now := time.Now()
+	items := []*models.ClipboardItem{
+		{ID: "sentence", ContentType: "text", ContentText: "the cat sat", PreviewText: "the cat sat", Hash: "word-hash-1", CreatedAt: now},
+		{ID: "category", ContentType: "text", ContentText: "category", PreviewText: "category", Hash: "word-hash-2", CreatedAt: now.Add(-time.Minute)},
+		{ID: "punctuated", ContentType: "text", ContentText: "Cat, meet dog.", PreviewText: "Cat, meet dog.", Hash: "word-hash-3", CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "tagged", ContentType: "text", ContentText: "concat", PreviewText: "concat", Hash: "word-hash-4", Tags: "pets,cat", CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: "cpp", ContentType: "text", ContentText: "c++ and cats", PreviewText: "c++ and cats", Hash: "word-hash-5", CreatedAt: now.Add(-4 * time.Minute)},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	// Substring matching stays the default
+	results, err := db.SearchAllFields("cat", nil, 10, 0, SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Len(t, results, 5)
+
+	results, err = db.SearchAllFields("cat", nil, 10, 0, SortCopied, false, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sentence", "punctuated", "tagged"}, itemIDs(results))
+
+	results, err = db.SearchAllFields("cat", []string{"preview"}, 10, 0, SortCopied, false, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sentence", "punctuated"}, itemIDs(results))
+
+	// Paging applies to the whole-word matches
+	results, err = db.SearchAllFields("cat", nil, 1, 1, SortCopied, false, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"punctuated"}, itemIDs(results))
+
+	results, err = db.SearchAllFields("cat", nil, 10, 5, SortCopied, false, false, true)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Terms ending in punctuation still have a boundary
+	results, err = db.SearchAllFields("c++", nil, 10, 0, SortCopied, false, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cpp"}, itemIDs(results))
+}
+
+func TestSearchWholeWordAcrossBatches(t *testing.T) {
+	db := setupTestDB(t)
+
+	// Every other item is a whole-word match, so matches span several batches
+	now := time.Now()
+	count := wholeWordBatchSize * 3
+	for i := 0; i < count; i++ {
+		text := fmt.Sprintf("cat %03d", i)
+		if i%2 == 1 {
+			text = fmt.Sprintf("category %03d", i)
+		}
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: fmt.Sprintf("batch-%03d", i), ContentType: "text", ContentText: text, PreviewText: text,
+			Hash: fmt.Sprintf("batch-hash-%d", i), CreatedAt: now.Add(-time.Duration(i) * time.Second),
+		}))
+	}
+
+	results, err := db.SearchAllFields("cat", []string{"preview"}, 3, wholeWordBatchSize-1, SortCopied, false, false, true)
+	require.NoError(t, err)
+	first := (wholeWordBatchSize - 1) * 2
+	assert.Equal(t, []string{
+		fmt.Sprintf("batch-%03d", first), fmt.Sprintf("batch-%03d", first+2), fmt.Sprintf("batch-%03d", first+4),
+	}, itemIDs(results))
+	assert.Equal(t, fmt.Sprintf("cat %03d", first), results[0].ContentText)
+
+	results, err = db.SearchAllFields("cat", []string{"preview"}, 10, count/2-1, SortCopied, false, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprintf("batch-%03d", count-2)}, itemIDs(results))
+}
+
+func itemIDs(items []models.ClipboardItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
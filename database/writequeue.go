@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by writes attempted after Close
+var ErrClosed = errors.New("database is closed")
+
+type writeRequest struct {
+	fn   func() error
+	done chan error
+}
+
+// writeQueue runs writes one at a time on a single goroutine, so they never
+// contend with each other for SQLite's write lock while reads run alongside
+// on their own connections.
+type writeQueue struct {
+	requests chan writeRequest
+	wg       sync.WaitGroup
+
+	mu     sync.RWMutex // held for reading while enqueuing, for writing to close
+	closed bool
+}
+
+func newWriteQueue() *writeQueue {
+	q := &writeQueue{requests: make(chan writeRequest)}
+	q.wg.Add(1)
+	go q.run()
+	return q
+}
+
+func (q *writeQueue) run() {
+	defer q.wg.Done()
+	for req := range q.requests {
+		req.done <- req.fn()
+	}
+}
+
+// do queues fn and waits for its result. Giving up on ctx only applies while
+// waiting for a turn; once fn has started it runs to completion. fn must not
+// call do itself or it would wait on its own turn forever.
+func (q *writeQueue) do(ctx context.Context, fn func() error) error {
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return ErrClosed
+	}
+
+	req := writeRequest{fn: fn, done: make(chan error, 1)}
+	select {
+	case q.requests <- req:
+	case <-ctx.Done():
+		q.mu.RUnlock()
+		return ctx.Err()
+	}
+	q.mu.RUnlock()
+
+	return <-req.done
+}
+
+// close lets queued writes finish, then stops the writer goroutine. Later
+// calls to do return ErrClosed.
+func (q *writeQueue) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.requests)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
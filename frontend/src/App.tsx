@@ -27,6 +27,9 @@ function App() {
   const [isMonitoringPaused, setIsMonitoringPaused] = useState(false);
   const [settings, setSettings] = useState<models.Settings | null>(null);
   const [totalItemCount, setTotalItemCount] = useState(0);
+  const [highlightedItemId, setHighlightedItemId] = useState<string | null>(
+    null
+  );
 
   // Load initial data on component mount
   useEffect(() => {
@@ -44,10 +47,19 @@ function App() {
       setIsSearchVisible(false);
     });
 
-    // Listen for clipboard updates
+    // Open history with the given item selected
+    const showItemUnsubscribe = EventsOn(
+      "show-item-detail",
+      (itemId: string) => {
+        setHighlightedItemId(itemId);
+        setIsSearchVisible(true);
+      }
+    );
+
+    // Listen for clipboard updates; new items arrive coalesced
     const clipboardAddedUnsubscribe = EventsOn(
-      "clipboard-item-added",
-      (_newItem: any) => {
+      "items-changed",
+      (_change: any) => {
         loadClipboardItems();
         loadTotalItemCount(); // Update total count when new items are added
       }
@@ -61,11 +73,31 @@ function App() {
       }
     );
 
+    // The sort hotkey saves a new mode; pick it up and reorder the list
+    const sortModeUnsubscribe = EventsOn(
+      "sort-mode-changed",
+      (_mode: string) => {
+        loadSettings();
+        loadClipboardItems();
+      }
+    );
+
+    // Tell the backend whether we're frontmost so the toggle hotkey can
+    // hide the window instead of just re-showing it
+    const handleFocus = () => WailsApp.SetMainWindowFocused(true);
+    const handleBlur = () => WailsApp.SetMainWindowFocused(false);
+    window.addEventListener("focus", handleFocus);
+    window.addEventListener("blur", handleBlur);
+
     return () => {
+      window.removeEventListener("focus", handleFocus);
+      window.removeEventListener("blur", handleBlur);
       showSearchUnsubscribe();
       hideSearchUnsubscribe();
+      showItemUnsubscribe();
       clipboardAddedUnsubscribe();
       clipboardUpdatedUnsubscribe();
+      sortModeUnsubscribe();
     };
   }, []);
 
@@ -256,13 +288,13 @@ function App() {
         items = await WailsApp.SearchClipboardItemsRegex(query, limit);
       } else {
         // Use new paginated search function
-        const sortByRecent =
-          (settings?.sortByRecent as "copied" | "pasted") || "copied";
         items = await WailsApp.SearchClipboardItemsPaginated(
           query,
           limit,
           offset,
-          sortByRecent === "copied"
+          false,
+          false,
+          false
         );
       }
 
@@ -390,11 +422,15 @@ function App() {
         onItemPin={handleItemPin}
         onSearch={handleSearch}
         onLoadMore={handleLoadMore}
-        onClose={() => setIsSearchVisible(false)}
+        onClose={() => {
+          setIsSearchVisible(false);
+          setHighlightedItemId(null);
+        }}
         isVisible={isSearchVisible}
         sortByRecent={
           (settings?.sortByRecent as "copied" | "pasted") || "copied"
         }
+        highlightedItemId={highlightedItemId}
       />
 
       {/* Settings */}
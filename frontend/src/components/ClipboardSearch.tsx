@@ -26,6 +26,7 @@ interface ClipboardSearchProps {
   onLoadMore?: (limit: number, offset: number) => Promise<ClipboardItem[]>;
   isVisible: boolean;
   sortByRecent?: "copied" | "pasted";
+  highlightedItemId?: string | null;
 }
 
 const ClipboardSearch: React.FC<ClipboardSearchProps> = ({
@@ -38,6 +39,7 @@ const ClipboardSearch: React.FC<ClipboardSearchProps> = ({
   onLoadMore,
   isVisible,
   sortByRecent = "copied",
+  highlightedItemId = null,
 }) => {
   const [searchQuery, setSearchQuery] = useState("");
   const [selectedIndex, setSelectedIndex] = useState(0);
@@ -150,6 +152,17 @@ const ClipboardSearch: React.FC<ClipboardSearchProps> = ({
     setSelectedIndex(0);
   }, [searchQuery]);
 
+  // Select the item the backend asked us to show once it's in the list
+  useEffect(() => {
+    if (!isVisible || !highlightedItemId) return;
+    const index = searchResults.findIndex(
+      (item) => item.id === highlightedItemId
+    );
+    if (index >= 0) {
+      setSelectedIndex(index);
+    }
+  }, [isVisible, highlightedItemId, searchResults]);
+
   useEffect(() => {
     const handleKeyDown = (e: KeyboardEvent) => {
       if (!isVisible) return;
@@ -2,48 +2,148 @@
 // This file is automatically generated. DO NOT EDIT
 import {models} from '../models';
 
-export function ClearAllClipboardItems(arg1:boolean):Promise<void>;
+export function AddRecentSearch(arg1:string):Promise<void>;
+
+export function CaptureNow():Promise<models.ClipboardItem>;
 
 export function ClearClipboardItemsByType(arg1:string,arg2:boolean):Promise<void>;
 
+export function ClearItemsInRange(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+
+export function ConfirmClearAll(arg1:string,arg2:boolean):Promise<void>;
+
+export function CopyItemAsMarkdown(arg1:string):Promise<void>;
+
+export function CopyItemFormatted(arg1:string):Promise<void>;
+
+export function CopyItemOriginal(arg1:string):Promise<void>;
+
+export function CopyItemWithLineEnding(arg1:string,arg2:string):Promise<void>;
+
+export function CopyItemsJoined(arg1:Array<string>,arg2:string):Promise<void>;
+
+export function CycleSortMode():Promise<string>;
+
+export function DeleteAndReturnNext(arg1:string,arg2:string):Promise<models.ClipboardItem>;
+
 export function DeleteClipboardItem(arg1:string):Promise<void>;
 
+export function DiffItems(arg1:string,arg2:string):Promise<string>;
+
+export function ExportItemAsMarkdown(arg1:string):Promise<string>;
+
+export function FindCurrentClipboardInHistory():Promise<Array<models.ClipboardItem>>;
+
+export function ForgetCurrent():Promise<void>;
+
+export function GetAdjacentItems(arg1:string):Promise<models.AdjacentItems>;
+
+export function GetAllTags():Promise<Array<string>>;
+
+export function GetCaptureCountsByDay(arg1:number):Promise<Array<models.DayCount>>;
+
 export function GetClipboardItemByID(arg1:string):Promise<models.ClipboardItem>;
 
 export function GetClipboardItems(arg1:number,arg2:number,arg3:string):Promise<Array<models.ClipboardItem>>;
 
+export function GetClipboardItemsCursor(arg1:models.Cursor,arg2:number,arg3:string):Promise<models.ClipboardPage>;
+
 export function GetClipboardItemsPaginated(arg1:number,arg2:number,arg3:string):Promise<Array<models.ClipboardItem>>;
 
+export function GetClipboardItemsWithMeta(arg1:number,arg2:number,arg3:string):Promise<models.ItemsPage>;
+
+export function GetItemContent(arg1:string):Promise<string>;
+
+export function GetItemContentBytes(arg1:string):Promise<Array<number>>;
+
+export function GetItemsSince(arg1:string,arg2:string):Promise<Array<models.ClipboardItem>>;
+
 export function GetMonitoringStatus():Promise<Record<string, any>>;
 
 export function GetRecentItems(arg1:number):Promise<Array<models.ClipboardItem>>;
 
+export function GetRecentSearches():Promise<Array<string>>;
+
+export function GetRegisteredHotkeys():Promise<Array<string>>;
+
+export function GetSchemaVersion():Promise<number>;
+
 export function GetSettings():Promise<models.Settings>;
 
+export function GetStatistics():Promise<models.Statistics>;
+
+export function GetVersion():Promise<string>;
+
 export function HideSearchInterface():Promise<void>;
 
+export function ImportFromTextFile(arg1:string,arg2:string):Promise<number>;
+
+export function ImportItems(arg1:string,arg2:string):Promise<number>;
+
+export function IsHistoryEmpty():Promise<boolean>;
+
+export function IsItemOnClipboard(arg1:string):Promise<boolean>;
+
 export function IsMonitoringEnabled():Promise<boolean>;
 
+export function IsPersistenceDisabled():Promise<boolean>;
+
+export function PasteCyclePrevious():Promise<void>;
+
+export function PastePrevious():Promise<void>;
+
+export function PeekItem(arg1:string):Promise<models.ClipboardItem>;
+
 export function PinClipboardItem(arg1:string,arg2:boolean):Promise<void>;
 
+export function PinSearchResults(arg1:string,arg2:boolean,arg3:number):Promise<number>;
+
+export function PopAndPasteLast():Promise<models.ClipboardItem>;
+
+export function PromoteItem(arg1:string):Promise<void>;
+
 export function Quit():Promise<void>;
 
+export function ReclassifyItems():Promise<number>;
+
+export function RequestClearAll():Promise<string>;
+
+export function ResetSettingsSection(arg1:string):Promise<void>;
+
+export function RunCleanupNow():Promise<void>;
+
+export function SaveItemToFile(arg1:string,arg2:string):Promise<void>;
+
 export function SearchClipboardItems(arg1:string,arg2:number):Promise<Array<models.ClipboardItem>>;
 
-export function SearchClipboardItemsPaginated(arg1:string,arg2:number,arg3:number,arg4:boolean):Promise<Array<models.ClipboardItem>>;
+export function SearchClipboardItemsPaginated(arg1:string,arg2:number,arg3:number,arg4:boolean,arg5:boolean,arg6:boolean):Promise<Array<models.ClipboardItem>>;
 
 export function SearchClipboardItemsRegex(arg1:string,arg2:number):Promise<Array<models.ClipboardItem>>;
 
 export function SelectClipboardItem(arg1:string):Promise<void>;
 
+export function SetItemOneTime(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetMainWindowFocused(arg1:boolean):Promise<void>;
+
+export function ShowLatestItem():Promise<void>;
+
 export function ShowMainWindow():Promise<void>;
 
 export function ShowPreferences():Promise<void>;
 
 export function ShowSearchInterface():Promise<void>;
 
+export function TagItems(arg1:Array<string>,arg2:Array<string>):Promise<void>;
+
+export function ToggleMainWindow():Promise<void>;
+
 export function ToggleMonitoring():Promise<boolean>;
 
 export function TriggerGlobalHotkey():Promise<void>;
 
+export function UnregisterHotkey(arg1:string):Promise<void>;
+
 export function UpdateSettings(arg1:models.Settings):Promise<void>;
+
+export function WaitForNextCapture(arg1:number):Promise<models.ClipboardItem>;
@@ -2,18 +2,86 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function ClearAllClipboardItems(arg1) {
-  return window['go']['main']['App']['ClearAllClipboardItems'](arg1);
+export function AddRecentSearch(arg1) {
+  return window['go']['main']['App']['AddRecentSearch'](arg1);
+}
+
+export function CaptureNow() {
+  return window['go']['main']['App']['CaptureNow']();
 }
 
 export function ClearClipboardItemsByType(arg1, arg2) {
   return window['go']['main']['App']['ClearClipboardItemsByType'](arg1, arg2);
 }
 
+export function ClearItemsInRange(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ClearItemsInRange'](arg1, arg2, arg3);
+}
+
+export function ConfirmClearAll(arg1, arg2) {
+  return window['go']['main']['App']['ConfirmClearAll'](arg1, arg2);
+}
+
+export function CopyItemAsMarkdown(arg1) {
+  return window['go']['main']['App']['CopyItemAsMarkdown'](arg1);
+}
+
+export function CopyItemFormatted(arg1) {
+  return window['go']['main']['App']['CopyItemFormatted'](arg1);
+}
+
+export function CopyItemOriginal(arg1) {
+  return window['go']['main']['App']['CopyItemOriginal'](arg1);
+}
+
+export function CopyItemWithLineEnding(arg1, arg2) {
+  return window['go']['main']['App']['CopyItemWithLineEnding'](arg1, arg2);
+}
+
+export function CopyItemsJoined(arg1, arg2) {
+  return window['go']['main']['App']['CopyItemsJoined'](arg1, arg2);
+}
+
+export function CycleSortMode() {
+  return window['go']['main']['App']['CycleSortMode']();
+}
+
+export function DeleteAndReturnNext(arg1, arg2) {
+  return window['go']['main']['App']['DeleteAndReturnNext'](arg1, arg2);
+}
+
 export function DeleteClipboardItem(arg1) {
   return window['go']['main']['App']['DeleteClipboardItem'](arg1);
 }
 
+export function DiffItems(arg1, arg2) {
+  return window['go']['main']['App']['DiffItems'](arg1, arg2);
+}
+
+export function ExportItemAsMarkdown(arg1) {
+  return window['go']['main']['App']['ExportItemAsMarkdown'](arg1);
+}
+
+export function FindCurrentClipboardInHistory() {
+  return window['go']['main']['App']['FindCurrentClipboardInHistory']();
+}
+
+export function ForgetCurrent() {
+  return window['go']['main']['App']['ForgetCurrent']();
+}
+
+export function GetAdjacentItems(arg1) {
+  return window['go']['main']['App']['GetAdjacentItems'](arg1);
+}
+
+export function GetAllTags() {
+  return window['go']['main']['App']['GetAllTags']();
+}
+
+export function GetCaptureCountsByDay(arg1) {
+  return window['go']['main']['App']['GetCaptureCountsByDay'](arg1);
+}
+
 export function GetClipboardItemByID(arg1) {
   return window['go']['main']['App']['GetClipboardItemByID'](arg1);
 }
@@ -22,10 +90,30 @@ export function GetClipboardItems(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetClipboardItems'](arg1, arg2, arg3);
 }
 
+export function GetClipboardItemsCursor(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetClipboardItemsCursor'](arg1, arg2, arg3);
+}
+
 export function GetClipboardItemsPaginated(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetClipboardItemsPaginated'](arg1, arg2, arg3);
 }
 
+export function GetClipboardItemsWithMeta(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetClipboardItemsWithMeta'](arg1, arg2, arg3);
+}
+
+export function GetItemContent(arg1) {
+  return window['go']['main']['App']['GetItemContent'](arg1);
+}
+
+export function GetItemContentBytes(arg1) {
+  return window['go']['main']['App']['GetItemContentBytes'](arg1);
+}
+
+export function GetItemsSince(arg1, arg2) {
+  return window['go']['main']['App']['GetItemsSince'](arg1, arg2);
+}
+
 export function GetMonitoringStatus() {
   return window['go']['main']['App']['GetMonitoringStatus']();
 }
@@ -34,32 +122,116 @@ export function GetRecentItems(arg1) {
   return window['go']['main']['App']['GetRecentItems'](arg1);
 }
 
+export function GetRecentSearches() {
+  return window['go']['main']['App']['GetRecentSearches']();
+}
+
+export function GetRegisteredHotkeys() {
+  return window['go']['main']['App']['GetRegisteredHotkeys']();
+}
+
+export function GetSchemaVersion() {
+  return window['go']['main']['App']['GetSchemaVersion']();
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetStatistics() {
+  return window['go']['main']['App']['GetStatistics']();
+}
+
+export function GetVersion() {
+  return window['go']['main']['App']['GetVersion']();
+}
+
 export function HideSearchInterface() {
   return window['go']['main']['App']['HideSearchInterface']();
 }
 
+export function ImportFromTextFile(arg1, arg2) {
+  return window['go']['main']['App']['ImportFromTextFile'](arg1, arg2);
+}
+
+export function ImportItems(arg1, arg2) {
+  return window['go']['main']['App']['ImportItems'](arg1, arg2);
+}
+
+export function IsHistoryEmpty() {
+  return window['go']['main']['App']['IsHistoryEmpty']();
+}
+
+export function IsItemOnClipboard(arg1) {
+  return window['go']['main']['App']['IsItemOnClipboard'](arg1);
+}
+
 export function IsMonitoringEnabled() {
   return window['go']['main']['App']['IsMonitoringEnabled']();
 }
 
+export function IsPersistenceDisabled() {
+  return window['go']['main']['App']['IsPersistenceDisabled']();
+}
+
+export function PasteCyclePrevious() {
+  return window['go']['main']['App']['PasteCyclePrevious']();
+}
+
+export function PastePrevious() {
+  return window['go']['main']['App']['PastePrevious']();
+}
+
+export function PeekItem(arg1) {
+  return window['go']['main']['App']['PeekItem'](arg1);
+}
+
 export function PinClipboardItem(arg1, arg2) {
   return window['go']['main']['App']['PinClipboardItem'](arg1, arg2);
 }
 
+export function PinSearchResults(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PinSearchResults'](arg1, arg2, arg3);
+}
+
+export function PopAndPasteLast() {
+  return window['go']['main']['App']['PopAndPasteLast']();
+}
+
+export function PromoteItem(arg1) {
+  return window['go']['main']['App']['PromoteItem'](arg1);
+}
+
 export function Quit() {
   return window['go']['main']['App']['Quit']();
 }
 
+export function ReclassifyItems() {
+  return window['go']['main']['App']['ReclassifyItems']();
+}
+
+export function RequestClearAll() {
+  return window['go']['main']['App']['RequestClearAll']();
+}
+
+export function ResetSettingsSection(arg1) {
+  return window['go']['main']['App']['ResetSettingsSection'](arg1);
+}
+
+export function RunCleanupNow() {
+  return window['go']['main']['App']['RunCleanupNow']();
+}
+
+export function SaveItemToFile(arg1, arg2) {
+  return window['go']['main']['App']['SaveItemToFile'](arg1, arg2);
+}
+
 export function SearchClipboardItems(arg1, arg2) {
   return window['go']['main']['App']['SearchClipboardItems'](arg1, arg2);
 }
 
-export function SearchClipboardItemsPaginated(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['SearchClipboardItemsPaginated'](arg1, arg2, arg3, arg4);
+export function SearchClipboardItemsPaginated(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['SearchClipboardItemsPaginated'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
 export function SearchClipboardItemsRegex(arg1, arg2) {
@@ -70,6 +242,18 @@ export function SelectClipboardItem(arg1) {
   return window['go']['main']['App']['SelectClipboardItem'](arg1);
 }
 
+export function SetItemOneTime(arg1, arg2) {
+  return window['go']['main']['App']['SetItemOneTime'](arg1, arg2);
+}
+
+export function SetMainWindowFocused(arg1) {
+  return window['go']['main']['App']['SetMainWindowFocused'](arg1);
+}
+
+export function ShowLatestItem() {
+  return window['go']['main']['App']['ShowLatestItem']();
+}
+
 export function ShowMainWindow() {
   return window['go']['main']['App']['ShowMainWindow']();
 }
@@ -82,6 +266,14 @@ export function ShowSearchInterface() {
   return window['go']['main']['App']['ShowSearchInterface']();
 }
 
+export function TagItems(arg1, arg2) {
+  return window['go']['main']['App']['TagItems'](arg1, arg2);
+}
+
+export function ToggleMainWindow() {
+  return window['go']['main']['App']['ToggleMainWindow']();
+}
+
 export function ToggleMonitoring() {
   return window['go']['main']['App']['ToggleMonitoring']();
 }
@@ -90,6 +282,14 @@ export function TriggerGlobalHotkey() {
   return window['go']['main']['App']['TriggerGlobalHotkey']();
 }
 
+export function UnregisterHotkey(arg1) {
+  return window['go']['main']['App']['UnregisterHotkey'](arg1);
+}
+
 export function UpdateSettings(arg1) {
   return window['go']['main']['App']['UpdateSettings'](arg1);
 }
+
+export function WaitForNextCapture(arg1) {
+  return window['go']['main']['App']['WaitForNextCapture'](arg1);
+}
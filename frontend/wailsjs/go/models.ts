@@ -10,6 +10,27 @@ export namespace models {
 	    createdAt: any;
 	    // Go type: time
 	    lastAccessed: any;
+	    // Go type: time
+	    lastSeen: any;
+	    sizeBytes: number;
+	    note: string;
+	    tags: string;
+	    sourceApp: string;
+	    thumbnail?: number[];
+	    language: string;
+	    useCount: number;
+	    encrypted: boolean;
+	    sensitivity: number;
+	    compressed: boolean;
+	    colorHex: string;
+	    rows: number;
+	    cols: number;
+	    width: number;
+	    height: number;
+	    originalText?: string;
+	    fileSize: number;
+	    fileExists: boolean;
+	    isOneTime: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new ClipboardItem(source);
@@ -24,6 +45,154 @@ export namespace models {
 	        this.isPinned = source["isPinned"];
 	        this.createdAt = this.convertValues(source["createdAt"], null);
 	        this.lastAccessed = this.convertValues(source["lastAccessed"], null);
+	        this.lastSeen = this.convertValues(source["lastSeen"], null);
+	        this.sizeBytes = source["sizeBytes"];
+	        this.note = source["note"];
+	        this.tags = source["tags"];
+	        this.sourceApp = source["sourceApp"];
+	        this.thumbnail = source["thumbnail"];
+	        this.language = source["language"];
+	        this.useCount = source["useCount"];
+	        this.encrypted = source["encrypted"];
+	        this.sensitivity = source["sensitivity"];
+	        this.compressed = source["compressed"];
+	        this.colorHex = source["colorHex"];
+	        this.rows = source["rows"];
+	        this.cols = source["cols"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.originalText = source["originalText"];
+	        this.fileSize = source["fileSize"];
+	        this.fileExists = source["fileExists"];
+	        this.isOneTime = source["isOneTime"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AdjacentItems {
+	    prev?: ClipboardItem;
+	    next?: ClipboardItem;
+	
+	    static createFrom(source: any = {}) {
+	        return new AdjacentItems(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.prev = this.convertValues(source["prev"], ClipboardItem);
+	        this.next = this.convertValues(source["next"], ClipboardItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Cursor {
+	    isPinned: boolean;
+	    sortKey: number;
+	    id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Cursor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.isPinned = source["isPinned"];
+	        this.sortKey = source["sortKey"];
+	        this.id = source["id"];
+	    }
+	}
+	export class ClipboardPage {
+	    items: ClipboardItem[];
+	    next?: Cursor;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClipboardPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], ClipboardItem);
+	        this.next = this.convertValues(source["next"], Cursor);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class DayCount {
+	    date: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DayCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.count = source["count"];
+	    }
+	}
+	export class ItemsPage {
+	    items: ClipboardItem[];
+	    hasMore: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ItemsPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], ClipboardItem);
+	        this.hasMore = source["hasMore"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -48,14 +217,46 @@ export namespace models {
 	    id: number;
 	    globalHotkey: string;
 	    previousItemHotkey: string;
+	    showWindowHotkey: string;
+	    forgetHotkey: string;
+	    cycleSortHotkey: string;
+	    captureHotkey: string;
+	    stackPasteMode: boolean;
+	    previousHotkeyAction: string;
 	    pollingInterval: number;
 	    maxItems: number;
+	    maxItemsPerApp: number;
 	    maxDays: number;
 	    autoLaunch: boolean;
 	    enableSounds: boolean;
 	    monitoringEnabled: boolean;
 	    allowPasswords: boolean;
 	    sortByRecent: string;
+	    sortAscending: boolean;
+	    stripURLTracking: boolean;
+	    passwordEntropyThreshold: number;
+	    passwordSkipThreshold: number;
+	    respectSecureInput: boolean;
+	    captureExistingOnStart: boolean;
+	    dedupNormalization: string;
+	    minContentLength: number;
+	    dedupWindowMs: number;
+	    captureTransforms: string;
+	    normalizeText: boolean;
+	    logLevel: string;
+	    logContentPreviews: boolean;
+	    theme: string;
+	    compressLargeContent: boolean;
+	    skipWhitespaceOnlyChanges: boolean;
+	    promotePinnedOnRecopy: boolean;
+	    imageExtensions: string;
+	    captureAllowlist: string;
+	    ignoreSelfWrites: boolean;
+	    ignoreSignatures: string;
+	    captureMode: string;
+	    manualCaptureMode: boolean;
+	    maxImageDimension: number;
+	    schemaVersion: number;
 	    // Go type: time
 	    createdAt: any;
 	    // Go type: time
@@ -70,14 +271,46 @@ export namespace models {
 	        this.id = source["id"];
 	        this.globalHotkey = source["globalHotkey"];
 	        this.previousItemHotkey = source["previousItemHotkey"];
+	        this.showWindowHotkey = source["showWindowHotkey"];
+	        this.forgetHotkey = source["forgetHotkey"];
+	        this.cycleSortHotkey = source["cycleSortHotkey"];
+	        this.captureHotkey = source["captureHotkey"];
+	        this.stackPasteMode = source["stackPasteMode"];
+	        this.previousHotkeyAction = source["previousHotkeyAction"];
 	        this.pollingInterval = source["pollingInterval"];
 	        this.maxItems = source["maxItems"];
+	        this.maxItemsPerApp = source["maxItemsPerApp"];
 	        this.maxDays = source["maxDays"];
 	        this.autoLaunch = source["autoLaunch"];
 	        this.enableSounds = source["enableSounds"];
 	        this.monitoringEnabled = source["monitoringEnabled"];
 	        this.allowPasswords = source["allowPasswords"];
 	        this.sortByRecent = source["sortByRecent"];
+	        this.sortAscending = source["sortAscending"];
+	        this.stripURLTracking = source["stripURLTracking"];
+	        this.passwordEntropyThreshold = source["passwordEntropyThreshold"];
+	        this.passwordSkipThreshold = source["passwordSkipThreshold"];
+	        this.respectSecureInput = source["respectSecureInput"];
+	        this.captureExistingOnStart = source["captureExistingOnStart"];
+	        this.dedupNormalization = source["dedupNormalization"];
+	        this.minContentLength = source["minContentLength"];
+	        this.dedupWindowMs = source["dedupWindowMs"];
+	        this.captureTransforms = source["captureTransforms"];
+	        this.normalizeText = source["normalizeText"];
+	        this.logLevel = source["logLevel"];
+	        this.logContentPreviews = source["logContentPreviews"];
+	        this.theme = source["theme"];
+	        this.compressLargeContent = source["compressLargeContent"];
+	        this.skipWhitespaceOnlyChanges = source["skipWhitespaceOnlyChanges"];
+	        this.promotePinnedOnRecopy = source["promotePinnedOnRecopy"];
+	        this.imageExtensions = source["imageExtensions"];
+	        this.captureAllowlist = source["captureAllowlist"];
+	        this.ignoreSelfWrites = source["ignoreSelfWrites"];
+	        this.ignoreSignatures = source["ignoreSignatures"];
+	        this.captureMode = source["captureMode"];
+	        this.manualCaptureMode = source["manualCaptureMode"];
+	        this.maxImageDimension = source["maxImageDimension"];
+	        this.schemaVersion = source["schemaVersion"];
 	        this.createdAt = this.convertValues(source["createdAt"], null);
 	        this.updatedAt = this.convertValues(source["updatedAt"], null);
 	    }
@@ -100,6 +333,24 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class Statistics {
+	    totalItems: number;
+	    pinnedItems: number;
+	    totalSizeBytes: number;
+	    itemsByType: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Statistics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.totalItems = source["totalItems"];
+	        this.pinnedItems = source["pinnedItems"];
+	        this.totalSizeBytes = source["totalSizeBytes"];
+	        this.itemsByType = source["itemsByType"];
+	    }
+	}
 
 }
 
@@ -9,31 +9,84 @@ import (
 // clipboard history item
 type ClipboardItem struct {
 	ID            string    `gorm:"primaryKey" json:"id"`
-	ContentType   string    `gorm:"not null" json:"contentType"` // "text", "image", "file"
+	ContentType   string    `gorm:"not null" json:"contentType"` // "text", "image", "file", "json", "color"
 	ContentText   string    `json:"content"`                     // For text content
 	ContentBinary []byte    `json:"-"`                           // For binary content (images, etc.)
 	PreviewText   string    `json:"preview"`                     // Searchable preview text
 	IsPinned      bool      `gorm:"default:false" json:"isPinned"`
 	CreatedAt     time.Time `json:"createdAt"`
-	LastAccessed  time.Time `json:"lastAccessed"`
-	Hash          string    `gorm:"index" json:"-"` // For duplicate detection
+	LastAccessed  time.Time `json:"lastAccessed"`                    // Last copied back to the clipboard from history
+	LastSeen      time.Time `json:"lastSeen"`                        // Last observed on the clipboard, including as a duplicate
+	Hash          string    `gorm:"index" json:"-"`                  // For duplicate detection
+	DedupHash     string    `gorm:"index" json:"-"`                  // Hash of the content as normalized by DedupNormalization
+	SizeBytes     int       `json:"sizeBytes"`                       // len(ContentText) + len(ContentBinary)
+	Note          string    `json:"note"`                            // User-written annotation
+	Tags          string    `json:"tags"`                            // Comma-separated tag list
+	SourceApp     string    `json:"sourceApp"`                       // App the content was copied from, when known
+	Thumbnail     []byte    `json:"thumbnail,omitempty"`             // Small PNG preview for image items
+	Language      string    `json:"language"`                        // Syntax-highlighting hint, e.g. "json" or "xml"
+	UseCount      int       `gorm:"default:0" json:"useCount"`       // Times copied back from history
+	Encrypted     bool      `gorm:"default:false" json:"encrypted"`  // Content is encrypted at rest; preview is masked
+	Sensitivity   float64   `json:"sensitivity"`                     // Password confidence (0-1) for items stored as sensitive
+	Compressed    bool      `gorm:"default:false" json:"compressed"` // ContentBinary holds the gzipped text; ContentText is empty at rest
+	ColorHex      string    `json:"colorHex"`                        // Normalized "#rrggbb[aa]" for color items
+	Rows          int       `json:"rows"`                            // Row count of table items
+	Cols          int       `json:"cols"`                            // Column count of table items
+	Width         int       `json:"width"`                           // Pixel width of inline image items
+	Height        int       `json:"height"`                          // Pixel height of inline image items
+	OriginalText  string    `json:"originalText,omitempty"`          // Content as copied, when ContentText is a cleaned version
+	FileSize      int64     `json:"fileSize"`                        // Combined size of the files a file item points to, at capture time
+	FileExists    bool      `json:"fileExists"`                      // Every path of a file item existed at capture time
+	IsOneTime     bool      `gorm:"default:false" json:"isOneTime"`  // Deleted once it has been copied back
 }
 
 // Settings represents application configuration
 type Settings struct {
-	ID                 uint      `gorm:"primaryKey" json:"id"`
-	GlobalHotkey       string    `gorm:"default:'Cmd+Shift+Space'" json:"globalHotkey"`
-	PreviousItemHotkey string    `gorm:"default:'Cmd+Shift+C'" json:"previousItemHotkey"`
-	PollingInterval    int       `gorm:"default:500" json:"pollingInterval"` // milliseconds
-	MaxItems           int       `gorm:"default:100" json:"maxItems"`
-	MaxDays            int       `gorm:"default:7" json:"maxDays"`
-	AutoLaunch         bool      `gorm:"default:true" json:"autoLaunch"`
-	EnableSounds       bool      `gorm:"default:false" json:"enableSounds"`
-	MonitoringEnabled  bool      `gorm:"default:true" json:"monitoringEnabled"`
-	AllowPasswords     bool      `gorm:"default:false" json:"allowPasswords"`  // Allow copying password-like content
-	SortByRecent       string    `gorm:"default:'copied'" json:"sortByRecent"` // 'copied' or 'pasted' - secondary sort after pinned items
-	CreatedAt          time.Time `json:"createdAt"`
-	UpdatedAt          time.Time `json:"updatedAt"`
+	ID                        uint      `gorm:"primaryKey" json:"id"`
+	GlobalHotkey              string    `gorm:"default:'Cmd+Shift+Space'" json:"globalHotkey"` // Any hotkey may list comma-separated alternates
+	PreviousItemHotkey        string    `gorm:"default:'Cmd+Shift+C'" json:"previousItemHotkey"`
+	ShowWindowHotkey          string    `gorm:"default:'Cmd+Shift+K'" json:"showWindowHotkey"`
+	ForgetHotkey              string    `json:"forgetHotkey"`                               // Clears the clipboard and newest item; empty = unbound
+	CycleSortHotkey           string    `json:"cycleSortHotkey"`                            // Switches to the next sort mode; empty = unbound
+	CaptureHotkey             string    `json:"captureHotkey"`                              // Captures the clipboard on demand; empty = unbound
+	StackPasteMode            bool      `gorm:"default:false" json:"stackPasteMode"`        // Previous-item hotkey pops (pastes then deletes) the newest item
+	PreviousHotkeyAction      string    `gorm:"default:'copy'" json:"previousHotkeyAction"` // 'copy', 'paste' or 'show'
+	PollingInterval           int       `gorm:"default:500" json:"pollingInterval"`         // milliseconds
+	MaxItems                  int       `gorm:"default:100" json:"maxItems"`                // Unpinned items kept (0 = no limit)
+	MaxItemsPerApp            int       `gorm:"default:0" json:"maxItemsPerApp"`            // Unpinned items kept per source app (0 = no limit)
+	MaxDays                   int       `gorm:"default:7" json:"maxDays"`                   // Days unpinned items are kept (0 = forever)
+	AutoLaunch                bool      `gorm:"default:true" json:"autoLaunch"`
+	EnableSounds              bool      `gorm:"default:false" json:"enableSounds"`
+	MonitoringEnabled         bool      `gorm:"default:true" json:"monitoringEnabled"`
+	AllowPasswords            bool      `gorm:"default:false" json:"allowPasswords"`            // Allow copying password-like content
+	SortByRecent              string    `gorm:"default:'copied'" json:"sortByRecent"`           // 'copied', 'pasted', 'accessed' or 'smart' - secondary sort after pinned items
+	SortAscending             bool      `gorm:"default:false" json:"sortAscending"`             // List oldest first (pinned items stay on top)
+	StripURLTracking          bool      `gorm:"default:false" json:"stripURLTracking"`          // Remove tracking parameters from copied URLs
+	PasswordEntropyThreshold  float64   `gorm:"default:3.7" json:"passwordEntropyThreshold"`    // Bits per character for two-class tokens to count as passwords (0 = off)
+	PasswordSkipThreshold     float64   `gorm:"default:0.5" json:"passwordSkipThreshold"`       // Password confidence (0-1) at which content is skipped or encrypted
+	RespectSecureInput        bool      `gorm:"default:true" json:"respectSecureInput"`         // Skip capture while macOS secure input is active
+	CaptureExistingOnStart    bool      `gorm:"default:true" json:"captureExistingOnStart"`     // Save what is on the clipboard at launch
+	DedupNormalization        string    `gorm:"default:'exact'" json:"dedupNormalization"`      // 'exact', 'trim' or 'trim-lower' - what counts as a duplicate
+	MinContentLength          int       `gorm:"default:0" json:"minContentLength"`              // Skip content shorter than this many characters (0 = no minimum)
+	DedupWindowMs             int       `gorm:"default:1000" json:"dedupWindowMs"`              // Ignore identical content re-seen within this many ms (0 = off)
+	CaptureTransforms         string    `json:"captureTransforms"`                              // Comma-separated transforms applied on capture, in order
+	NormalizeText             bool      `gorm:"default:false" json:"normalizeText"`             // Straighten smart quotes and drop invisible characters on capture
+	LogLevel                  string    `gorm:"default:'info'" json:"logLevel"`                 // "debug", "info", "warn" or "error"
+	LogContentPreviews        bool      `gorm:"default:false" json:"logContentPreviews"`        // Include clipboard text previews in logs
+	Theme                     string    `gorm:"default:'auto'" json:"theme"`                    // 'auto', 'light' or 'dark'; auto follows the system appearance
+	CompressLargeContent      bool      `gorm:"default:false" json:"compressLargeContent"`      // Gzip large text items at rest
+	SkipWhitespaceOnlyChanges bool      `gorm:"default:false" json:"skipWhitespaceOnlyChanges"` // Ignore copies differing from the last capture only in whitespace
+	PromotePinnedOnRecopy     bool      `gorm:"default:false" json:"promotePinnedOnRecopy"`     // Re-copying a pinned item moves it up within the pinned group
+	ImageExtensions           string    `json:"imageExtensions"`                                // Comma-separated extensions treated as images; empty = built-in list
+	CaptureAllowlist          string    `json:"captureAllowlist"`                               // Newline-separated exact strings or /regex/ entries exempt from password detection
+	IgnoreSelfWrites          bool      `gorm:"default:true" json:"ignoreSelfWrites"`           // Don't recapture content Klipd recently wrote to the clipboard
+	IgnoreSignatures          string    `json:"ignoreSignatures"`                               // Newline-separated strings or /regex/ entries; matching content is never captured
+	CaptureMode               string    `gorm:"default:'all'" json:"captureMode"`               // 'all', 'multiline-only' or 'single-line-only'
+	ManualCaptureMode         bool      `gorm:"default:false" json:"manualCaptureMode"`         // No polling; items are only captured with CaptureNow
+	MaxImageDimension         int       `gorm:"default:0" json:"maxImageDimension"`             // Downscale inline images larger than this many pixels (0 = never)
+	SchemaVersion             int       `gorm:"default:0" json:"schemaVersion"`                 // Settings migrations applied; see database.settingsMigrations
+	CreatedAt                 time.Time `json:"createdAt"`
+	UpdatedAt                 time.Time `json:"updatedAt"`
 }
 
 func (c *ClipboardItem) BeforeCreate(tx *gorm.DB) error {
@@ -43,9 +96,119 @@ func (c *ClipboardItem) BeforeCreate(tx *gorm.DB) error {
 	if c.LastAccessed.IsZero() {
 		c.LastAccessed = time.Now()
 	}
+	if c.LastSeen.IsZero() {
+		c.LastSeen = c.LastAccessed
+	}
+	if c.DedupHash == "" {
+		c.DedupHash = c.Hash
+	}
+	if c.SizeBytes == 0 {
+		c.SizeBytes = c.ContentSize()
+	}
 	return nil
 }
 
+// ContentSize returns the number of bytes of stored content
+func (c *ClipboardItem) ContentSize() int {
+	return len(c.ContentText) + len(c.ContentBinary)
+}
+
+// Meta returns the item's CaptureMeta
+func (c *ClipboardItem) Meta() CaptureMeta {
+	return CaptureMeta{
+		ID:          c.ID,
+		ContentType: c.ContentType,
+		SizeBytes:   c.SizeBytes,
+		Width:       c.Width,
+		Height:      c.Height,
+	}
+}
+
+// RecentSearch is a query typed into the search box, kept to suggest it again
+type RecentSearch struct {
+	Query      string    `gorm:"primaryKey" json:"query"`
+	SearchedAt time.Time `gorm:"index" json:"searchedAt"`
+}
+
+// Statistics summarizes the stored clipboard history
+type Statistics struct {
+	TotalItems     int64            `json:"totalItems"`
+	PinnedItems    int64            `json:"pinnedItems"`
+	TotalSizeBytes int64            `json:"totalSizeBytes"`
+	ItemsByType    map[string]int64 `json:"itemsByType"`
+}
+
+// DayCount is how many items were captured on one local calendar day
+type DayCount struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Count int64  `json:"count"`
+}
+
+// Cursor marks the last item of a page for keyset pagination. The zero value
+// starts from the top.
+type Cursor struct {
+	IsPinned bool    `json:"isPinned"`
+	SortKey  float64 `json:"sortKey"`
+	ID       string  `json:"id"`
+}
+
+// ClipboardPage is one page of items and the cursor for the next, which is
+// nil once the end has been reached
+type ClipboardPage struct {
+	Items []ClipboardItem `json:"items"`
+	Next  *Cursor         `json:"next"`
+}
+
+// MonitorState is the payload of the "monitor-state" event sent whenever the
+// clipboard monitor starts, stops, pauses, resumes or hits an error
+type MonitorState struct {
+	State   string `json:"state"`           // "stopped", "running", "paused", "resumed" or "error"
+	Enabled bool   `json:"enabled"`         // Whether monitoring is enabled in settings
+	Running bool   `json:"running"`         // Whether the monitor has been started
+	Error   string `json:"error,omitempty"` // Why, in the error state
+}
+
+// ItemsPage is one offset-based page of items. HasMore says whether another
+// page follows, so infinite scroll knows when to stop without a count.
+type ItemsPage struct {
+	Items   []ClipboardItem `json:"items"`
+	HasMore bool            `json:"hasMore"`
+}
+
+// ItemsChanged is the payload of the coalesced "items-changed" event: how
+// many items were added since the last one and the newest of them. Only
+// metadata is sent; the frontend fetches content it wants to show.
+type ItemsChanged struct {
+	Count  int           `json:"count"`
+	Latest CaptureMeta   `json:"latest"`
+	Added  []CaptureMeta `json:"added"` // Every item added, oldest first
+}
+
+// CaptureMeta describes a newly captured item without its content, enough
+// for the frontend to lay out a placeholder before fetching the item or its
+// thumbnail
+type CaptureMeta struct {
+	ID          string `json:"id"`
+	ContentType string `json:"contentType"`
+	SizeBytes   int    `json:"sizeBytes"`
+	Width       int    `json:"width,omitempty"`  // Image items only
+	Height      int    `json:"height,omitempty"` // Image items only
+}
+
+// MaintenanceProgress is the payload of the "maintenance-progress" event sent
+// while a long operation over the history runs
+type MaintenanceProgress struct {
+	Operation string `json:"operation"`
+	Processed int    `json:"processed"`
+	Total     int    `json:"total"`
+}
+
+// AdjacentItems are the neighbours of an item in the list, nil at either end
+type AdjacentItems struct {
+	Prev *ClipboardItem `json:"prev"`
+	Next *ClipboardItem `json:"next"`
+}
+
 func (ClipboardItem) TableName() string {
 	return "clipboard_items"
 }
@@ -64,6 +64,7 @@ func TestClipboardItemBeforeCreate(t *testing.T) {
 	assert.False(t, item.LastAccessed.IsZero())
 	assert.WithinDuration(t, time.Now(), item.CreatedAt, time.Second)
 	assert.WithinDuration(t, time.Now(), item.LastAccessed, time.Second)
+	assert.Equal(t, len("test content"), item.SizeBytes)
 }
 
 func TestClipboardItemBeforeCreateWithExistingTimes(t *testing.T) {
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"klipd/models"
+)
+
+// captureNotifier lets any number of callers block until the next item is
+// captured. Each capture closes the channel the current waiters share and
+// starts a fresh one for later waiters.
+type captureNotifier struct {
+	mu   sync.Mutex
+	next *pendingCapture
+}
+
+// pendingCapture is filled in with an item just before done is closed
+type pendingCapture struct {
+	done chan struct{}
+	item *models.ClipboardItem
+}
+
+func newCaptureNotifier() *captureNotifier {
+	return &captureNotifier{next: &pendingCapture{done: make(chan struct{})}}
+}
+
+// notify wakes everyone waiting on the next capture with item
+func (cn *captureNotifier) notify(item *models.ClipboardItem) {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	cn.next.item = item
+	close(cn.next.done)
+	cn.next = &pendingCapture{done: make(chan struct{})}
+}
+
+// wait returns the first item captured after it is called, or an error if
+// none is captured within timeout
+func (cn *captureNotifier) wait(timeout time.Duration) (*models.ClipboardItem, error) {
+	cn.mu.Lock()
+	pending := cn.next
+	cn.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-pending.done:
+		return pending.item, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("no clipboard item captured within %v", timeout)
+	}
+}
+
+// WaitForNextCapture blocks until a new item is saved to the history and
+// returns it, or fails after timeout. Duplicates that only bump an existing
+// item don't count.
+func (cm *ClipboardMonitor) WaitForNextCapture(timeout time.Duration) (*models.ClipboardItem, error) {
+	return cm.captures.wait(timeout)
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForNextCapture(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+
+	const waiters = 3
+	var ready, done sync.WaitGroup
+	ready.Add(waiters)
+	done.Add(waiters)
+	results := make([]string, waiters)
+	for i := 0; i < waiters; i++ {
+		go func(i int) {
+			defer done.Done()
+			// Register before the capture happens
+			monitor.captures.mu.Lock()
+			pending := monitor.captures.next
+			monitor.captures.mu.Unlock()
+			ready.Done()
+
+			<-pending.done
+			results[i] = pending.item.ContentText
+		}(i)
+	}
+	ready.Wait()
+
+	monitor.readClipboard = func() (string, error) { return "captured for automation", nil }
+	monitor.checkClipboard()
+	done.Wait()
+
+	for _, content := range results {
+		assert.Equal(t, "captured for automation", content)
+	}
+}
+
+func TestWaitForNextCaptureReturnsNextItem(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		monitor.readClipboard = func() (string, error) { return "first", nil }
+		monitor.checkClipboard()
+		monitor.readClipboard = func() (string, error) { return "second", nil }
+		monitor.checkClipboard()
+	}()
+
+	item, err := monitor.WaitForNextCapture(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "first", item.ContentText)
+	assert.NotEmpty(t, item.ID)
+}
+
+func TestWaitForNextCaptureTimeout(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+
+	start := time.Now()
+	item, err := monitor.WaitForNextCapture(30 * time.Millisecond)
+	assert.Error(t, err)
+	assert.Nil(t, item)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// clearTokenTTL is how long a token from RequestClearAll stays valid
+const clearTokenTTL = 30 * time.Second
+
+// clearConfirmation hands out single-use tokens so wiping the history takes
+// two deliberate calls instead of one misfired event
+type clearConfirmation struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	token   string
+	expires time.Time
+}
+
+func newClearConfirmation(ttl time.Duration) *clearConfirmation {
+	return &clearConfirmation{ttl: ttl}
+}
+
+// issue returns a fresh token, invalidating any earlier one
+func (cc *clearConfirmation) issue() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.token = hex.EncodeToString(buf)
+	cc.expires = time.Now().Add(cc.ttl)
+	return cc.token, nil
+}
+
+// consume checks token against the outstanding one. A matching token is
+// used up even if it has expired, so it can never be replayed.
+func (cc *clearConfirmation) consume(token string) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.token == "" || token != cc.token {
+		return fmt.Errorf("invalid confirmation token")
+	}
+
+	cc.token = ""
+	if time.Now().After(cc.expires) {
+		return fmt.Errorf("confirmation token expired")
+	}
+	return nil
+}
@@ -2,10 +2,17 @@ package services
 
 import (
 	"context"
-	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"klipd/config"
@@ -15,30 +22,68 @@ import (
 	"github.com/atotto/clipboard"
 	"github.com/google/uuid"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gorm.io/gorm"
 )
 
 // handles clipboard monitoring and management
 type ClipboardMonitor struct {
-	db            *database.Database
-	config        *config.Config
-	lastHash      string
-	isRunning     bool
-	ctx           context.Context
-	cancel        context.CancelFunc
-	cleanupTicker *time.Ticker
-	wailsCtx      context.Context // Wails context for event emission
+	db             *database.Database
+	config         *config.Config
+	lastHash       string
+	lastCaptured   string // Content of the last capture that was saved or bumped, for SkipWhitespaceOnlyChanges
+	isRunning      bool
+	ctx            context.Context
+	cancel         context.CancelFunc
+	cleanupTicker  *time.Ticker
+	wailsCtx       context.Context // Wails context for event emission
+	readClipboard  func() (string, error)
+	writeClipboard func(string) error
+	writeImage     func([]byte) error   // Puts image data on the clipboard; see clipboardwrite.go
+	writeFiles     func([]string) error // Puts file references on the clipboard
+	secureInput    SecureInputDetector
+	sourceApp      SourceAppDetector
+	pasteCycle     *pasteCycle
+	recentHashes   map[string]time.Time // Last capture time per hash, for the dedup window
+	clearConfirm   *clearConfirmation
+	cipher         *itemCipher
+	emitEvent      func(name string, data interface{})
+	itemEvents     *itemEventCoalescer
+	captures       *captureNotifier
+	deleted        *expiringHashes
+	selfWrites     *expiringHashes
+	captureMu      sync.Mutex // Guards lastHash, lastCaptured and recentHashes
+	stateMu        sync.Mutex
+	state          string // Last announced monitor state; "" until started
 }
 
 func NewClipboardMonitor(db *database.Database, cfg *config.Config) *ClipboardMonitor {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &ClipboardMonitor{
-		db:       db,
-		config:   cfg,
-		ctx:      ctx,
-		cancel:   cancel,
-		wailsCtx: nil,
+	cm := &ClipboardMonitor{
+		db:             db,
+		config:         cfg,
+		ctx:            ctx,
+		cancel:         cancel,
+		wailsCtx:       nil,
+		readClipboard:  clipboard.ReadAll,
+		writeClipboard: clipboard.WriteAll,
+		writeImage:     writeClipboardImage,
+		writeFiles:     writeClipboardFiles,
+		secureInput:    systemSecureInput{},
+		sourceApp:      systemSourceApp{},
+		pasteCycle:     newPasteCycle(pasteCycleWindow),
+		recentHashes:   make(map[string]time.Time),
+		clearConfirm:   newClearConfirmation(clearTokenTTL),
+		cipher:         newItemCipher(systemKeychain{}),
+		captures:       newCaptureNotifier(),
+		deleted:        newExpiringHashes(deletedHashTTL),
+		selfWrites:     newExpiringHashes(selfWriteTTL),
 	}
+	cm.emitEvent = cm.emitToFrontend
+	cm.itemEvents = newItemEventCoalescer(ctx, func(event models.ItemsChanged) {
+		cm.emitEvent("items-changed", event)
+	})
+	return cm
 }
 
 // SetWailsContext sets the Wails context for event emission
@@ -46,17 +91,41 @@ func (cm *ClipboardMonitor) SetWailsContext(wailsCtx context.Context) {
 	cm.wailsCtx = wailsCtx
 }
 
+// emitToFrontend sends an event to the frontend once the Wails context is set
+func (cm *ClipboardMonitor) emitToFrontend(name string, data interface{}) {
+	if cm.wailsCtx != nil {
+		runtime.EventsEmit(cm.wailsCtx, name, data)
+	}
+}
+
 func (cm *ClipboardMonitor) Start() error {
 	if cm.isRunning {
 		return fmt.Errorf("clipboard monitor is already running")
 	}
 
 	cm.isRunning = true
-	log.Println("Starting clipboard monitor...")
+	slog.Info("Starting clipboard monitor")
+
+	cm.stateMu.Lock()
+	if cm.config.MonitoringEnabled {
+		cm.setState(MonitorRunning, nil)
+	} else {
+		cm.setState(MonitorPaused, nil)
+	}
+	cm.stateMu.Unlock()
 
-	// Get initial clipboard content to establish baseline
-	if initialContent, err := clipboard.ReadAll(); err == nil {
-		cm.lastHash = cm.generateHash(initialContent)
+	// Content already on the clipboard is either captured like any other copy
+	// or only taken as the baseline so the first poll doesn't pick it up.
+	// Starting paused only takes the baseline, and manual capture never
+	// polls, so neither applies there.
+	if cm.config.ManualCaptureMode {
+		slog.Info("Manual capture mode, not polling the clipboard")
+	} else if cm.config.CaptureExistingOnStart && cm.config.MonitoringEnabled {
+		cm.checkClipboard()
+	} else if initialContent, err := cm.readClipboard(); err == nil {
+		cm.captureMu.Lock()
+		cm.lastHash = contentHash(cm.config.ApplyCaptureTransforms(normalizeClipboardText(initialContent)))
+		cm.captureMu.Unlock()
 	}
 
 	// Start monitoring goroutine
@@ -74,10 +143,14 @@ func (cm *ClipboardMonitor) Stop() {
 		return
 	}
 
-	log.Println("Stopping clipboard monitor...")
+	slog.Info("Stopping clipboard monitor")
 	cm.isRunning = false
 	cm.cancel()
 
+	cm.stateMu.Lock()
+	cm.setState(MonitorStopped, nil)
+	cm.stateMu.Unlock()
+
 	if cm.cleanupTicker != nil {
 		cm.cleanupTicker.Stop()
 	}
@@ -87,8 +160,11 @@ func (cm *ClipboardMonitor) IsRunning() bool {
 	return cm.isRunning
 }
 
+// UpdateConfig switches to cfg, announcing a pause or resume if it changed
+// whether monitoring is enabled
 func (cm *ClipboardMonitor) UpdateConfig(cfg *config.Config) {
 	cm.config = cfg
+	cm.syncState()
 }
 
 // monitorClipboard is the main monitoring loop
@@ -101,7 +177,7 @@ func (cm *ClipboardMonitor) monitorClipboard() {
 		case <-cm.ctx.Done():
 			return
 		case <-ticker.C:
-			if cm.config.MonitoringEnabled {
+			if cm.config.MonitoringEnabled && !cm.config.ManualCaptureMode {
 				cm.checkClipboard()
 			}
 		}
@@ -110,90 +186,411 @@ func (cm *ClipboardMonitor) monitorClipboard() {
 
 // checkClipboard checks for clipboard changes and processes new content
 func (cm *ClipboardMonitor) checkClipboard() {
-	content, err := clipboard.ReadAll()
+	content, err := cm.readClipboard()
 
 	if err != nil {
+		cm.readFailed(err)
 		return
 	}
+	cm.readSucceeded()
+
+	cm.captureMu.Lock()
+	defer cm.captureMu.Unlock()
+	cm.capture(content, false)
+}
+
+// CaptureNow captures what's on the clipboard on request, whether or not
+// monitoring is enabled or polling at all. The content goes through the
+// usual filters and dedup against history, but none of the guards against
+// implicit recaptures: content matching the last capture, a just-deleted
+// item, Klipd's own writes and repeats inside the dedup window are all taken
+// again. Nil means it was filtered out.
+func (cm *ClipboardMonitor) CaptureNow() (*models.ClipboardItem, error) {
+	content, err := cm.readClipboard()
+	if err != nil {
+		return nil, err
+	}
+
+	// Runs from the hotkey, so it can land in the middle of a poll
+	cm.captureMu.Lock()
+	defer cm.captureMu.Unlock()
+	return cm.capture(content, true), nil
+}
+
+// capture runs content read from the clipboard through the capture
+// pipeline. It returns the item stored, or the existing one bumped for a
+// duplicate, and nil if the content was unchanged or filtered out. explicit
+// is set when the user asked for the capture, which skips the checks that
+// keep polling from picking the same content up again.
+// Callers must hold captureMu.
+func (cm *ClipboardMonitor) capture(content string, explicit bool) *models.ClipboardItem {
+	// Normalize before hashing so dedup sees the same text that gets stored
+	copied := normalizeClipboardText(content)
+	content = cm.config.ApplyCaptureTransforms(copied)
 
 	// Skip if content hasn't changed
-	currentHash := cm.generateHash(content)
-	if currentHash == cm.lastHash {
-		return
+	currentHash := contentHash(content)
+	if currentHash == cm.lastHash && !explicit {
+		return nil
 	}
 
 	cm.lastHash = currentHash
 
+	// An item deleted while its content is on the clipboard stays deleted
+	// until something else is copied. Any other content means the clipboard
+	// has moved on, and copying deleted content again later is a deliberate
+	// new capture.
+	if cm.deleted.contains(currentHash) && !explicit {
+		return nil
+	}
+	cm.deleted.clear()
+
+	// Content Klipd wrote coming back, e.g. from another clipboard manager
+	// restoring it, isn't a new copy
+	if cm.config.IgnoreSelfWrites && cm.selfWrites.contains(currentHash) && !explicit {
+		return nil
+	}
+
+	// Duplicates are matched on the configured normalization of the content,
+	// which for the default "exact" is just the content hash
+	_, dedupHash, _ := cm.itemHashes(content, false)
+
+	// Anything copied while secure input is on (e.g. a focused password field)
+	// is treated as sensitive. The hash is still recorded above so it isn't
+	// picked up once secure input ends.
+	if cm.config.RespectSecureInput && cm.secureInput.IsSecureInputActive() {
+		return nil
+	}
+
+	// Apps that rewrite the clipboard in bursts would otherwise keep bumping
+	// the same item, so ignore repeats inside the dedup window entirely.
+	// Explicit captures are still recorded, just not held back.
+	if cm.seenWithinDedupWindow(dedupHash) && !explicit {
+		return nil
+	}
+
+	// Shrink oversized inline images before the size check so they can still
+	// be kept. The hash stays that of the original so dedup keeps working; if
+	// shrinking fails the size check decides as before.
+	downscaledFrom := ""
+	hashed := content
+	if cm.config.MaxImageDimension > 0 && isBase64Image(content) {
+		scaled, original, resized, format, err := downscaleBase64Image(content, cm.config.MaxImageDimension)
+		if err != nil {
+			slog.Warn("Error downscaling image", "err", err)
+		} else if scaled != content {
+			content = scaled
+			downscaledFrom = fmt.Sprintf("Image (%s, %dx%d, downscaled to %dx%d)",
+				strings.ToUpper(format), original.Width, original.Height, resized.Width, resized.Height)
+		}
+	}
+
 	// Skip if content should be ignored
 	if cm.config.ShouldSkipContent(content) {
-		return
+		return nil
+	}
+
+	// Editors can put the same text back with only indentation or line
+	// endings changed, which isn't worth an entry of its own
+	if cm.config.SkipWhitespaceOnlyChanges && config.WhitespaceOnlyChange(cm.lastCaptured, content) {
+		return nil
+	}
+	cm.lastCaptured = content
+
+	// Password-like content only gets this far when passwords are allowed.
+	// It is stored encrypted, under keyed hashes.
+	sensitive := cm.config.IsSensitiveContent(content)
+	storedHash, storedDedupHash, err := cm.itemHashes(hashed, sensitive)
+	if err != nil {
+		slog.Warn("Skipping sensitive clipboard item, encryption unavailable", "err", err)
+		return nil
 	}
 
 	// Check for duplicate content
-	if existingItem, err := cm.db.GetItemByHash(currentHash); err == nil {
-		// Update last accessed time for existing item
-		existingItem.LastAccessed = time.Now()
+	if existingItem, err := cm.db.GetItemByDedupHash(storedDedupHash); err == nil {
+		// Seen again on the clipboard; LastAccessed is only for copies from history
+		now := time.Now()
+		existingItem.LastSeen = now
+		// A pinned item keeps its place among the pinned unless re-copying is
+		// set to refresh it, which bumps every sort key as PromoteItem does
+		if existingItem.IsPinned && cm.config.PromotePinnedOnRecopy {
+			existingItem.CreatedAt = now
+			existingItem.LastAccessed = now
+		}
 		if err := cm.db.UpdateClipboardItem(existingItem); err != nil {
-			log.Printf("Error updating existing clipboard item: %v", err)
+			slog.Error("Error updating existing clipboard item", "err", err)
 		} else {
 			// Emit event to frontend for real-time updates (item order may have changed)
-			if cm.wailsCtx != nil {
-				runtime.EventsEmit(cm.wailsCtx, "clipboard-item-updated", existingItem)
-			}
+			cm.emitEvent("clipboard-item-updated", existingItem)
 		}
-		return
+		return existingItem
 	}
 
 	// Create new clipboard item
+	class := cm.classify(content)
 	item := &models.ClipboardItem{
 		ID:           uuid.New().String(),
-		ContentType:  cm.detectContentType(content),
+		ContentType:  class.ContentType,
 		ContentText:  content,
 		PreviewText:  config.TruncatePreview(content, 200),
-		Hash:         currentHash,
+		Language:     class.Language,
+		ColorHex:     class.ColorHex,
+		Rows:         class.Rows,
+		Cols:         class.Cols,
+		Hash:         storedHash,
+		DedupHash:    storedDedupHash,
 		CreatedAt:    time.Now(),
 		LastAccessed: time.Now(),
+		LastSeen:     time.Now(),
 		IsPinned:     false,
+		SourceApp:    cm.sourceApp.FrontmostApp(),
+	}
+
+	// Lead a table's preview with its size, keeping the cells searchable
+	if item.ContentType == "table" {
+		item.PreviewText = fmt.Sprintf("Table (%d rows, %d columns)\n", item.Rows, item.Cols) + item.PreviewText
+	}
+
+	// Text with its smart quotes and invisible characters normalized away
+	// keeps the typographic version too
+	if cm.config.NormalizeText && config.NormalizeText(copied) != copied {
+		item.OriginalText = copied
+	}
+
+	// Keep tracking-free URLs for pasting, with the original for when it's needed
+	if cm.config.StripURLTracking && item.ContentType == "text" {
+		if cleaned := config.StripTrackingParams(content, cm.config.TrackingParams); cleaned != content {
+			if item.OriginalText == "" {
+				item.OriginalText = content
+			}
+			item.ContentText = cleaned
+			item.PreviewText = config.TruncatePreview(cleaned, 200)
+		}
+	}
+
+	// Record whether copied paths point at real files, for the UI to gray out
+	// missing ones; lookups that fail just leave the item marked missing
+	if item.ContentType == "file" || (item.ContentType == "image" && !isBase64Image(content) && cm.looksLikeFilePath(strings.TrimSpace(content))) {
+		item.FileSize, item.FileExists = statFiles(filePaths(content), fileStatTimeout)
 	}
 
 	// Handle binary content if needed
 	if item.ContentType == "image" {
-		// For now, we'll store image content as text (file paths, URLs, etc.)
-		// In the future, this could/will be enhanced to handle actual binary data
+		// Inline base64 images carry their own pixels; other image items are
+		// file paths or URLs and are stored as text only
 		item.ContentBinary = nil
+		if isBase64Image(content) {
+			cm.attachInlineImage(item, content)
+		}
+		if downscaledFrom != "" {
+			item.PreviewText = downscaledFrom
+		}
+	}
+	item.SizeBytes = item.ContentSize()
+
+	if err := cm.protectItem(item, content, sensitive); err != nil {
+		slog.Warn("Skipping sensitive clipboard item, encryption unavailable", "err", err)
+		return nil
 	}
 
 	// Save to database
 	if err := cm.db.CreateClipboardItem(item); err != nil {
-		log.Printf("Error saving clipboard item: %v", err)
-		return
+		slog.Error("Error saving clipboard item", "err", err)
+		return nil
 	}
 
-	log.Printf("New clipboard item saved: %s (type: %s)",
-		config.TruncatePreview(content, 50), item.ContentType)
+	// Content only reaches the logs when previews are switched on; logs end
+	// up in bug reports and system log collectors. PreviewText is masked for
+	// encrypted items either way.
+	slog.Debug("New clipboard item saved",
+		append([]any{"id", item.ID}, cm.config.ContentLogAttrs(item.ContentType, item.SizeBytes, item.PreviewText)...)...)
 
-	if cm.wailsCtx != nil {
-		runtime.EventsEmit(cm.wailsCtx, "clipboard-item-added", item)
+	// Rapid captures are announced together to keep the UI from reloading
+	// once per item
+	cm.itemEvents.add(item, cm.config.EventCoalesceWindow)
+	cm.captures.notify(item)
+	return item
+}
+
+// protectItem prepares a new item for storage. Sensitive content is kept off
+// disk in plaintext rather than stored unprotected, and fails the item if it
+// can't be encrypted; other large text is compressed when configured.
+func (cm *ClipboardMonitor) protectItem(item *models.ClipboardItem, content string, sensitive bool) error {
+	if sensitive {
+		item.OriginalText = "" // Would be stored in plaintext
+		item.Sensitivity = cm.config.PasswordConfidence(content)
+		return cm.cipher.encryptItem(item)
+	}
+
+	if cm.config.CompressLargeContent {
+		if err := compressItem(item); err != nil {
+			slog.Warn("Storing clipboard item uncompressed", "err", err)
+		}
+	}
+	return nil
+}
+
+// itemHashes returns the Hash and DedupHash to store for content. Sensitive
+// content gets keyed hashes, which need the encryption key.
+func (cm *ClipboardMonitor) itemHashes(content string, sensitive bool) (string, string, error) {
+	if !sensitive {
+		if hash, ok := imageHash(content); ok {
+			return hash, hash, nil
+		}
+		return config.GenerateHash(content), config.GenerateHash(cm.config.DedupKey(content)), nil
+	}
+
+	hash, err := cm.cipher.keyedHash(content)
+	if err != nil {
+		return "", "", err
+	}
+	dedupHash, err := cm.cipher.keyedHash(cm.config.DedupKey(content))
+	if err != nil {
+		return "", "", err
+	}
+	return hash, dedupHash, nil
+}
+
+// clipboardHashes returns the plain content hashes of item, as the capture
+// path computes them from the clipboard. Encrypted items only store keyed
+// hashes, so they are decrypted to recompute them.
+func (cm *ClipboardMonitor) clipboardHashes(item *models.ClipboardItem) (string, string, error) {
+	if !item.Encrypted {
+		return item.Hash, item.DedupHash, nil
+	}
+
+	plain := *item
+	if err := cm.cipher.decryptItem(&plain); err != nil {
+		return "", "", err
 	}
+	return cm.itemHashes(plain.ContentText, false)
+}
+
+// imageHash hashes an inline image by its decoded bytes, so the same picture
+// matches whatever data URI it arrives under. ok is false for anything else.
+func imageHash(content string) (hash string, ok bool) {
+	if !isBase64Image(content) {
+		return "", false
+	}
+	data, err := decodeBase64Image(content)
+	if err != nil {
+		return "", false
+	}
+	return config.HashContent("image", content, data), true
+}
+
+// contentHash is the plain hash items store for content
+func contentHash(content string) string {
+	if hash, ok := imageHash(content); ok {
+		return hash
+	}
+	return config.GenerateHash(content)
+}
+
+// seenWithinDedupWindow reports whether hash was captured less than the
+// configured dedup window ago, recording this capture otherwise
+func (cm *ClipboardMonitor) seenWithinDedupWindow(hash string) bool {
+	window := cm.config.DedupWindow
+	if window <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	for h, seen := range cm.recentHashes {
+		if now.Sub(seen) >= window {
+			delete(cm.recentHashes, h)
+		}
+	}
+
+	if _, ok := cm.recentHashes[hash]; ok {
+		return true
+	}
+
+	cm.recentHashes[hash] = now
+	return false
 }
 
 func (cm *ClipboardMonitor) detectContentType(content string) string {
+	contentType, _, _ := cm.detectContentShape(content)
+	return contentType
+}
+
+// detectContentShape is detectContentType along with the size of table
+// content, so callers that need both only parse the table once
+func (cm *ClipboardMonitor) detectContentShape(content string) (string, int, int) {
 	content = strings.TrimSpace(content)
 
+	// Check for a data URI or raw base64 image first, since base64 JPEGs
+	// start with "/" and would otherwise look like a path
+	if isBase64Image(content) {
+		return "image", 0, 0
+	}
+
+	if isJSONDocument(content) {
+		return "json", 0, 0
+	}
+
+	if detectColorHex(content) != "" {
+		return "color", 0, 0
+	}
+
 	if cm.looksLikeFilePath(content) {
-		if config.IsImageFormat(content) {
-			return "image"
+		if cm.config.IsImageFormat(content) {
+			return "image", 0, 0
 		}
-		return "file"
+		return "file", 0, 0
+	}
+
+	if rows, cols, ok := detectTable(content); ok {
+		return "table", rows, cols
 	}
 
 	// Check if it's a URL to an image
-	if cm.looksLikeURL(content) && config.IsImageFormat(content) {
-		return "image"
+	if cm.looksLikeURL(content) && cm.config.IsImageFormat(content) {
+		return "image", 0, 0
 	}
 
 	// Default to text
-	return "text"
+	return "text", 0, 0
+}
+
+// classify runs content detection for new and reclassified items
+func (cm *ClipboardMonitor) classify(content string) database.Classification {
+	contentType, rows, cols := cm.detectContentShape(content)
+	return database.Classification{
+		ContentType: contentType,
+		Language:    detectLanguage(content),
+		ColorHex:    detectColorHex(content),
+		Rows:        rows,
+		Cols:        cols,
+	}
+}
+
+// ReclassifyItems re-runs content detection over stored text items,
+// reporting to progress if it's set
+func (cm *ClipboardMonitor) ReclassifyItems(progress database.ProgressFunc) (int, error) {
+	return cm.db.ReclassifyItems(cm.classify, progress)
+}
+
+// attachInlineImage decodes a base64 image into the item's binary content and
+// generates its thumbnail. Failures leave the item as text-backed.
+func (cm *ClipboardMonitor) attachInlineImage(item *models.ClipboardItem, content string) {
+	data, err := decodeBase64Image(content)
+	if err != nil {
+		slog.Warn("Error decoding base64 image", "err", err)
+		return
+	}
+	item.ContentBinary = data
+
+	thumbnail, dims, format, err := makeThumbnail(data, thumbnailSize)
+	if err != nil {
+		slog.Warn("Error generating thumbnail", "err", err)
+		item.PreviewText = "Image"
+		return
+	}
+	item.Thumbnail = thumbnail
+	item.Width, item.Height = dims.Width, dims.Height
+	item.PreviewText = fmt.Sprintf("Image (%s, %dx%d)", strings.ToUpper(format), dims.Width, dims.Height)
 }
 
 func (cm *ClipboardMonitor) looksLikeFilePath(content string) bool {
@@ -210,11 +607,6 @@ func (cm *ClipboardMonitor) looksLikeURL(content string) bool {
 		strings.HasPrefix(content, "ftp://")
 }
 
-func (cm *ClipboardMonitor) generateHash(content string) string {
-	hash := sha256.Sum256([]byte(content))
-	return fmt.Sprintf("%x", hash)
-}
-
 func (cm *ClipboardMonitor) runCleanup() {
 	cm.cleanupTicker = time.NewTicker(cm.config.CleanupInterval())
 	defer cm.cleanupTicker.Stop()
@@ -229,72 +621,719 @@ func (cm *ClipboardMonitor) runCleanup() {
 	}
 }
 
-// performCleanup removes old clipboard items based on configuration
-func (cm *ClipboardMonitor) performCleanup() {
-	log.Println("Running clipboard cleanup...")
+// performCleanup removes old clipboard items based on configuration and
+// returns how many it removed
+func (cm *ClipboardMonitor) performCleanup() (int, error) {
+	slog.Debug("Running clipboard cleanup")
 
 	settings, err := cm.db.GetSettings()
 	if err != nil {
-		log.Printf("Error getting settings for cleanup: %v", err)
-		return
+		slog.Error("Error getting settings for cleanup", "err", err)
+		return 0, err
 	}
 
-	if err := cm.db.CleanupOldItems(settings.MaxItems, settings.MaxDays); err != nil {
-		log.Printf("Error during cleanup: %v", err)
-	} else {
-		log.Println("Clipboard cleanup completed")
+	removed, err := cm.db.CleanupOldItems(settings.MaxItems, settings.MaxDays)
+	if err != nil {
+		slog.Error("Error during cleanup", "err", err)
+		return removed, err
 	}
+	trimmed, err := cm.db.TrimItemsPerApp(settings.MaxItemsPerApp)
+	removed += trimmed
+	if err != nil {
+		slog.Error("Error trimming items per app", "err", err)
+		return removed, err
+	}
+
+	slog.Debug("Clipboard cleanup completed", "removed", removed)
+	return removed, nil
 }
 
-func (cm *ClipboardMonitor) GetRecentItems(limit int) ([]models.ClipboardItem, error) {
+// RunCleanupNow runs a cleanup with the current settings straight away
+// instead of waiting for the hourly one, e.g. after MaxItems is lowered. It
+// emits "cleanup-complete" with the number of items removed.
+func (cm *ClipboardMonitor) RunCleanupNow() (int, error) {
+	removed, err := cm.performCleanup()
+	if err != nil {
+		return removed, err
+	}
+	cm.emitEvent("cleanup-complete", removed)
+	return removed, nil
+}
+
+// sortMode returns the saved sort mode, or SortCopied when settings can't
+// be read
+func (cm *ClipboardMonitor) sortMode() (database.SortMode, error) {
 	settings, err := cm.db.GetSettings()
-	sortByRecent := "copied"
-	if err == nil {
-		sortByRecent = settings.SortByRecent
+	if err != nil {
+		return database.SortCopied, nil
 	}
-	return cm.db.GetClipboardItems(limit, 0, "", sortByRecent)
+	return database.ParseSortMode(settings.SortByRecent)
+}
+
+func (cm *ClipboardMonitor) GetRecentItems(limit int) ([]models.ClipboardItem, error) {
+	mode, err := cm.sortMode()
+	if err != nil {
+		return nil, err
+	}
+	return cm.db.GetClipboardItems(limit, 0, "", mode)
 }
 
 func (cm *ClipboardMonitor) SearchItems(query string, limit int) ([]models.ClipboardItem, error) {
-	settings, err := cm.db.GetSettings()
-	sortByRecent := "copied"
-	if err == nil {
-		sortByRecent = settings.SortByRecent
+	mode, err := cm.sortMode()
+	if err != nil {
+		return nil, err
 	}
-	return cm.db.SearchClipboardItems(query, limit, 0, sortByRecent)
+	return cm.db.SearchClipboardItems(query, limit, 0, mode, false)
 }
 
 func (cm *ClipboardMonitor) PinItem(id string, pinned bool) error {
 	return cm.db.PinClipboardItem(id, pinned)
 }
 
+// SetItemOneTime marks an item to be consumed by its next copy
+func (cm *ClipboardMonitor) SetItemOneTime(id string, oneTime bool) error {
+	return cm.db.SetItemOneTime(id, oneTime)
+}
+
+// PromoteItem floats an item to the top of the history without copying it
+func (cm *ClipboardMonitor) PromoteItem(id string) error {
+	return cm.db.PromoteItem(id)
+}
+
+// DeleteItem removes an item and keeps its content from being captured
+// again straight away if it is still on the clipboard
 func (cm *ClipboardMonitor) DeleteItem(id string) error {
-	return cm.db.DeleteClipboardItem(id)
+	item, err := cm.db.GetClipboardItemByID(id)
+	if err != nil {
+		return cm.db.DeleteClipboardItem(id)
+	}
+
+	if err := cm.db.DeleteClipboardItem(id); err != nil {
+		return err
+	}
+	if hash, _, err := cm.clipboardHashes(item); err == nil && cm.isOnClipboard(hash) {
+		cm.deleted.add(hash)
+	}
+	cm.announceIfEmpty()
+	return nil
+}
+
+// isOnClipboard reports whether content with hash is what the clipboard
+// holds, as of the last poll or Klipd's own last write to it
+func (cm *ClipboardMonitor) isOnClipboard(hash string) bool {
+	cm.captureMu.Lock()
+	defer cm.captureMu.Unlock()
+	return hash == cm.lastHash || cm.selfWrites.contains(hash)
+}
+
+// DeleteAndReturnNext deletes an item and returns the one that should be
+// selected in its place: the next in the list order for mode and
+// ascending, or the previous one if it was last. Nil means nothing is left.
+func (cm *ClipboardMonitor) DeleteAndReturnNext(id string, mode database.SortMode, ascending bool) (*models.ClipboardItem, error) {
+	prev, next, err := cm.db.GetAdjacentItems(id, mode, ascending)
+	if err != nil {
+		return nil, err
+	}
+	if err := cm.DeleteItem(id); err != nil {
+		return nil, err
+	}
+
+	if next != nil {
+		return next, nil
+	}
+	return prev, nil
+}
+
+// IsHistoryEmpty reports whether there are no items, e.g. to show new users
+// how to get started
+func (cm *ClipboardMonitor) IsHistoryEmpty() (bool, error) {
+	hasItems, err := cm.db.HasItems()
+	return !hasItems, err
 }
 
+// announceIfEmpty emits "history-empty" once removing items has left none
+func (cm *ClipboardMonitor) announceIfEmpty() {
+	if empty, err := cm.IsHistoryEmpty(); err != nil {
+		slog.Error("Error checking for empty history", "err", err)
+	} else if empty {
+		cm.emitEvent("history-empty", nil)
+	}
+}
+
+// GetItemByID returns an item with its content decrypted and decompressed
 func (cm *ClipboardMonitor) GetItemByID(id string) (*models.ClipboardItem, error) {
-	return cm.db.GetClipboardItemByID(id)
+	item, err := cm.db.GetClipboardItemByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := cm.cipher.decryptItem(item); err != nil {
+		return nil, err
+	}
+	if err := decompressItem(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// PeekItem returns an item for viewing without copying it: LastAccessed,
+// UseCount and the system clipboard are left alone. Image items stored
+// before thumbnails existed get one generated for the response.
+func (cm *ClipboardMonitor) PeekItem(id string) (*models.ClipboardItem, error) {
+	item, err := cm.GetItemByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.ContentType == "image" && len(item.Thumbnail) == 0 && len(item.ContentBinary) > 0 {
+		if thumbnail, _, _, err := makeThumbnail(item.ContentBinary, thumbnailSize); err == nil {
+			item.Thumbnail = thumbnail
+		}
+	}
+	return item, nil
+}
+
+// GetItemContent returns just the content of an item: its text, or a base64
+// data URL for images that carry their own bytes
+func (cm *ClipboardMonitor) GetItemContent(id string) (string, error) {
+	item, err := cm.GetItemByID(id)
+	if err != nil {
+		return "", err
+	}
+
+	if item.ContentType == "image" && len(item.ContentBinary) > 0 {
+		return "data:" + http.DetectContentType(item.ContentBinary) + ";base64," +
+			base64.StdEncoding.EncodeToString(item.ContentBinary), nil
+	}
+	return item.ContentText, nil
+}
+
+// GetItemContentBytes returns the binary content of an item, falling back to
+// its text for items without any
+func (cm *ClipboardMonitor) GetItemContentBytes(id string) ([]byte, error) {
+	item, err := cm.GetItemByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(item.ContentBinary) > 0 {
+		return item.ContentBinary, nil
+	}
+	return []byte(item.ContentText), nil
+}
+
+// languageExtensions maps detected languages to file extensions
+var languageExtensions = map[string]string{
+	"json": ".json",
+	"xml":  ".xml",
+}
+
+// binaryExtensions maps sniffed MIME types of binary content to extensions
+var binaryExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"image/bmp":  ".bmp",
+}
+
+// fileExtension picks an extension for saving item to disk
+func fileExtension(item *models.ClipboardItem) string {
+	if len(item.ContentBinary) > 0 {
+		if ext, ok := binaryExtensions[http.DetectContentType(item.ContentBinary)]; ok {
+			return ext
+		}
+		return ".bin"
+	}
+	if ext, ok := languageExtensions[item.Language]; ok {
+		return ext
+	}
+	if item.ContentType == "json" {
+		return ".json"
+	}
+	return ".txt"
+}
+
+// SaveItemToFile writes the content of an item to path. Binary content such
+// as images is written as-is, everything else as text. If path has no
+// extension, one matching the content is appended.
+func (cm *ClipboardMonitor) SaveItemToFile(id string, path string) error {
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("directory does not exist: %s", dir)
+	}
+
+	item, err := cm.GetItemByID(id)
+	if err != nil {
+		return err
+	}
+
+	if filepath.Ext(path) == "" {
+		path += fileExtension(item)
+	}
+
+	data := item.ContentBinary
+	if len(data) == 0 {
+		data = []byte(item.ContentText)
+	}
+
+	// Keep secrets readable by the owner only
+	perm := os.FileMode(0644)
+	if item.Encrypted {
+		perm = 0600
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// ImportItems reads a JSON array of items from path and adds them to the
+// history, resolving items whose content is already stored with strategy
+// (see database.ImportItems). Items get fresh IDs and hashes of their content,
+// and go through the same filters and encryption as captured content.
+// progress, if set, follows the database writes.
+func (cm *ClipboardMonitor) ImportItems(path string, strategy string, progress database.ProgressFunc) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var items []models.ClipboardItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return 0, fmt.Errorf("failed to parse import file: %w", err)
+	}
+
+	toImport := make([]models.ClipboardItem, 0, len(items))
+	for _, item := range items {
+		if item.ContentText == "" {
+			continue
+		}
+
+		content := item.ContentText
+		if cm.config.ShouldSkipContent(content) {
+			continue
+		}
+
+		sensitive := cm.config.IsSensitiveContent(content)
+		hash, dedupHash, err := cm.itemHashes(content, sensitive)
+		if err != nil {
+			slog.Warn("Skipping sensitive imported item, encryption unavailable", "err", err)
+			continue
+		}
+
+		item.ID = uuid.New().String()
+		item.Hash = hash
+		item.DedupHash = dedupHash
+		if item.ContentType == "" {
+			item.ContentType = cm.detectContentType(content)
+		}
+		if item.PreviewText == "" {
+			item.PreviewText = config.TruncatePreview(content, 200)
+		}
+
+		// Exported content is plaintext whatever it was stored as
+		item.Encrypted, item.Compressed = false, false
+		item.SizeBytes = item.ContentSize()
+		if err := cm.protectItem(&item, content, sensitive); err != nil {
+			slog.Warn("Skipping sensitive imported item, encryption unavailable", "err", err)
+			continue
+		}
+		toImport = append(toImport, item)
+	}
+
+	return cm.db.ImportItems(toImport, strategy, progress)
+}
+
+// plainContent returns the text content of item, decrypting or decompressing
+// a copy if needed so item itself can still be saved
+func (cm *ClipboardMonitor) plainContent(item *models.ClipboardItem) (string, error) {
+	plain, err := cm.plainItem(item)
+	if err != nil {
+		return "", err
+	}
+	return plain.ContentText, nil
+}
+
+// plainItem is plainContent returning the whole decoded copy, binary
+// content included
+func (cm *ClipboardMonitor) plainItem(item *models.ClipboardItem) (*models.ClipboardItem, error) {
+	plain := *item
+	if err := cm.cipher.decryptItem(&plain); err != nil {
+		return nil, err
+	}
+	if err := decompressItem(&plain); err != nil {
+		return nil, err
+	}
+	return &plain, nil
 }
 
 func (cm *ClipboardMonitor) CopyItemToClipboard(id string) error {
+	return cm.copyItem(id, false)
+}
+
+// CopyItemOriginal copies an item's content exactly as it was captured,
+// e.g. a URL with its tracking parameters still in place
+func (cm *ClipboardMonitor) CopyItemOriginal(id string) error {
+	return cm.copyItem(id, true)
+}
+
+func (cm *ClipboardMonitor) copyItem(id string, original bool) error {
 	item, err := cm.db.GetClipboardItemByID(id)
 	if err != nil {
 		return err
 	}
 
+	plain, err := cm.plainItem(item)
+	if err != nil {
+		return err
+	}
+	content := plain.ContentText
+	if original && item.OriginalText != "" {
+		content = item.OriginalText
+	}
+
 	item.LastAccessed = time.Now()
+	item.UseCount++
 	if err := cm.db.UpdateClipboardItem(item); err != nil {
-		log.Printf("Error updating last accessed time: %v", err)
+		slog.Error("Error updating last accessed time", "err", err)
+	}
+
+	// A cleaned URL hashes differently from the stored original, so mark it
+	// as seen rather than capture it as a new item on the next poll
+	if content != item.OriginalText && item.OriginalText != "" {
+		cm.captureMu.Lock()
+		cm.lastHash = contentHash(content)
+		cm.captureMu.Unlock()
 	}
 
 	// Copy to clipboard
-	return clipboard.WriteAll(item.ContentText)
+	cm.selfWrites.replace(contentHash(cm.config.ApplyCaptureTransforms(content)))
+	if err := cm.writeItem(plain.ContentType, content, plain.ContentBinary); err != nil {
+		return err
+	}
+
+	if item.IsOneTime {
+		cm.consume(item)
+	}
+	return nil
+}
+
+// consume deletes a one-time item after it has been copied. Pinned items are
+// kept, since pinning says the item should stay put.
+func (cm *ClipboardMonitor) consume(item *models.ClipboardItem) {
+	if item.IsPinned {
+		slog.Warn("Not deleting pinned one-time item", "id", item.ID)
+		return
+	}
+	if err := cm.DeleteItem(item.ID); err != nil {
+		slog.Error("Error deleting one-time item", "err", err)
+		return
+	}
+	cm.emitEvent("item-consumed", item.ID)
+}
+
+// writeOwn puts text Klipd produced itself on the clipboard, marked as
+// already seen so it doesn't become a history entry on the next poll
+func (cm *ClipboardMonitor) writeOwn(content string) error {
+	hash := contentHash(cm.config.ApplyCaptureTransforms(content))
+	cm.captureMu.Lock()
+	cm.lastHash = hash
+	cm.captureMu.Unlock()
+	cm.selfWrites.replace(hash)
+	return cm.writeClipboard(content)
+}
+
+// CopyItemFormatted writes a pretty-printed copy of a JSON or XML item to the
+// clipboard. The stored item is left untouched.
+func (cm *ClipboardMonitor) CopyItemFormatted(id string) error {
+	item, err := cm.db.GetClipboardItemByID(id)
+	if err != nil {
+		return err
+	}
+
+	content, err := cm.plainContent(item)
+	if err != nil {
+		return err
+	}
+
+	formatted, err := formatContent(content)
+	if err != nil {
+		return err
+	}
+
+	item.LastAccessed = time.Now()
+	item.UseCount++
+	if err := cm.db.UpdateClipboardItem(item); err != nil {
+		slog.Error("Error updating last accessed time", "err", err)
+	}
+
+	// Treat the formatted text as already seen so it isn't captured as a
+	// separate history entry on the next poll
+	return cm.writeOwn(formatted)
+}
+
+// CopyItemWithLineEnding writes an item's text to the clipboard with every
+// line ending converted to style ("lf", "crlf" or "cr"), for pasting into
+// tools on another platform. The stored item is left untouched.
+func (cm *ClipboardMonitor) CopyItemWithLineEnding(id string, style string) error {
+	item, err := cm.db.GetClipboardItemByID(id)
+	if err != nil {
+		return err
+	}
+
+	content, err := cm.plainContent(item)
+	if err != nil {
+		return err
+	}
+
+	converted, err := config.ConvertLineEndings(content, style)
+	if err != nil {
+		return err
+	}
+
+	item.LastAccessed = time.Now()
+	item.UseCount++
+	if err := cm.db.UpdateClipboardItem(item); err != nil {
+		slog.Error("Error updating last accessed time", "err", err)
+	}
+
+	// Like a formatted copy, the converted text isn't a new history entry
+	return cm.writeOwn(converted)
 }
 
-func (cm *ClipboardMonitor) ClearAll(preservePinned bool) error {
-	return cm.db.ClearAllItems(preservePinned)
+// CopyItemsJoined writes the content of the items with ids, in that order and
+// separated by separator, to the clipboard. Unlike merging, nothing is stored:
+// the joined text is treated as already seen so it isn't captured either.
+// Every item must exist and hold text; images and files are rejected.
+func (cm *ClipboardMonitor) CopyItemsJoined(ids []string, separator string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("no items to copy")
+	}
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		item, err := cm.db.GetClipboardItemByID(id)
+		if err != nil {
+			return fmt.Errorf("item %s: %w", id, err)
+		}
+		if item.ContentType == "image" || item.ContentType == "file" || (len(item.ContentBinary) > 0 && !item.Compressed) {
+			return fmt.Errorf("item %s is not text", id)
+		}
+
+		content, err := cm.plainContent(item)
+		if err != nil {
+			return fmt.Errorf("item %s: %w", id, err)
+		}
+		parts = append(parts, content)
+	}
+
+	return cm.writeOwn(strings.Join(parts, separator))
+}
+
+// CopyPreviousToClipboard copies a recent item back to the clipboard. Each
+// call within the paste-cycle window moves one item further back in history;
+// after a pause it starts again from the most recent item.
+func (cm *ClipboardMonitor) CopyPreviousToClipboard() (*models.ClipboardItem, error) {
+	index := cm.pasteCycle.next()
+
+	items, err := cm.db.GetClipboardItems(1, index, "", database.SortCopied)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wrap around once we've cycled past the oldest item
+	if len(items) == 0 && index > 0 {
+		cm.pasteCycle.reset()
+		index = cm.pasteCycle.next()
+		if items, err = cm.db.GetClipboardItems(1, index, "", database.SortCopied); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	if err := cm.CopyItemToClipboard(items[0].ID); err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// IsItemOnClipboard reports whether the live clipboard holds the content of
+// the item with the given id. It only reads the clipboard, so nothing is
+// captured. Inline images compare their decoded bytes, since the same
+// picture can arrive with a different data URI prefix.
+func (cm *ClipboardMonitor) IsItemOnClipboard(id string) (bool, error) {
+	item, err := cm.db.GetClipboardItemByID(id)
+	if err != nil {
+		return false, err
+	}
+
+	content, err := cm.readClipboard()
+	if err != nil {
+		return false, err
+	}
+	content = cm.config.ApplyCaptureTransforms(normalizeClipboardText(content))
+
+	if item.ContentType == "image" && len(item.ContentBinary) > 0 && !item.Encrypted {
+		if !isBase64Image(content) {
+			return false, nil
+		}
+		data, err := decodeBase64Image(content)
+		if err != nil {
+			return false, nil
+		}
+		return config.HashContent("image", content, data) == config.HashContent(item.ContentType, item.ContentText, item.ContentBinary), nil
+	}
+
+	// Either the cleaned or the original form of a cleaned URL counts
+	if item.OriginalText != "" && content == item.ContentText {
+		return true, nil
+	}
+	if item.Encrypted {
+		hash, err := cm.cipher.keyedHash(content)
+		if err != nil {
+			return false, err
+		}
+		return hash == item.Hash, nil
+	}
+	return contentHash(content) == item.Hash, nil
+}
+
+// FindCurrentInHistory returns every item matching what's on the clipboard
+// now, exactly or after dedup normalization, newest first. Inline images also
+// match items holding the same picture under a different data URI. The
+// clipboard is only read, so this never captures anything.
+func (cm *ClipboardMonitor) FindCurrentInHistory() ([]models.ClipboardItem, error) {
+	content, err := cm.readClipboard()
+	if err != nil {
+		return nil, err
+	}
+	content = cm.config.ApplyCaptureTransforms(normalizeClipboardText(content))
+
+	hash, dedupHash, err := cm.itemHashes(content, cm.config.IsSensitiveContent(content))
+	if err != nil {
+		return nil, err
+	}
+	items, err := cm.db.FindItemsByHash(hash, dedupHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if isBase64Image(content) {
+		data, err := decodeBase64Image(content)
+		if err != nil {
+			return items, nil
+		}
+		images, err := cm.db.FindImageItemsByContent(data)
+		if err != nil {
+			return nil, err
+		}
+		items = mergeItems(items, images)
+	}
+	return items, nil
+}
+
+// mergeItems adds the items of b missing from a, keeping newest first
+func mergeItems(a, b []models.ClipboardItem) []models.ClipboardItem {
+	seen := make(map[string]bool, len(a))
+	for _, item := range a {
+		seen[item.ID] = true
+	}
+	for _, item := range b {
+		if !seen[item.ID] {
+			a = append(a, item)
+		}
+	}
+	sort.SliceStable(a, func(i, j int) bool { return a[i].CreatedAt.After(a[j].CreatedAt) })
+	return a
+}
+
+// PopAndPaste copies the newest unpinned item to the clipboard and then
+// deletes it, so repeated calls work through history like a stack. It
+// returns the popped item, or nil if there was nothing to pop. The item is
+// only deleted once it has been copied.
+func (cm *ClipboardMonitor) PopAndPaste() (*models.ClipboardItem, error) {
+	item, err := cm.db.GetLatestUnpinnedItem()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cm.CopyItemToClipboard(item.ID); err != nil {
+		return nil, err
+	}
+	if err := cm.DeleteItem(item.ID); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ForgetCurrent empties the system clipboard and deletes the newest unpinned
+// item. Both are attempted even if one fails; an empty history is not an error.
+func (cm *ClipboardMonitor) ForgetCurrent() error {
+	var errs []error
+	if err := cm.writeClipboard(""); err != nil {
+		errs = append(errs, fmt.Errorf("failed to clear clipboard: %w", err))
+	}
+
+	item, err := cm.db.GetLatestUnpinnedItem()
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		errs = append(errs, err)
+	default:
+		if err := cm.db.DeleteClipboardItem(item.ID); err != nil {
+			errs = append(errs, err)
+		} else {
+			// Let the same content be captured again straight away
+			if _, dedupHash, err := cm.clipboardHashes(item); err == nil {
+				cm.captureMu.Lock()
+				delete(cm.recentHashes, dedupHash)
+				cm.captureMu.Unlock()
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// RequestClearAll starts a two-step clear and returns the token that
+// ConfirmClearAll must be called with
+func (cm *ClipboardMonitor) RequestClearAll() (string, error) {
+	return cm.clearConfirm.issue()
+}
+
+// ConfirmClearAll clears the history if token came from the latest
+// RequestClearAll and hasn't expired, then reclaims the freed disk space
+func (cm *ClipboardMonitor) ConfirmClearAll(token string, preservePinned bool) error {
+	if err := cm.clearConfirm.consume(token); err != nil {
+		return err
+	}
+
+	if err := cm.db.ClearAllItems(preservePinned); err != nil {
+		return err
+	}
+	cm.announceIfEmpty()
+
+	if err := cm.db.Vacuum(); err != nil {
+		slog.Error("Error vacuuming database", "err", err)
+	}
+	return nil
 }
 
 func (cm *ClipboardMonitor) ClearByType(contentType string, preservePinned bool) error {
-	return cm.db.ClearItemsByType(contentType, preservePinned)
+	if err := cm.db.ClearItemsByType(contentType, preservePinned); err != nil {
+		return err
+	}
+	cm.announceIfEmpty()
+	return nil
+}
+
+func (cm *ClipboardMonitor) ClearInRange(start, end time.Time, preservePinned bool) error {
+	if err := cm.db.ClearItemsInRange(start, end, preservePinned); err != nil {
+		return err
+	}
+	cm.announceIfEmpty()
+	return nil
 }
@@ -1,9 +1,21 @@
 package services
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"log/slog"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"klipd/config"
 	"klipd/database"
@@ -14,213 +26,2075 @@ import (
 )
 
 func setupTestClipboardMonitor(t *testing.T) (*ClipboardMonitor, *database.Database) {
-	// Create temporary directory for test database
-	tempDir := t.TempDir()
+	db, err := database.NewWithPath(t.TempDir(), nil)
+	require.NoError(t, err)
+
+	cfg := &config.Config{
+		PollingInterval:   time.Millisecond * 100, // Fast polling for tests
+		MonitoringEnabled: true,
+		MaxItems:          10,
+		MaxDays:           1,
+	}
+
+	monitor := NewClipboardMonitor(db, cfg)
+
+	t.Cleanup(func() {
+		monitor.Stop()
+		if err := db.Close(); err != nil {
+			t.Logf("Failed to close database: %v", err)
+		}
+	})
+
+	return monitor, db
+}
+
+func TestNewClipboardMonitor(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	assert.NotNil(t, monitor)
+	assert.NotNil(t, monitor.db)
+	assert.NotNil(t, monitor.config)
+	assert.NotNil(t, monitor.ctx)
+	assert.NotNil(t, monitor.cancel)
+	assert.False(t, monitor.isRunning)
+
+	// Cleanup
+	monitor.Stop()
+	if err := db.Close(); err != nil {
+		t.Logf("Failed to close database: %v", err)
+	}
+}
+
+func TestClipboardMonitorStart(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	// Test starting the monitor
+	err := monitor.Start()
+	assert.NoError(t, err)
+	assert.True(t, monitor.IsRunning())
+
+	// Test starting again (should return error)
+	err = monitor.Start()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already running")
+
+	// Cleanup
+	monitor.Stop()
+	if err := db.Close(); err != nil {
+		t.Logf("Failed to close database: %v", err)
+	}
+}
+
+func TestStartCapturesExistingClipboard(t *testing.T) {
+	for _, capture := range []bool{true, false} {
+		t.Run(fmt.Sprintf("capture=%v", capture), func(t *testing.T) {
+			monitor, db := setupTestClipboardMonitor(t)
+			monitor.config.CaptureExistingOnStart = capture
+			monitor.readClipboard = func() (string, error) { return "copied before launch", nil }
+
+			require.NoError(t, monitor.Start())
+			// Let a few polls run; the baseline must keep them from capturing
+			time.Sleep(250 * time.Millisecond)
+			monitor.Stop()
+
+			items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+			require.NoError(t, err)
+			if capture {
+				require.Len(t, items, 1)
+				assert.Equal(t, "copied before launch", items[0].ContentText)
+			} else {
+				assert.Empty(t, items)
+			}
+		})
+	}
+}
+
+func TestStartPausedOnlyTakesBaseline(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.CaptureExistingOnStart = true
+	monitor.config.MonitoringEnabled = false
+	monitor.readClipboard = func() (string, error) { return "copied before launch", nil }
+
+	require.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	// Resuming doesn't pick up what was there when monitoring started paused
+	monitor.config.MonitoringEnabled = true
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestClipboardMonitorStop(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	// Start the monitor
+	err := monitor.Start()
+	assert.NoError(t, err)
+	assert.True(t, monitor.IsRunning())
+
+	// Stop the monitor
+	monitor.Stop()
+
+	// Give it a moment to stop
+	time.Sleep(time.Millisecond * 50)
+	assert.False(t, monitor.IsRunning())
+
+	// Test stopping again (should not panic)
+	monitor.Stop()
+	assert.False(t, monitor.IsRunning())
+
+	// Cleanup
+	if err := db.Close(); err != nil {
+		t.Logf("Failed to close database: %v", err)
+	}
+}
+
+func TestClipboardMonitorIsRunning(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	// Initially not running
+	assert.False(t, monitor.IsRunning())
+
+	// Start and check
+	err := monitor.Start()
+	assert.NoError(t, err)
+	assert.True(t, monitor.IsRunning())
+
+	// Stop and check
+	monitor.Stop()
+	time.Sleep(time.Millisecond * 50)
+	assert.False(t, monitor.IsRunning())
+
+	// Cleanup
+	if err := db.Close(); err != nil {
+		t.Logf("Failed to close database: %v", err)
+	}
+}
+
+func TestUpdateConfig(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	// Create new config
+	newConfig := &config.Config{
+		PollingInterval:   time.Second,
+		MonitoringEnabled: false,
+		MaxItems:          50,
+		MaxDays:           30,
+	}
+
+	// Update config
+	monitor.UpdateConfig(newConfig)
+
+	// Verify config was updated
+	assert.Equal(t, newConfig.PollingInterval, monitor.config.PollingInterval)
+	assert.Equal(t, newConfig.MonitoringEnabled, monitor.config.MonitoringEnabled)
+	assert.Equal(t, newConfig.MaxItems, monitor.config.MaxItems)
+	assert.Equal(t, newConfig.MaxDays, monitor.config.MaxDays)
+
+	// Cleanup
+	monitor.Stop()
+	if err := db.Close(); err != nil {
+		t.Logf("Failed to close database: %v", err)
+	}
+}
+
+func TestCapturedItemsHashThroughConfig(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	clip := "Hello, World!"
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	monitor.checkClipboard()
+
+	pngData := encodeTestPNG(t, 8, 8)
+	clip = "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData)
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	image, text := items[0], items[1]
+	require.Equal(t, "image", image.ContentType)
+
+	// Stored hashes are the single config hash of the captured content
+	assert.Equal(t, config.GenerateHash("Hello, World!"), text.Hash)
+	assert.Equal(t, config.HashContent(text.ContentType, text.ContentText, nil), text.Hash)
+	assert.Equal(t, config.HashContent("image", clip, pngData), image.Hash)
+	assert.Equal(t, image.Hash, image.DedupHash)
+
+	// An image's content hash is that of its bytes, however they're wrapped
+	assert.Equal(t, config.HashContent("image", "", pngData), config.HashContent(image.ContentType, image.ContentText, image.ContentBinary))
+	other := "data:image/x-png;base64," + base64.StdEncoding.EncodeToString(pngData)
+	assert.Equal(t, config.HashContent("image", other, pngData), config.HashContent(image.ContentType, image.ContentText, image.ContentBinary))
+}
+
+func TestDetectContentType(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	tests := []struct {
+		content      string
+		expectedType string
+	}{
+		{"Hello, World!", "text"},
+		{"file:///Users/test/document.pdf", "file"},
+		{"http://example.com", "text"},
+		{"https://example.com", "text"},
+		{"C:\\Users\\test\\file.txt", "file"},
+		{"/Users/test/image.png", "image"},
+		{"", "text"},
+		{"Multi\nline\ntext", "text"},
+		{"/Users/test/image.jpg", "image"},
+		{"~/Downloads/photo.jpeg", "image"},
+		{"data:image/png;base64," + onePixelPNG, "image"},
+		{onePixelPNG, "image"},
+		{"aGVsbG8gd29ybGQsIHRoaXMgaXMgbm90IGFuIGltYWdl", "text"},
+		{`{"key":"value","list":[1,2]}`, "json"},
+		{`[{"id":1}]`, "json"},
+		{`{not json}`, "text"},
+		{"#ff8800", "color"},
+		{"  #FA0 ", "color"},
+		{"#ff880080", "color"},
+		{"#ff88", "text"},
+		{"color: #ff8800", "text"},
+		{"a\tb\tc\n1\t2\t3\n", "table"},
+		{"sku,qty\nA-1,4\nB-2,7", "table"},
+		{"sku,qty\nA-1,4", "text"},
+		{"Hi there, Ada\nThanks, see you soon", "text"},
+	}
+
+	for _, test := range tests {
+		contentType := monitor.detectContentType(test.content)
+		assert.Equal(t, test.expectedType, contentType, "Content: %s", test.content)
+	}
+
+	// Cleanup
+	monitor.Stop()
+	if err := db.Close(); err != nil {
+		t.Logf("Failed to close database: %v", err)
+	}
+}
+
+func TestDetectContentTypeCustomImageExtensions(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+	assert.Equal(t, "file", monitor.detectContentType("/Users/test/IMG_0042.heic"))
+
+	monitor.config.ImageExtensions = config.ParseImageExtensions("heic,png")
+	assert.Equal(t, "image", monitor.detectContentType("/Users/test/IMG_0042.heic"))
+	assert.Equal(t, "file", monitor.detectContentType("/Users/test/photo.jpg"))
+}
+
+func TestCheckClipboardRecordsFileInfo(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("%PDF-1.7 content"), 0644))
+	missing := filepath.Join(t.TempDir(), "deleted.pdf")
+
+	clip := path
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	monitor.checkClipboard()
+	clip = missing
+	monitor.checkClipboard()
+	clip = "plain text"
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	byContent := make(map[string]models.ClipboardItem)
+	for _, item := range items {
+		byContent[item.ContentText] = item
+	}
+
+	assert.Equal(t, "file", byContent[path].ContentType)
+	assert.True(t, byContent[path].FileExists)
+	assert.Equal(t, int64(16), byContent[path].FileSize)
+
+	assert.Equal(t, "file", byContent[missing].ContentType)
+	assert.False(t, byContent[missing].FileExists)
+	assert.Zero(t, byContent[missing].FileSize)
+
+	assert.False(t, byContent["plain text"].FileExists)
+}
+
+type stubSecureInput struct {
+	active bool
+}
+
+func (s *stubSecureInput) IsSecureInputActive() bool {
+	return s.active
+}
+
+type stubSourceApp struct {
+	name string
+}
+
+func (s *stubSourceApp) FrontmostApp() string {
+	return s.name
+}
+
+func TestCheckClipboardRecordsSourceApp(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	source := &stubSourceApp{name: "Terminal"}
+	monitor.sourceApp = source
+
+	for i := 0; i < 4; i++ {
+		content := fmt.Sprintf("ls -la %d", i)
+		monitor.readClipboard = func() (string, error) { return content, nil }
+		monitor.checkClipboard()
+	}
+	source.name = "Notes"
+	monitor.readClipboard = func() (string, error) { return "shopping list", nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+	assert.Equal(t, "Notes", items[0].SourceApp)
+	assert.Equal(t, "Terminal", items[1].SourceApp)
+
+	// Cleanup trims the chatty app to the cap and leaves the other alone
+	settings, err := db.GetSettings()
+	require.NoError(t, err)
+	settings.MaxItemsPerApp = 2
+	require.NoError(t, db.UpdateSettings(settings))
+	monitor.performCleanup()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	var contents []string
+	for _, item := range items {
+		contents = append(contents, item.SourceApp+": "+item.ContentText)
+	}
+	assert.ElementsMatch(t, []string{"Notes: shopping list", "Terminal: ls -la 2", "Terminal: ls -la 3"}, contents)
+}
+
+// captureLogs sends the default logger to a buffer at level for the rest of
+// the test
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestRunCleanupNow(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	var completed []int
+	monitor.emitEvent = func(name string, data interface{}) {
+		if name == "cleanup-complete" {
+			completed = append(completed, data.(int))
+		}
+	}
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: fmt.Sprintf("cleanup-%d", i), ContentType: "text", ContentText: fmt.Sprintf("item %d", i),
+			Hash: fmt.Sprintf("cleanup-hash-%d", i), CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "cleanup-pinned", ContentType: "text", ContentText: "pinned", Hash: "cleanup-hash-pinned",
+		IsPinned: true, CreatedAt: now.Add(-time.Hour),
+	}))
+
+	settings, err := db.GetSettings()
+	require.NoError(t, err)
+	settings.MaxItems = 2
+	require.NoError(t, db.UpdateSettings(settings))
+
+	// The oldest unpinned items are gone as soon as it returns
+	removed, err := monitor.RunCleanupNow()
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, []int{3}, completed)
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pinned", "item 3", "item 4"}, itemContents(items))
+
+	// Nothing left over the limit
+	removed, err = monitor.RunCleanupNow()
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, []int{3, 0}, completed)
+}
+
+func TestHistoryEmptyEvent(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	empties := 0
+	monitor.emitEvent = func(name string, data interface{}) {
+		if name == "history-empty" {
+			empties++
+		}
+	}
+
+	empty, err := monitor.IsHistoryEmpty()
+	require.NoError(t, err)
+	assert.True(t, empty)
+
+	for _, id := range []string{"first", "second"} {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: id, ContentType: "text", ContentText: id, Hash: "empty-" + id,
+		}))
+	}
+	empty, err = monitor.IsHistoryEmpty()
+	require.NoError(t, err)
+	assert.False(t, empty)
+
+	// Only removing the last item announces it
+	require.NoError(t, monitor.DeleteItem("first"))
+	assert.Equal(t, 0, empties)
+	require.NoError(t, monitor.DeleteItem("second"))
+	assert.Equal(t, 1, empties)
+
+	empty, err = monitor.IsHistoryEmpty()
+	require.NoError(t, err)
+	assert.True(t, empty)
+}
+
+func TestCheckClipboardDoesNotLogContent(t *testing.T) {
+	secret := "meeting notes: launch moves to friday"
+
+	for _, level := range []slog.Level{slog.LevelInfo, slog.LevelDebug} {
+		t.Run(level.String(), func(t *testing.T) {
+			monitor, db := setupTestClipboardMonitor(t)
+			logs := captureLogs(t, level)
+
+			monitor.readClipboard = func() (string, error) { return secret, nil }
+			monitor.checkClipboard()
+
+			items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+			assert.NotContains(t, logs.String(), "meeting notes")
+
+			if level == slog.LevelDebug {
+				assert.Contains(t, logs.String(), items[0].ID)
+				assert.Contains(t, logs.String(), fmt.Sprintf("size=%d", len(secret)))
+			}
+		})
+	}
+
+	t.Run("previews enabled", func(t *testing.T) {
+		monitor, _ := setupTestClipboardMonitor(t)
+		monitor.config.LogContentPreviews = true
+		logs := captureLogs(t, slog.LevelDebug)
+
+		monitor.readClipboard = func() (string, error) { return secret, nil }
+		monitor.checkClipboard()
+
+		assert.Contains(t, logs.String(), "meeting notes")
+	})
+}
+
+func TestCheckClipboardSkipsSecureInput(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.RespectSecureInput = true
+
+	secureInput := &stubSecureInput{active: true}
+	monitor.secureInput = secureInput
+
+	content := "typed into a password field"
+	monitor.readClipboard = func() (string, error) { return content, nil }
+
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	assert.NoError(t, err)
+	assert.Len(t, items, 0, "Nothing should be captured while secure input is active")
+
+	// Content seen during secure input must not be captured once it ends
+	secureInput.active = false
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	assert.NoError(t, err)
+	assert.Len(t, items, 0)
+
+	// New content after secure input ends is captured as usual
+	content = "regular clipboard text"
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	assert.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "regular clipboard text", items[0].ContentText)
+	assert.Equal(t, len("regular clipboard text"), items[0].SizeBytes)
+}
+
+func TestCheckClipboardIgnoresSecureInputWhenDisabled(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.RespectSecureInput = false
+	monitor.secureInput = &stubSecureInput{active: true}
+	monitor.readClipboard = func() (string, error) { return "captured anyway", nil }
+
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestCopyPreviousToClipboardCycles(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.pasteCycle = newPasteCycle(100 * time.Millisecond)
+
+	var written []string
+	monitor.writeClipboard = func(content string) error {
+		written = append(written, content)
+		return nil
+	}
+
+	base := time.Now()
+	for i, content := range []string{"oldest", "middle", "newest"} {
+		item := &models.ClipboardItem{
+			ID:          "cycle-" + content,
+			ContentType: "text",
+			ContentText: content,
+			PreviewText: content,
+			Hash:        "cycle-hash-" + content,
+			CreatedAt:   base.Add(time.Duration(i) * time.Second),
+		}
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	// Two quick presses walk back from the newest item
+	item, err := monitor.CopyPreviousToClipboard()
+	require.NoError(t, err)
+	assert.Equal(t, "newest", item.ContentText)
+
+	item, err = monitor.CopyPreviousToClipboard()
+	require.NoError(t, err)
+	assert.Equal(t, "middle", item.ContentText)
+
+	// After the window expires the cycle starts over
+	time.Sleep(200 * time.Millisecond)
+
+	item, err = monitor.CopyPreviousToClipboard()
+	require.NoError(t, err)
+	assert.Equal(t, "newest", item.ContentText)
+
+	assert.Equal(t, []string{"newest", "middle", "newest"}, written)
+}
+
+func TestCopyPreviousToClipboardWrapsAround(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.writeClipboard = func(string) error { return nil }
+
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "only", ContentType: "text", ContentText: "only", PreviewText: "only", Hash: "only-hash",
+	}))
+
+	for i := 0; i < 3; i++ {
+		item, err := monitor.CopyPreviousToClipboard()
+		require.NoError(t, err)
+		require.NotNil(t, item)
+		assert.Equal(t, "only", item.ID)
+	}
+}
+
+func TestCopyPreviousToClipboardEmptyHistory(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+
+	item, err := monitor.CopyPreviousToClipboard()
+	assert.NoError(t, err)
+	assert.Nil(t, item)
+}
+
+func TestCheckClipboardCapturesBase64Image(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	content := "data:image/png;base64," + base64.StdEncoding.EncodeToString(encodeTestPNG(t, 200, 100))
+	monitor.readClipboard = func() (string, error) { return content, nil }
+
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	item := items[0]
+	assert.Equal(t, "image", item.ContentType)
+	assert.Equal(t, "Image (PNG, 200x100)", item.PreviewText)
+	assert.True(t, bytes.HasPrefix(item.ContentBinary, []byte("\x89PNG")))
+	assert.NotEmpty(t, item.Thumbnail)
+}
+
+func TestCheckClipboardDedupsImageByBytes(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	pngData := encodeTestPNG(t, 20, 10)
+	content := "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData)
+	monitor.readClipboard = func() (string, error) { return content, nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	hash := config.HashContent("image", content, pngData)
+	assert.Equal(t, hash, items[0].Hash)
+	assert.Equal(t, hash, items[0].DedupHash)
+
+	// The same picture under another data URI prefix is the same item
+	content = "data:image/x-png;base64," + base64.StdEncoding.EncodeToString(pngData)
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestCheckClipboardDownscalesLargeImage(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	// Noise doesn't compress, so this is well over the 1MB content limit
+	img := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	rng := rand.New(rand.NewSource(1))
+	rng.Read(img.Pix)
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	content := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	require.Greater(t, len(content), 1024*1024)
+
+	monitor.readClipboard = func() (string, error) { return content, nil }
+
+	// Without a limit the existing size check drops it
+	monitor.checkClipboard()
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	monitor.config.MaxImageDimension = 200
+	monitor.lastHash = ""
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	item := items[0]
+	assert.Equal(t, "image", item.ContentType)
+	assert.Equal(t, "Image (PNG, 800x600, downscaled to 200x150)", item.PreviewText)
+	assert.Equal(t, config.HashContent("image", content, buf.Bytes()), item.Hash, "Hash should match the original image")
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(item.ContentBinary))
+	require.NoError(t, err)
+	assert.Equal(t, 200, cfg.Width)
+	assert.Equal(t, 150, cfg.Height)
+}
+
+func TestCopyItemFormatted(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	var written string
+	monitor.writeClipboard = func(content string) error {
+		written = content
+		return nil
+	}
+
+	minified := `{"name":"klipd","ok":true}`
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "json-item", ContentType: "json", ContentText: minified, PreviewText: minified, Hash: "json-hash", Language: "json",
+	}))
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "broken-item", ContentType: "text", ContentText: `{"name":`, PreviewText: `{"name":`, Hash: "broken-hash",
+	}))
+
+	err := monitor.CopyItemFormatted("json-item")
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"name\": \"klipd\",\n  \"ok\": true\n}", written)
+
+	// The stored item keeps its original content
+	stored, err := db.GetClipboardItemByID("json-item")
+	require.NoError(t, err)
+	assert.Equal(t, minified, stored.ContentText)
+
+	written = ""
+	err = monitor.CopyItemFormatted("broken-item")
+	assert.Error(t, err)
+	assert.Empty(t, written, "Nothing should be written for invalid JSON")
+}
+
+func TestCopyItemWithLineEnding(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	var written string
+	monitor.writeClipboard = func(content string) error {
+		written = content
+		return nil
+	}
+
+	original := "line one\nline two\r\nline three"
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "lines", ContentType: "text", ContentText: original, PreviewText: original, Hash: "lines-hash",
+	}))
+
+	require.NoError(t, monitor.CopyItemWithLineEnding("lines", "crlf"))
+	assert.Equal(t, "line one\r\nline two\r\nline three", written)
+
+	stored, err := db.GetClipboardItemByID("lines")
+	require.NoError(t, err)
+	assert.Equal(t, original, stored.ContentText)
+
+	// The converted text isn't captured as a new item
+	monitor.readClipboard = func() (string, error) { return written, nil }
+	monitor.checkClipboard()
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	written = ""
+	assert.Error(t, monitor.CopyItemWithLineEnding("lines", "dos"))
+	assert.Empty(t, written)
+}
+
+func TestCheckClipboardIgnoresSelfWrites(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.IgnoreSelfWrites = true
+	monitor.writeClipboard = func(string) error { return nil }
+
+	for i, text := range []string{"alpha", "beta"} {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: fmt.Sprintf("self-%d", i), ContentType: "text", ContentText: text, PreviewText: text, Hash: "self-hash-" + text,
+		}))
+	}
+	require.NoError(t, monitor.CopyItemsJoined([]string{"self-0", "self-1"}, ", "))
+
+	clipboardText := ""
+	monitor.readClipboard = func() (string, error) { return clipboardText, nil }
+	capture := func(content string) {
+		clipboardText = content
+		monitor.checkClipboard()
+	}
+
+	// Genuinely new content is captured, then another clipboard manager
+	// puts back what Klipd wrote; that isn't a new copy
+	capture("gamma")
+	capture("alpha, beta")
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alpha", "beta", "gamma"}, itemContents(items))
+
+	// With the option off the returning content is captured like any other
+	monitor.config.IgnoreSelfWrites = false
+	capture("delta")
+	capture("alpha, beta")
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alpha", "beta", "gamma", "delta", "alpha, beta"}, itemContents(items))
+}
+
+func TestCheckClipboardOnlyIgnoresLatestSelfWrite(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.IgnoreSelfWrites = true
+	monitor.writeClipboard = func(string) error { return nil }
+
+	for i, text := range []string{"alpha", "beta"} {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: fmt.Sprintf("self-%d", i), ContentType: "text", ContentText: text, PreviewText: text, Hash: "self-hash-" + text,
+		}))
+	}
+	require.NoError(t, monitor.CopyItemsJoined([]string{"self-0", "self-1"}, ", "))
+	require.NoError(t, monitor.CopyItemsJoined([]string{"self-0", "self-1"}, " / "))
+
+	clipboardText := ""
+	monitor.readClipboard = func() (string, error) { return clipboardText, nil }
+	capture := func(content string) {
+		clipboardText = content
+		monitor.checkClipboard()
+	}
+
+	// The earlier write was replaced on the clipboard, so it coming back is
+	// a copy like any other
+	capture("alpha, beta")
+	capture("alpha / beta")
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alpha", "beta", "alpha, beta"}, itemContents(items))
+}
+
+func TestCopyItemsJoined(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	var written string
+	monitor.writeClipboard = func(content string) error {
+		written = content
+		return nil
+	}
+
+	for i, text := range []string{"first", "second", "third"} {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: fmt.Sprintf("join-%d", i), ContentType: "text", ContentText: text, PreviewText: text, Hash: "join-hash-" + text,
+		}))
+	}
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "join-image", ContentType: "image", ContentBinary: []byte{0x89, 'P', 'N', 'G'}, Hash: "join-hash-image",
+	}))
+
+	err := monitor.CopyItemsJoined([]string{"join-2", "join-0", "join-1"}, " | ")
+	require.NoError(t, err)
+	assert.Equal(t, "third | first | second", written)
+
+	// The joined text isn't captured as a new item on the next poll
+	monitor.readClipboard = func() (string, error) { return written, nil }
+	monitor.checkClipboard()
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 4)
+
+	written = ""
+	assert.Error(t, monitor.CopyItemsJoined([]string{"join-0", "join-image"}, "\n"))
+	assert.Error(t, monitor.CopyItemsJoined([]string{"join-0", "missing"}, "\n"))
+	assert.Error(t, monitor.CopyItemsJoined(nil, "\n"))
+	assert.Empty(t, written, "Nothing should be written when an item is rejected")
+}
+
+func TestCheckClipboardSkipsWhitespaceOnlyChanges(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.SkipWhitespaceOnlyChanges = true
+
+	for _, content := range []string{"if ok {\n\treturn\n}", "if ok {\n    return\n}  "} {
+		monitor.readClipboard = func() (string, error) { return content, nil }
+		monitor.checkClipboard()
+	}
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "if ok {\n\treturn\n}", items[0].ContentText)
+
+	// A real edit is still captured
+	monitor.readClipboard = func() (string, error) { return "if !ok {\n\treturn\n}", nil }
+	monitor.checkClipboard()
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	// With the option off, whitespace variants are separate items
+	monitor.config.SkipWhitespaceOnlyChanges = false
+	monitor.readClipboard = func() (string, error) { return "if !ok {  return }", nil }
+	monitor.checkClipboard()
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+}
+
+func TestCopyOneTimeItemConsumesIt(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	clipboardContent := "482913"
+	monitor.readClipboard = func() (string, error) { return clipboardContent, nil }
+	monitor.writeClipboard = func(s string) error { clipboardContent = s; return nil }
+	var consumed []string
+	monitor.emitEvent = func(name string, data interface{}) {
+		if name == "item-consumed" {
+			consumed = append(consumed, data.(string))
+		}
+	}
+
+	monitor.checkClipboard()
+	clipboardContent = "pinned code"
+	monitor.checkClipboard()
+	clipboardContent = "something else"
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	pinned, code := items[1], items[2]
+	require.NoError(t, monitor.SetItemOneTime(code.ID, true))
+	require.NoError(t, monitor.SetItemOneTime(pinned.ID, true))
+	require.NoError(t, monitor.PinItem(pinned.ID, true))
+
+	require.NoError(t, monitor.CopyItemToClipboard(code.ID))
+	assert.Equal(t, "482913", clipboardContent)
+	assert.Equal(t, []string{code.ID}, consumed)
+	_, err = db.GetClipboardItemByID(code.ID)
+	assert.Error(t, err)
+
+	// Still on the clipboard, but not captured again
+	monitor.checkClipboard()
+
+	// A pinned one-time item is kept
+	require.NoError(t, monitor.CopyItemToClipboard(pinned.ID))
+	assert.Equal(t, []string{code.ID}, consumed)
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pinned code", "something else"}, itemContents(items))
+}
+
+func TestFindCurrentInHistory(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.DedupNormalization = config.DedupTrimLower
+
+	now := time.Now()
+	seed := func(id, content string, age time.Duration) {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: id, ContentType: "text", ContentText: content, PreviewText: content,
+			Hash:      config.GenerateHash(content),
+			DedupHash: config.GenerateHash(monitor.config.DedupKey(content)),
+			CreatedAt: now.Add(-age),
+		}))
+	}
+	seed("exact-old", "Deploy notes", 2*time.Hour)
+	seed("exact-new", "Deploy notes", time.Hour)
+	seed("normalized", "  deploy NOTES ", 30*time.Minute)
+	seed("other", "Something else", time.Minute)
+
+	clip := "Deploy notes"
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+
+	found, err := monitor.FindCurrentInHistory()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"normalized", "exact-new", "exact-old"}, itemIDs(found))
+
+	// Looking doesn't capture
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 4)
+	monitor.checkClipboard()
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 4)
+
+	clip = "never copied"
+	found, err = monitor.FindCurrentInHistory()
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestFindCurrentInHistoryMatchesImageBytes(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	pngData := encodeTestPNG(t, 20, 10)
+	clip := "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData)
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	captured := items[0].ID
+
+	// The same picture under another data URI prefix is still found
+	clip = "data:image/x-png;base64," + base64.StdEncoding.EncodeToString(pngData)
+	found, err := monitor.FindCurrentInHistory()
+	require.NoError(t, err)
+	assert.Equal(t, []string{captured}, itemIDs(found))
+
+	clip = "data:image/png;base64," + base64.StdEncoding.EncodeToString(encodeTestPNG(t, 10, 20))
+	found, err = monitor.FindCurrentInHistory()
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestDeletedItemIsNotRecaptured(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	clipboardContent := "delete me"
+	monitor.readClipboard = func() (string, error) { return clipboardContent, nil }
+	monitor.writeClipboard = func(s string) error { clipboardContent = s; return nil }
+
+	monitor.checkClipboard()
+	clipboardContent = "something else"
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	deleted := items[1]
+
+	// Copying it back from history and deleting it before the next poll
+	require.NoError(t, monitor.CopyItemToClipboard(deleted.ID))
+	require.NoError(t, monitor.DeleteItem(deleted.ID))
+	monitor.checkClipboard()
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"something else"}, itemContents(items))
+
+	// Once the clipboard has moved on, copying it again is a new capture
+	clipboardContent = "third"
+	monitor.checkClipboard()
+	clipboardContent = "delete me"
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"delete me", "third", "something else"}, itemContents(items))
+}
+
+func TestDeletingOldItemDoesNotBlockRecapture(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.DedupWindow = 0
+
+	clipboardContent := "old item"
+	monitor.readClipboard = func() (string, error) { return clipboardContent, nil }
+
+	monitor.checkClipboard()
+	clipboardContent = "current"
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	// Deleting an item that isn't on the clipboard leaves its content free to
+	// be captured when it's next copied
+	require.NoError(t, monitor.DeleteItem(items[1].ID))
+	clipboardContent = "old item"
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"old item", "current"}, itemContents(items))
+}
+
+func TestExpiringHashes(t *testing.T) {
+	hashes := newExpiringHashes(20 * time.Millisecond)
+	hashes.add("hash")
+	assert.True(t, hashes.contains("hash"))
+	assert.False(t, hashes.contains("other"))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, hashes.contains("hash"))
+
+	hashes.add("hash")
+	hashes.clear()
+	assert.False(t, hashes.contains("hash"))
+}
+
+func itemContents(items []models.ClipboardItem) []string {
+	contents := make([]string, 0, len(items))
+	for _, item := range items {
+		contents = append(contents, item.ContentText)
+	}
+	return contents
+}
+
+func itemIDs(items []models.ClipboardItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestCheckClipboardDedupWindow(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.DedupWindow = 150 * time.Millisecond
+
+	content := "rewritten by a chatty app"
+	monitor.readClipboard = func() (string, error) { return content, nil }
+
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	firstSeen := items[0].LastSeen
+
+	// Something else is copied, then the same content comes back inside the window
+	content = "interleaved"
+	monitor.checkClipboard()
+	content = "rewritten by a chatty app"
+	monitor.checkClipboard()
+
+	item, err := db.GetClipboardItemByID(items[0].ID)
+	require.NoError(t, err)
+	assert.True(t, firstSeen.Equal(item.LastSeen), "Repeat inside the window must not touch the DB")
+
+	// Outside the window the normal duplicate handling applies again
+	time.Sleep(200 * time.Millisecond)
+	content = "interleaved again"
+	monitor.checkClipboard()
+	content = "rewritten by a chatty app"
+	monitor.checkClipboard()
+
+	item, err = db.GetClipboardItemByID(items[0].ID)
+	require.NoError(t, err)
+	assert.True(t, item.LastSeen.After(firstSeen), "Repeat outside the window should update the existing item")
+
+	count, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, count, 3)
+}
+
+// eventRecorder collects emitted events; emission can come from timer goroutines
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []models.ItemsChanged
+}
+
+func (r *eventRecorder) emit(name string, data interface{}) {
+	if event, ok := data.(models.ItemsChanged); ok && name == "items-changed" {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, event)
+	}
+}
+
+func (r *eventRecorder) recorded() []models.ItemsChanged {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.ItemsChanged(nil), r.events...)
+}
+
+func TestMonitorStateEvents(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+	monitor.config.PollingInterval = time.Hour // Polls are driven by hand below
+
+	var mu sync.Mutex
+	var events []models.MonitorState
+	monitor.emitEvent = func(name string, data interface{}) {
+		if event, ok := data.(models.MonitorState); ok && name == "monitor-state" {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}
+	}
+	states := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var result []string
+		for _, event := range events {
+			result = append(result, event.State)
+		}
+		return result
+	}
+
+	readErr := errors.New("pasteboard unavailable")
+	failing := false
+	monitor.readClipboard = func() (string, error) {
+		if failing {
+			return "", readErr
+		}
+		return "content", nil
+	}
+
+	assert.Equal(t, MonitorStopped, monitor.State())
+	require.NoError(t, monitor.Start())
+
+	monitor.SetMonitoringEnabled(false)
+	monitor.SetMonitoringEnabled(false) // No change, no event
+
+	// A config-driven change is picked up the same way
+	enabled := *monitor.config
+	enabled.MonitoringEnabled = true
+	monitor.UpdateConfig(&enabled)
+
+	failing = true
+	monitor.checkClipboard()
+	monitor.checkClipboard() // Still failing, no event
+	failing = false
+	monitor.checkClipboard()
+
+	monitor.Stop()
+
+	assert.Equal(t, []string{MonitorRunning, MonitorPaused, MonitorResumed, MonitorError, MonitorRunning, MonitorStopped}, states())
+	assert.Equal(t, MonitorStopped, monitor.State())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "pasteboard unavailable", events[3].Error)
+	assert.False(t, events[1].Enabled)
+	assert.True(t, events[2].Enabled)
+	assert.False(t, events[5].Running)
+}
+
+func TestMonitorStartsPaused(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+	monitor.config.MonitoringEnabled = false
+
+	var states []string
+	monitor.emitEvent = func(name string, data interface{}) {
+		if event, ok := data.(models.MonitorState); ok {
+			states = append(states, event.State)
+		}
+	}
+
+	require.NoError(t, monitor.Start())
+	monitor.Stop()
+	assert.Equal(t, []string{MonitorPaused, MonitorStopped}, states)
+}
+
+func TestCheckClipboardCoalescesItemEvents(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.EventCoalesceWindow = 100 * time.Millisecond
+
+	recorder := &eventRecorder{}
+	monitor.emitEvent = recorder.emit
+
+	for i := 0; i < 5; i++ {
+		content := fmt.Sprintf("burst item %d", i)
+		monitor.readClipboard = func() (string, error) { return content, nil }
+		monitor.checkClipboard()
+	}
+	assert.Empty(t, recorder.recorded())
+
+	require.Eventually(t, func() bool { return len(recorder.recorded()) > 0 }, time.Second, 10*time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+
+	events := recorder.recorded()
+	require.Len(t, events, 1)
+	assert.Equal(t, 5, events[0].Count)
+	require.Len(t, events[0].Added, 5)
+	assert.Equal(t, events[0].Added[4], events[0].Latest)
+	latest, err := db.GetClipboardItemByID(events[0].Latest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "burst item 4", latest.ContentText)
+	assert.Equal(t, len("burst item 0"), events[0].Added[0].SizeBytes)
+}
+
+func TestItemEventCarriesImageMeta(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+	monitor.config.EventCoalesceWindow = 0
+	monitor.config.MaxImageDimension = 100
+
+	recorder := &eventRecorder{}
+	monitor.emitEvent = recorder.emit
+
+	content := "data:image/png;base64," + base64.StdEncoding.EncodeToString(encodeTestPNG(t, 300, 150))
+	monitor.readClipboard = func() (string, error) { return content, nil }
+	monitor.checkClipboard()
+
+	events := recorder.recorded()
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Added, 1)
+	meta := events[0].Added[0]
+	assert.Equal(t, "image", meta.ContentType)
+	assert.Equal(t, events[0].Latest, meta)
+	// The size stored after downscaling, which is what the thumbnail shows
+	assert.Equal(t, 100, meta.Width)
+	assert.Equal(t, 50, meta.Height)
+	assert.Positive(t, meta.SizeBytes)
+
+	// The metadata is all there is to it over the wire
+	encoded, err := json.Marshal(events[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "base64")
+}
+
+func TestItemEventsDroppedOnStop(t *testing.T) {
+	monitor, _ := setupTestClipboardMonitor(t)
+	monitor.config.EventCoalesceWindow = 50 * time.Millisecond
+
+	recorder := &eventRecorder{}
+	monitor.emitEvent = recorder.emit
+
+	monitor.readClipboard = func() (string, error) { return "pending item", nil }
+	monitor.checkClipboard()
+
+	monitor.isRunning = true
+	monitor.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, recorder.recorded())
+}
+
+func TestConfirmClearAll(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	seed := func() {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "keep", ContentType: "text", ContentText: "keep", Hash: "clear-1", IsPinned: true}))
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "drop", ContentType: "text", ContentText: "drop", Hash: "clear-2"}))
+	}
+	count := func() int {
+		items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+		require.NoError(t, err)
+		return len(items)
+	}
+	seed()
+
+	t.Run("Mismatched token", func(t *testing.T) {
+		_, err := monitor.RequestClearAll()
+		require.NoError(t, err)
+
+		assert.Error(t, monitor.ConfirmClearAll("not-the-token", false))
+		assert.Equal(t, 2, count())
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		monitor.clearConfirm = newClearConfirmation(10 * time.Millisecond)
+		defer func() { monitor.clearConfirm = newClearConfirmation(clearTokenTTL) }()
+
+		token, err := monitor.RequestClearAll()
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Error(t, monitor.ConfirmClearAll(token, false))
+		assert.Equal(t, 2, count())
+	})
+
+	t.Run("Valid token", func(t *testing.T) {
+		token, err := monitor.RequestClearAll()
+		require.NoError(t, err)
+
+		require.NoError(t, monitor.ConfirmClearAll(token, true))
+		assert.Equal(t, 1, count(), "Pinned item should survive")
+
+		// Tokens are single use
+		assert.Error(t, monitor.ConfirmClearAll(token, false))
+		assert.Equal(t, 1, count())
+	})
+
+	t.Run("Newer request invalidates older token", func(t *testing.T) {
+		first, err := monitor.RequestClearAll()
+		require.NoError(t, err)
+		second, err := monitor.RequestClearAll()
+		require.NoError(t, err)
+
+		assert.Error(t, monitor.ConfirmClearAll(first, true))
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "drop-again", ContentType: "text", ContentText: "drop again", Hash: "clear-3"}))
+		require.NoError(t, monitor.ConfirmClearAll(second, true))
+		assert.Equal(t, 1, count())
+	})
+}
+
+func TestCheckClipboardEncryptsSensitiveItems(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.AllowPasswords = true
+	monitor.cipher = newItemCipher(testKey())
+
+	secret := "VeryComplexP@ssw0rd!"
+	monitor.readClipboard = func() (string, error) { return secret, nil }
+	var written string
+	monitor.writeClipboard = func(s string) error { written = s; return nil }
+
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	stored := items[0]
+	assert.True(t, stored.Encrypted)
+	assert.GreaterOrEqual(t, stored.Sensitivity, config.DefaultPasswordSkipThreshold)
+	assert.NotContains(t, stored.ContentText, secret)
+	assert.NotContains(t, stored.PreviewText, secret)
+
+	// Stored hashes can't be checked against a guessed password
+	assert.NotEqual(t, config.GenerateHash(secret), stored.Hash)
+	assert.NotEqual(t, config.GenerateHash(secret), stored.DedupHash)
+
+	onClipboard, err := monitor.IsItemOnClipboard(stored.ID)
+	require.NoError(t, err)
+	assert.True(t, onClipboard)
+
+	// Copying it again still finds the existing item
+	monitor.config.DedupWindow = 0
+	monitor.lastHash = ""
+	monitor.checkClipboard()
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	// Search only sees the masked preview
+	results, err := db.SearchAllFields("P@ssw0rd", nil, 10, 0, database.SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	item, err := monitor.GetItemByID(stored.ID)
+	require.NoError(t, err)
+	assert.Equal(t, secret, item.ContentText)
+
+	require.NoError(t, monitor.CopyItemToClipboard(stored.ID))
+	assert.Equal(t, secret, written)
+
+	// Copying must not have written the plaintext back to the row
+	stored2, err := db.GetClipboardItemByID(stored.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stored.ContentText, stored2.ContentText)
+	assert.Equal(t, 1, stored2.UseCount)
+}
+
+func TestCheckClipboardSensitiveWithoutKey(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.AllowPasswords = true
+	monitor.cipher = newItemCipher(missingKey{})
+
+	monitor.readClipboard = func() (string, error) { return "VeryComplexP@ssw0rd!", nil }
+	monitor.checkClipboard()
+
+	// Sensitive content is dropped rather than stored in plaintext
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	// Ordinary content is unaffected
+	monitor.readClipboard = func() (string, error) { return "just some notes", nil }
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.False(t, items[0].Encrypted)
+	assert.Equal(t, "just some notes", items[0].ContentText)
+}
+
+func TestCheckClipboardAppliesCaptureTransforms(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.CaptureTransforms = []string{"crlf-to-lf", "trim-trailing"}
+	monitor.config.DedupWindow = 0
+
+	content := "first line\r\nsecond line  \r\n"
+	monitor.readClipboard = func() (string, error) { return content, nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "first line\nsecond line", items[0].ContentText)
+
+	// Variants that normalize to the same text dedup against the stored item
+	content = "other"
+	monitor.checkClipboard()
+	content = "first line\nsecond line\n"
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+}
+
+func TestCheckClipboardStripsURLTracking(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.StripURLTracking = true
+	monitor.config.TrackingParams = config.DefaultTrackingParams
+	monitor.config.DedupWindow = 0
+
+	original := "https://example.com/article?id=7&utm_source=news&fbclid=abc"
+	clip := original
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	var written string
+	monitor.writeClipboard = func(s string) error { written = s; clip = s; return nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "https://example.com/article?id=7", items[0].ContentText)
+	assert.Equal(t, original, items[0].OriginalText)
+	id := items[0].ID
+
+	// The cleaned copy isn't captured again as a separate item
+	require.NoError(t, monitor.CopyItemToClipboard(id))
+	assert.Equal(t, "https://example.com/article?id=7", written)
+	monitor.checkClipboard()
+
+	onClipboard, err := monitor.IsItemOnClipboard(id)
+	require.NoError(t, err)
+	assert.True(t, onClipboard)
+
+	require.NoError(t, monitor.CopyItemOriginal(id))
+	assert.Equal(t, original, written)
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestCheckClipboardNormalizesText(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.NormalizeText = true
+	monitor.config.DedupWindow = 0
+
+	typographic := "print(\u201chello\u201d)\u00a0# it\u2019s\u200b fine"
+	clip := typographic
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	var written string
+	monitor.writeClipboard = func(s string) error { written = s; clip = s; return nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, `print("hello") # it's fine`, items[0].ContentText)
+	assert.Equal(t, typographic, items[0].OriginalText)
+	id := items[0].ID
+
+	// The plain spelling is the same content, not a new item
+	clip = "something else"
+	monitor.checkClipboard()
+	clip = `print("hello") # it's fine`
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{`print("hello") # it's fine`, "something else"}, itemContents(items))
+
+	// Neither form is captured again when copied back
+	require.NoError(t, monitor.CopyItemOriginal(id))
+	assert.Equal(t, typographic, written)
+	monitor.checkClipboard()
+	require.NoError(t, monitor.CopyItemToClipboard(id))
+	assert.Equal(t, `print("hello") # it's fine`, written)
+	monitor.checkClipboard()
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	// Text that needed no normalizing keeps no original
+	clip = "already plain"
+	monitor.checkClipboard()
+	items, err = db.GetClipboardItems(1, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "already plain", items[0].ContentText)
+	assert.Empty(t, items[0].OriginalText)
+}
+
+func TestCheckClipboardKeepsURLTrackingWhenDisabled(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	original := "https://example.com/?utm_campaign=spring"
+	monitor.readClipboard = func() (string, error) { return original, nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, original, items[0].ContentText)
+	assert.Empty(t, items[0].OriginalText)
+}
+
+func TestCheckClipboardDedupNormalization(t *testing.T) {
+	tests := []struct {
+		normalization string
+		expected      []string
+	}{
+		{config.DedupExact, []string{"Hello", "hello ", "HELLO", " Hello"}},
+		{config.DedupTrim, []string{"Hello", "hello ", "HELLO"}},
+		{config.DedupTrimLower, []string{"Hello"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.normalization, func(t *testing.T) {
+			monitor, db := setupTestClipboardMonitor(t)
+			monitor.config.DedupNormalization = test.normalization
+
+			clip := ""
+			monitor.readClipboard = func() (string, error) { return clip, nil }
+			for _, content := range []string{"Hello", "hello ", "HELLO", " Hello"} {
+				clip = content
+				monitor.checkClipboard()
+			}
+
+			items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+			require.NoError(t, err)
+			var contents []string
+			for _, item := range items {
+				contents = append(contents, item.ContentText)
+			}
+			// The first capture's content is what gets kept
+			assert.ElementsMatch(t, test.expected, contents)
+		})
+	}
+}
+
+func TestIsItemOnClipboard(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	clip := "copied earlier"
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	id := items[0].ID
+
+	onClipboard, err := monitor.IsItemOnClipboard(id)
+	require.NoError(t, err)
+	assert.True(t, onClipboard)
+
+	clip = "something else"
+	onClipboard, err = monitor.IsItemOnClipboard(id)
+	require.NoError(t, err)
+	assert.False(t, onClipboard)
+
+	// Checking must not capture what it read
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	_, err = monitor.IsItemOnClipboard("missing")
+	assert.Error(t, err)
+}
+
+func TestIsItemOnClipboardImage(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	clip := "data:image/png;base64," + onePixelPNG
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "image", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	id := items[0].ID
+
+	// Same pixels without the data URI prefix still match
+	clip = onePixelPNG
+	onClipboard, err := monitor.IsItemOnClipboard(id)
+	require.NoError(t, err)
+	assert.True(t, onClipboard)
+
+	clip = "not an image"
+	onClipboard, err = monitor.IsItemOnClipboard(id)
+	require.NoError(t, err)
+	assert.False(t, onClipboard)
+}
+
+func TestForgetCurrent(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	var writes []string
+	monitor.writeClipboard = func(s string) error {
+		writes = append(writes, s)
+		return nil
+	}
+
+	// Safe with no history; the clipboard is still cleared
+	require.NoError(t, monitor.ForgetCurrent())
+	assert.Equal(t, []string{""}, writes)
+
+	now := time.Now()
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "old", ContentType: "text", ContentText: "old", Hash: "forget-1", CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "new", ContentType: "text", ContentText: "new", Hash: "forget-2", CreatedAt: now.Add(-time.Minute)}))
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "pinned", ContentType: "text", ContentText: "pinned", Hash: "forget-3", CreatedAt: now, IsPinned: true}))
+
+	require.NoError(t, monitor.ForgetCurrent())
+	assert.Len(t, writes, 2)
+
+	// The newest unpinned item goes; pinned items are never forgotten
+	_, err := db.GetClipboardItemByID("new")
+	assert.Error(t, err)
+	_, err = db.GetClipboardItemByID("old")
+	assert.NoError(t, err)
+	_, err = db.GetClipboardItemByID("pinned")
+	assert.NoError(t, err)
+
+	// A clipboard failure is reported but the item is still deleted
+	monitor.writeClipboard = func(string) error { return errors.New("pasteboard unavailable") }
+	assert.Error(t, monitor.ForgetCurrent())
+	_, err = db.GetClipboardItemByID("old")
+	assert.Error(t, err)
+}
+
+func TestForgetCurrentClearsDedupWindow(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.DedupWindow = time.Minute
+	monitor.config.DedupNormalization = config.DedupTrimLower
+	monitor.writeClipboard = func(string) error { return nil }
+
+	// The window is keyed by the normalized content, not the exact hash
+	require.NotNil(t, monitor.capture("Secret ", false))
+	require.NoError(t, monitor.ForgetCurrent())
+
+	item := monitor.capture("secret", false)
+	require.NotNil(t, item)
+	assert.Equal(t, "secret", item.ContentText)
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secret"}, itemContents(items))
+}
+
+func TestPopAndPaste(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	var writes []string
+	monitor.writeClipboard = func(s string) error {
+		writes = append(writes, s)
+		return nil
+	}
+
+	now := time.Now()
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "oldest", ContentType: "text", ContentText: "oldest", Hash: "pop-1", CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "older", ContentType: "text", ContentText: "older", Hash: "pop-2", CreatedAt: now.Add(-2 * time.Minute)}))
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "newest", ContentType: "text", ContentText: "newest", Hash: "pop-3", CreatedAt: now.Add(-time.Minute)}))
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{ID: "pinned", ContentType: "text", ContentText: "pinned", Hash: "pop-4", CreatedAt: now, IsPinned: true}))
+
+	// Two pops paste and consume the two most recent unpinned items in order
+	first, err := monitor.PopAndPaste()
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, "newest", first.ID)
+
+	second, err := monitor.PopAndPaste()
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.Equal(t, "older", second.ID)
 
-	// Set environment variable to use test database path
-	originalHome := os.Getenv("HOME")
-	if err := os.Setenv("HOME", tempDir); err != nil {
-		t.Fatalf("Failed to set HOME: %v", err)
+	assert.Equal(t, []string{"newest", "older"}, writes)
+	remaining, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"oldest", "pinned"}, itemContents(remaining))
+
+	// A failed paste leaves the item in place
+	monitor.writeClipboard = func(string) error { return errors.New("pasteboard unavailable") }
+	_, err = monitor.PopAndPaste()
+	assert.Error(t, err)
+	_, err = db.GetClipboardItemByID("oldest")
+	assert.NoError(t, err)
+
+	// Pinned items are never popped
+	monitor.writeClipboard = func(string) error { return nil }
+	_, err = monitor.PopAndPaste()
+	require.NoError(t, err)
+	last, err := monitor.PopAndPaste()
+	require.NoError(t, err)
+	assert.Nil(t, last)
+}
+
+func TestPeekItem(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	accessed := time.Now().Add(-time.Hour).Truncate(time.Second)
+	pixels := encodeTestPNG(t, 300, 200)
+	items := []*models.ClipboardItem{
+		{ID: "text", ContentType: "text", ContentText: "peek at me", Hash: "peek-1", LastAccessed: accessed},
+		{ID: "image", ContentType: "image", ContentText: "data:image/png;base64,...", ContentBinary: pixels, Hash: "peek-2", LastAccessed: accessed},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
 	}
 
-	// Cleanup function
-	t.Cleanup(func() {
-		if err := os.Setenv("HOME", originalHome); err != nil {
-			t.Logf("Failed to restore HOME: %v", err)
-		}
-	})
+	written := false
+	monitor.writeClipboard = func(string) error { written = true; return nil }
 
-	db, err := database.New()
+	item, err := monitor.PeekItem("text")
 	require.NoError(t, err)
+	assert.Equal(t, "peek at me", item.ContentText)
 
-	cfg := &config.Config{
-		PollingInterval:   time.Millisecond * 100, // Fast polling for tests
-		MonitoringEnabled: true,
-		MaxItems:          10,
-		MaxDays:           1,
+	// Images without a stored thumbnail get one in the response only
+	item, err = monitor.PeekItem("image")
+	require.NoError(t, err)
+	assert.NotEmpty(t, item.Thumbnail)
+
+	for _, id := range []string{"text", "image"} {
+		stored, err := db.GetClipboardItemByID(id)
+		require.NoError(t, err)
+		assert.True(t, accessed.Equal(stored.LastAccessed), id)
+		assert.Zero(t, stored.UseCount, id)
 	}
+	stored, err := db.GetClipboardItemByID("image")
+	require.NoError(t, err)
+	assert.Empty(t, stored.Thumbnail)
+	assert.False(t, written)
 
-	monitor := NewClipboardMonitor(db, cfg)
+	_, err = monitor.PeekItem("missing")
+	assert.Error(t, err)
+}
 
-	t.Cleanup(func() {
-		monitor.Stop()
-		if err := db.Close(); err != nil {
-			t.Logf("Failed to close database: %v", err)
-		}
-	})
+func TestGetItemContent(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
 
-	return monitor, db
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "text", ContentType: "text", ContentText: "full text, not the preview", PreviewText: "full text", Hash: "content-1",
+	}))
+
+	content, err := monitor.GetItemContent("text")
+	require.NoError(t, err)
+	assert.Equal(t, "full text, not the preview", content)
+
+	data, err := monitor.GetItemContentBytes("text")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("full text, not the preview"), data)
+
+	_, err = monitor.GetItemContent("missing")
+	assert.Error(t, err)
+	_, err = monitor.GetItemContentBytes("missing")
+	assert.Error(t, err)
 }
 
-func TestNewClipboardMonitor(t *testing.T) {
+func TestGetItemContentImage(t *testing.T) {
 	monitor, db := setupTestClipboardMonitor(t)
 
-	assert.NotNil(t, monitor)
-	assert.NotNil(t, monitor.db)
-	assert.NotNil(t, monitor.config)
-	assert.NotNil(t, monitor.ctx)
-	assert.NotNil(t, monitor.cancel)
-	assert.False(t, monitor.isRunning)
+	monitor.readClipboard = func() (string, error) { return "data:image/png;base64," + onePixelPNG, nil }
+	monitor.checkClipboard()
 
-	// Cleanup
-	monitor.Stop()
-	if err := db.Close(); err != nil {
-		t.Logf("Failed to close database: %v", err)
-	}
+	items, err := db.GetClipboardItems(10, 0, "image", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	pixels, err := base64.StdEncoding.DecodeString(onePixelPNG)
+	require.NoError(t, err)
+
+	content, err := monitor.GetItemContent(items[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "data:image/png;base64,"+onePixelPNG, content)
+
+	data, err := monitor.GetItemContentBytes(items[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, pixels, data)
 }
 
-func TestClipboardMonitorStart(t *testing.T) {
+func TestLastAccessedVersusLastSeen(t *testing.T) {
 	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.DedupWindow = 0
 
-	// Test starting the monitor
-	err := monitor.Start()
-	assert.NoError(t, err)
-	assert.True(t, monitor.IsRunning())
+	clip := "tracked content"
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	monitor.writeClipboard = func(s string) error { clip = s; return nil }
+	monitor.checkClipboard()
 
-	// Test starting again (should return error)
-	err = monitor.Start()
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "already running")
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	original := items[0]
 
-	// Cleanup
-	monitor.Stop()
-	if err := db.Close(); err != nil {
-		t.Logf("Failed to close database: %v", err)
-	}
+	// Passively seeing it again only moves LastSeen
+	time.Sleep(5 * time.Millisecond)
+	clip = "something else"
+	monitor.checkClipboard()
+	clip = "tracked content"
+	monitor.checkClipboard()
+
+	seen, err := db.GetClipboardItemByID(original.ID)
+	require.NoError(t, err)
+	assert.True(t, seen.LastSeen.After(original.LastSeen))
+	assert.True(t, seen.LastAccessed.Equal(original.LastAccessed))
+
+	// Copying it back from history moves LastAccessed
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, monitor.CopyItemToClipboard(original.ID))
+
+	accessed, err := db.GetClipboardItemByID(original.ID)
+	require.NoError(t, err)
+	assert.True(t, accessed.LastAccessed.After(original.LastAccessed))
+	assert.True(t, accessed.LastSeen.Equal(seen.LastSeen))
 }
 
-func TestClipboardMonitorStop(t *testing.T) {
+func TestImportItems(t *testing.T) {
 	monitor, db := setupTestClipboardMonitor(t)
 
-	// Start the monitor
-	err := monitor.Start()
-	assert.NoError(t, err)
-	assert.True(t, monitor.IsRunning())
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "stored", ContentType: "text", ContentText: "already here", Hash: config.GenerateHash("already here"),
+	}))
 
-	// Stop the monitor
-	monitor.Stop()
+	path := filepath.Join(t.TempDir(), "history.json")
+	data := `[
+		{"content": "already here", "isPinned": true},
+		{"content": "{\"a\": 1}"},
+		{"content": ""}
+	]`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
 
-	// Give it a moment to stop
-	time.Sleep(time.Millisecond * 50)
-	assert.False(t, monitor.IsRunning())
+	count, err := monitor.ImportItems(path, "overwrite", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
 
-	// Test stopping again (should not panic)
-	monitor.Stop()
-	assert.False(t, monitor.IsRunning())
+	stored, err := db.GetClipboardItemByID("stored")
+	require.NoError(t, err)
+	assert.True(t, stored.IsPinned)
 
-	// Cleanup
-	if err := db.Close(); err != nil {
-		t.Logf("Failed to close database: %v", err)
-	}
+	items, err := db.GetClipboardItems(10, 0, "json", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, `{"a": 1}`, items[0].ContentText)
+
+	_, err = monitor.ImportItems(filepath.Join(t.TempDir(), "missing.json"), "skip", nil)
+	assert.Error(t, err)
 }
 
-func TestClipboardMonitorIsRunning(t *testing.T) {
+func TestImportItemsAppliesCaptureFilters(t *testing.T) {
 	monitor, db := setupTestClipboardMonitor(t)
+	monitor.cipher = newItemCipher(testKey())
+	monitor.config.MinContentLength = 3
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	data := `[
+		{"content": "VeryComplexP@ssw0rd!", "preview": "VeryComplexP@ssw0rd!"},
+		{"content": "ok"},
+		{"content": "plain notes", "encrypted": true}
+	]`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	// Passwords are skipped like on capture
+	count, err := monitor.ImportItems(path, "skip", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
 
-	// Initially not running
-	assert.False(t, monitor.IsRunning())
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "plain notes", items[0].ContentText)
+	assert.False(t, items[0].Encrypted)
 
-	// Start and check
-	err := monitor.Start()
-	assert.NoError(t, err)
-	assert.True(t, monitor.IsRunning())
+	// and encrypted when they're allowed
+	monitor.config.AllowPasswords = true
+	count, err = monitor.ImportItems(path, "skip", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
 
-	// Stop and check
-	monitor.Stop()
-	time.Sleep(time.Millisecond * 50)
-	assert.False(t, monitor.IsRunning())
+	results, err := db.SearchAllFields("P@ssw0rd", nil, 10, 0, database.SortCopied, false, false, false)
+	require.NoError(t, err)
+	assert.Empty(t, results)
 
-	// Cleanup
-	if err := db.Close(); err != nil {
-		t.Logf("Failed to close database: %v", err)
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	for _, item := range items {
+		if item.ContentText != "plain notes" {
+			assert.True(t, item.Encrypted)
+			assert.NotContains(t, item.ContentText, "P@ssw0rd")
+			assert.NotEqual(t, config.GenerateHash("VeryComplexP@ssw0rd!"), item.Hash)
+		}
 	}
 }
 
-func TestUpdateConfig(t *testing.T) {
+func TestSaveItemToFile(t *testing.T) {
 	monitor, db := setupTestClipboardMonitor(t)
+	dir := t.TempDir()
 
-	// Create new config
-	newConfig := &config.Config{
-		PollingInterval:   time.Second,
-		MonitoringEnabled: false,
-		MaxItems:          50,
-		MaxDays:           30,
+	pixels := encodeTestPNG(t, 4, 4)
+	items := []*models.ClipboardItem{
+		{ID: "text", ContentType: "text", ContentText: "a long note\nover two lines", Hash: "save-1"},
+		{ID: "json", ContentType: "json", ContentText: `{"a":1}`, Hash: "save-2", Language: "json"},
+		{ID: "image", ContentType: "image", ContentText: "data:image/png;base64,...", ContentBinary: pixels, Hash: "save-3"},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
 	}
 
-	// Update config
-	monitor.UpdateConfig(newConfig)
+	tests := []struct {
+		id       string
+		path     string
+		written  string
+		expected []byte
+	}{
+		{"text", "note", "note.txt", []byte("a long note\nover two lines")},
+		{"text", "named.md", "named.md", []byte("a long note\nover two lines")},
+		{"json", "data", "data.json", []byte(`{"a":1}`)},
+		{"image", "picture", "picture.png", pixels},
+	}
 
-	// Verify config was updated
-	assert.Equal(t, newConfig.PollingInterval, monitor.config.PollingInterval)
-	assert.Equal(t, newConfig.MonitoringEnabled, monitor.config.MonitoringEnabled)
-	assert.Equal(t, newConfig.MaxItems, monitor.config.MaxItems)
-	assert.Equal(t, newConfig.MaxDays, monitor.config.MaxDays)
+	for _, tc := range tests {
+		t.Run(tc.written, func(t *testing.T) {
+			require.NoError(t, monitor.SaveItemToFile(tc.id, filepath.Join(dir, tc.path)))
 
-	// Cleanup
-	monitor.Stop()
-	if err := db.Close(); err != nil {
-		t.Logf("Failed to close database: %v", err)
+			data, err := os.ReadFile(filepath.Join(dir, tc.written))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, data)
+		})
 	}
+
+	err := monitor.SaveItemToFile("text", filepath.Join(dir, "missing", "note.txt"))
+	assert.Error(t, err)
+
+	err = monitor.SaveItemToFile("missing", filepath.Join(dir, "note.txt"))
+	assert.Error(t, err)
 }
 
-func TestGenerateHash(t *testing.T) {
+func TestCheckClipboardNormalizesEncoding(t *testing.T) {
 	monitor, db := setupTestClipboardMonitor(t)
 
-	// Test hash generation
-	content1 := "Hello, World!"
-	content2 := "Hello, World!"
-	content3 := "Different content"
+	clipboardText := ""
+	monitor.readClipboard = func() (string, error) { return clipboardText, nil }
+	capture := func(content string) {
+		clipboardText = content
+		monitor.checkClipboard()
+	}
 
-	hash1 := monitor.generateHash(content1)
-	hash2 := monitor.generateHash(content2)
-	hash3 := monitor.generateHash(content3)
+	capture(utf16LEBOM + utf16Bytes("Report from Excel", false))
+	capture(utf8BOM + "BOM-prefixed note")
 
-	// Same content should produce same hash
-	assert.Equal(t, hash1, hash2)
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Report from Excel", "BOM-prefixed note"}, itemContents(items))
+	for _, item := range items {
+		assert.True(t, utf8.ValidString(item.ContentText))
+		assert.Equal(t, config.GenerateHash(item.ContentText), item.Hash)
+	}
 
-	// Different content should produce different hash
-	assert.NotEqual(t, hash1, hash3)
+	// The same text copied again as UTF-8 is recognized as a duplicate
+	capture("Report from Excel")
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+}
 
-	// Hash should not be empty
-	assert.NotEmpty(t, hash1)
-	assert.NotEmpty(t, hash3)
+func TestCheckClipboardTable(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
 
-	// Cleanup
-	monitor.Stop()
-	if err := db.Close(); err != nil {
-		t.Logf("Failed to close database: %v", err)
-	}
+	tsv := "Name\tQty\tPrice\tTotal\nApple\t3\t0.50\t1.50\nPear\t2\t0.75\t1.50\n"
+	monitor.readClipboard = func() (string, error) { return tsv, nil }
+	monitor.checkClipboard()
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "table", items[0].ContentType)
+	assert.Equal(t, 3, items[0].Rows)
+	assert.Equal(t, 4, items[0].Cols)
+	assert.Regexp(t, `^Table \(3 rows, 4 columns\)\nName\tQty`, items[0].PreviewText)
+	assert.Equal(t, tsv, items[0].ContentText)
 }
 
-func TestDetectContentType(t *testing.T) {
+func TestReclassifyItems(t *testing.T) {
 	monitor, db := setupTestClipboardMonitor(t)
 
-	tests := []struct {
-		content      string
-		expectedType string
-	}{
-		{"Hello, World!", "text"},
-		{"file:///Users/test/document.pdf", "file"},
-		{"http://example.com", "text"},
-		{"https://example.com", "text"},
-		{"C:\\Users\\test\\file.txt", "file"},
-		{"/Users/test/image.png", "image"},
-		{"", "text"},
-		{"Multi\nline\ntext", "text"},
-		{"/Users/test/image.jpg", "image"},
-		{"~/Downloads/photo.jpeg", "image"},
+	// Stored before color detection existed
+	items := []*models.ClipboardItem{
+		{ID: "color", ContentType: "text", ContentText: "#1E90FF", Hash: "reclassify-1"},
+		{ID: "plain", ContentType: "text", ContentText: "just words", Hash: "reclassify-2"},
+		{ID: "json", ContentType: "text", ContentText: `{"a":1}`, Hash: "reclassify-3"},
+		{ID: "table", ContentType: "text", ContentText: "a\tb\n1\t2\n3\t4", Hash: "reclassify-5"},
+		{ID: "image", ContentType: "image", ContentText: "#000000", ContentBinary: []byte("\x89PNG"), Hash: "reclassify-4"},
 	}
-
-	for _, test := range tests {
-		contentType := monitor.detectContentType(test.content)
-		assert.Equal(t, test.expectedType, contentType, "Content: %s", test.content)
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
 	}
 
-	// Cleanup
-	monitor.Stop()
-	if err := db.Close(); err != nil {
-		t.Logf("Failed to close database: %v", err)
-	}
+	updated, err := monitor.ReclassifyItems(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated)
+
+	table, err := db.GetClipboardItemByID("table")
+	require.NoError(t, err)
+	assert.Equal(t, "table", table.ContentType)
+	assert.Equal(t, 3, table.Rows)
+	assert.Equal(t, 2, table.Cols)
+
+	color, err := db.GetClipboardItemByID("color")
+	require.NoError(t, err)
+	assert.Equal(t, "color", color.ContentType)
+	assert.Equal(t, "#1e90ff", color.ColorHex)
+
+	jsonItem, err := db.GetClipboardItemByID("json")
+	require.NoError(t, err)
+	assert.Equal(t, "json", jsonItem.ContentType)
+	assert.Equal(t, "json", jsonItem.Language)
+
+	// Binary items keep their type
+	image, err := db.GetClipboardItemByID("image")
+	require.NoError(t, err)
+	assert.Equal(t, "image", image.ContentType)
+
+	// Running again finds nothing left to change
+	updated, err = monitor.ReclassifyItems(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated)
 }
 
 func TestConfigUtilities(t *testing.T) {
@@ -406,11 +2280,11 @@ func TestRunCleanup(t *testing.T) {
 
 	// Manually trigger cleanup using the database method directly
 	// Since the monitor's cleanup runs on a timer, we test the cleanup functionality directly
-	err = db.CleanupOldItems(10, 1) // Max 10 items, older than 1 day
+	_, err = db.CleanupOldItems(10, 1) // Max 10 items, older than 1 day
 	assert.NoError(t, err)
 
 	// Verify cleanup happened (old item should be removed)
-	items, err := db.GetClipboardItems(10, 0, "", "copied")
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
 	assert.NoError(t, err)
 
 	// Should have only the recent item
@@ -469,3 +2343,214 @@ func TestMonitorWithContext(t *testing.T) {
 		t.Logf("Failed to close database: %v", err)
 	}
 }
+
+func TestDeleteAndReturnNext(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	now := time.Now()
+	for i, id := range []string{"oldest", "middle", "newest"} {
+		require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+			ID: id, ContentType: "text", ContentText: id, Hash: "next-" + id,
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "pinned", ContentType: "text", ContentText: "pinned", Hash: "next-pinned",
+		IsPinned: true, CreatedAt: now.Add(-time.Hour),
+	}))
+
+	// Listed as pinned, newest, middle, oldest
+	next, err := monitor.DeleteAndReturnNext("newest", database.SortCopied, false)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "middle", next.ID)
+
+	// Deleting the last item selects the one before it
+	next, err = monitor.DeleteAndReturnNext("oldest", database.SortCopied, false)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "middle", next.ID)
+
+	next, err = monitor.DeleteAndReturnNext("pinned", database.SortCopied, false)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "middle", next.ID)
+
+	next, err = monitor.DeleteAndReturnNext("middle", database.SortCopied, false)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	_, err = db.GetClipboardItemByID("middle")
+	assert.Error(t, err)
+
+	_, err = monitor.DeleteAndReturnNext("missing", database.SortCopied, false)
+	assert.Error(t, err)
+}
+
+func TestRecopyPinnedItemPromotion(t *testing.T) {
+	for _, promote := range []bool{false, true} {
+		t.Run(fmt.Sprintf("promote=%v", promote), func(t *testing.T) {
+			monitor, db := setupTestClipboardMonitor(t)
+			monitor.config.PromotePinnedOnRecopy = promote
+
+			now := time.Now()
+			for i, content := range []string{"alpha", "beta"} {
+				require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+					ID: content, ContentType: "text", ContentText: content, IsPinned: true,
+					Hash: config.GenerateHash(content), DedupHash: config.GenerateHash(monitor.config.DedupKey(content)),
+					CreatedAt: now.Add(time.Duration(i-2) * time.Hour), LastAccessed: now.Add(time.Duration(i-2) * time.Hour),
+				}))
+			}
+
+			monitor.readClipboard = func() (string, error) { return "alpha", nil }
+			monitor.checkClipboard()
+
+			items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+			require.NoError(t, err)
+			if promote {
+				assert.Equal(t, []string{"alpha", "beta"}, itemIDs(items))
+			} else {
+				assert.Equal(t, []string{"beta", "alpha"}, itemIDs(items))
+			}
+			for _, item := range items {
+				assert.True(t, item.IsPinned)
+			}
+		})
+	}
+}
+
+func TestManualCaptureMode(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.ManualCaptureMode = true
+	monitor.config.CaptureExistingOnStart = true
+	monitor.config.PollingInterval = 5 * time.Millisecond
+
+	var mu sync.Mutex
+	clip := "already copied"
+	reads := 0
+	monitor.readClipboard = func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reads++
+		return clip, nil
+	}
+
+	require.NoError(t, monitor.Start())
+	mu.Lock()
+	clip = "copied while running"
+	mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+
+	// Neither the start-up capture nor polling read the clipboard
+	mu.Lock()
+	assert.Zero(t, reads)
+	mu.Unlock()
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	item, err := monitor.CaptureNow()
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, "copied while running", item.ContentText)
+
+	// Asking again for the same content bumps the stored item
+	again, err := monitor.CaptureNow()
+	require.NoError(t, err)
+	require.NotNil(t, again)
+	assert.Equal(t, item.ID, again.ID)
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"copied while running"}, itemContents(items))
+
+	// Filtered content is reported as nothing captured
+	monitor.config.MinContentLength = 100
+	skipped, err := monitor.CaptureNow()
+	require.NoError(t, err)
+	assert.Nil(t, skipped)
+
+	monitor.readClipboard = func() (string, error) { return "", errors.New("clipboard unavailable") }
+	_, err = monitor.CaptureNow()
+	assert.Error(t, err)
+}
+
+func TestCaptureNowSkipsRecaptureGuards(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.DedupWindow = time.Minute
+	monitor.config.IgnoreSelfWrites = true
+
+	clip := "copied twice"
+	monitor.readClipboard = func() (string, error) { return clip, nil }
+	monitor.writeClipboard = func(s string) error { clip = s; return nil }
+
+	// Inside the dedup window a poll ignores the repeat, an explicit
+	// capture doesn't
+	monitor.checkClipboard()
+	monitor.lastHash = ""
+	monitor.checkClipboard()
+	item, err := monitor.CaptureNow()
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, "copied twice", item.ContentText)
+
+	// Deleting the item while it's on the clipboard keeps polls from
+	// bringing it back, but not a capture the user asked for
+	require.NoError(t, monitor.DeleteItem(item.ID))
+	monitor.lastHash = ""
+	monitor.checkClipboard()
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	item, err = monitor.CaptureNow()
+	require.NoError(t, err)
+	require.NotNil(t, item)
+
+	// Klipd's own write to the clipboard can be captured on request too
+	require.NoError(t, monitor.writeOwn("written by klipd"))
+	monitor.lastHash = ""
+	assert.Nil(t, monitor.capture(clip, false))
+	item, err = monitor.CaptureNow()
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, "written by klipd", item.ContentText)
+
+	items, err = db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"written by klipd", "copied twice"}, itemContents(items))
+}
+
+func TestCaptureNowDuringPolling(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.DedupWindow = time.Minute
+
+	var mu sync.Mutex
+	n := 0
+	monitor.readClipboard = func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return fmt.Sprintf("copy %d", n), nil
+	}
+
+	// A hotkey capture can land in the middle of a poll; run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			monitor.checkClipboard()
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := monitor.CaptureNow()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	items, err := db.GetClipboardItems(100, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 40)
+}
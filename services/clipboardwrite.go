@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// errFlavorUnsupported is returned by writeClipboardImage and
+// writeClipboardFiles where the platform can only put text on the clipboard
+var errFlavorUnsupported = errors.New("clipboard flavor not supported on this platform")
+
+// writeItem puts an item's content on the clipboard in the flavor paste
+// targets expect for its type: image data for images that have their bytes,
+// file references for files, and text for everything else. If the platform
+// can't write the richer flavor the text is written instead, as before.
+func (cm *ClipboardMonitor) writeItem(contentType string, content string, binary []byte) error {
+	var err error
+	switch {
+	case contentType == "image" && len(binary) > 0:
+		err = cm.writeImage(binary)
+	case contentType == "file":
+		paths := filePaths(content)
+		if len(paths) == 0 {
+			return cm.writeClipboard(content)
+		}
+		err = cm.writeFiles(paths)
+	default:
+		return cm.writeClipboard(content)
+	}
+
+	if errors.Is(err, errFlavorUnsupported) {
+		slog.Debug("Writing item as text", "type", contentType)
+		return cm.writeClipboard(content)
+	}
+	return err
+}
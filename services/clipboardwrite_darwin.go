@@ -0,0 +1,66 @@
+//go:build darwin
+
+package services
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework AppKit
+#include <stdlib.h>
+#import <AppKit/AppKit.h>
+
+static int writeImage(const void *bytes, int length) {
+	@autoreleasepool {
+		NSData *data = [NSData dataWithBytes:bytes length:length];
+		NSImage *image = [[NSImage alloc] initWithData:data];
+		if (image == nil) {
+			return 0;
+		}
+		NSPasteboard *pasteboard = [NSPasteboard generalPasteboard];
+		[pasteboard clearContents];
+		BOOL ok = [pasteboard writeObjects:@[image]];
+		[image release];
+		return ok ? 1 : 0;
+	}
+}
+
+static int writeFiles(char **paths, int count) {
+	@autoreleasepool {
+		NSMutableArray *urls = [NSMutableArray arrayWithCapacity:count];
+		for (int i = 0; i < count; i++) {
+			[urls addObject:[NSURL fileURLWithPath:[NSString stringWithUTF8String:paths[i]]]];
+		}
+		NSPasteboard *pasteboard = [NSPasteboard generalPasteboard];
+		[pasteboard clearContents];
+		return [pasteboard writeObjects:urls] ? 1 : 0;
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+func writeClipboardImage(data []byte) error {
+	bytes := C.CBytes(data)
+	defer C.free(bytes)
+
+	if C.writeImage(bytes, C.int(len(data))) == 0 {
+		return errors.New("failed to write image to the clipboard")
+	}
+	return nil
+}
+
+func writeClipboardFiles(paths []string) error {
+	cPaths := make([]*C.char, len(paths))
+	for i, path := range paths {
+		cPaths[i] = C.CString(path)
+		defer C.free(unsafe.Pointer(cPaths[i]))
+	}
+
+	if C.writeFiles(&cPaths[0], C.int(len(paths))) == 0 {
+		return errors.New("failed to write files to the clipboard")
+	}
+	return nil
+}
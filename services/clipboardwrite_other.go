@@ -0,0 +1,11 @@
+//go:build !darwin
+
+package services
+
+func writeClipboardImage(data []byte) error {
+	return errFlavorUnsupported
+}
+
+func writeClipboardFiles(paths []string) error {
+	return errFlavorUnsupported
+}
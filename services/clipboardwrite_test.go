@@ -0,0 +1,81 @@
+package services
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"klipd/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clipboardWrites records what was written to the clipboard, by flavor
+type clipboardWrites struct {
+	text   []string
+	images [][]byte
+	files  [][]string
+}
+
+func recordClipboardWrites(monitor *ClipboardMonitor) *clipboardWrites {
+	w := &clipboardWrites{}
+	monitor.writeClipboard = func(s string) error { w.text = append(w.text, s); return nil }
+	monitor.writeImage = func(data []byte) error { w.images = append(w.images, data); return nil }
+	monitor.writeFiles = func(paths []string) error { w.files = append(w.files, paths); return nil }
+	return w
+}
+
+func TestCopyItemWritesFlavorPerType(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	pngData := encodeTestPNG(t, 4, 4)
+	items := []*models.ClipboardItem{
+		{ID: "text", ContentType: "text", ContentText: "plain words", Hash: "flavor-text"},
+		{ID: "image", ContentType: "image", ContentText: "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData),
+			ContentBinary: pngData, Hash: "flavor-image"},
+		{ID: "image-path", ContentType: "image", ContentText: "/tmp/picture.png", Hash: "flavor-image-path"},
+		{ID: "file", ContentType: "file", ContentText: "/tmp/a.txt\nfile:///tmp/b%20c.txt", Hash: "flavor-file"},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	t.Run("text", func(t *testing.T) {
+		w := recordClipboardWrites(monitor)
+		require.NoError(t, monitor.CopyItemToClipboard("text"))
+		assert.Equal(t, []string{"plain words"}, w.text)
+		assert.Empty(t, w.images)
+		assert.Empty(t, w.files)
+	})
+
+	t.Run("image with data", func(t *testing.T) {
+		w := recordClipboardWrites(monitor)
+		require.NoError(t, monitor.CopyItemToClipboard("image"))
+		assert.Equal(t, [][]byte{pngData}, w.images)
+		assert.Empty(t, w.text)
+	})
+
+	t.Run("image without data", func(t *testing.T) {
+		w := recordClipboardWrites(monitor)
+		require.NoError(t, monitor.CopyItemToClipboard("image-path"))
+		assert.Equal(t, []string{"/tmp/picture.png"}, w.text)
+		assert.Empty(t, w.images)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		w := recordClipboardWrites(monitor)
+		require.NoError(t, monitor.CopyItemToClipboard("file"))
+		assert.Equal(t, [][]string{{"/tmp/a.txt", "/tmp/b c.txt"}}, w.files)
+		assert.Empty(t, w.text)
+	})
+
+	t.Run("unsupported flavor falls back to text", func(t *testing.T) {
+		w := recordClipboardWrites(monitor)
+		monitor.writeImage = func([]byte) error { return errFlavorUnsupported }
+		monitor.writeFiles = func([]string) error { return errFlavorUnsupported }
+
+		require.NoError(t, monitor.CopyItemToClipboard("image"))
+		require.NoError(t, monitor.CopyItemToClipboard("file"))
+		assert.Equal(t, []string{items[1].ContentText, items[3].ContentText}, w.text)
+	})
+}
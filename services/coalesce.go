@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"klipd/models"
+)
+
+// itemEventCoalescer batches item-added notifications so a burst of captures
+// reaches the frontend as a single "items-changed" event
+type itemEventCoalescer struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	emit    func(models.ItemsChanged)
+	pending models.ItemsChanged
+	timer   *time.Timer
+}
+
+// newItemEventCoalescer returns a coalescer that drops anything still pending
+// once ctx is cancelled
+func newItemEventCoalescer(ctx context.Context, emit func(models.ItemsChanged)) *itemEventCoalescer {
+	ec := &itemEventCoalescer{ctx: ctx, emit: emit}
+	context.AfterFunc(ctx, func() {
+		ec.mu.Lock()
+		defer ec.mu.Unlock()
+		if ec.timer != nil {
+			ec.timer.Stop()
+			ec.timer = nil
+		}
+		ec.pending = models.ItemsChanged{}
+	})
+	return ec
+}
+
+// add records a new item. The first add in a quiet period starts the window;
+// everything added before it ends goes out together. A window of 0 emits
+// right away.
+func (ec *itemEventCoalescer) add(item *models.ClipboardItem, window time.Duration) {
+	ec.mu.Lock()
+	if ec.ctx.Err() != nil {
+		ec.mu.Unlock()
+		return
+	}
+
+	meta := item.Meta()
+	ec.pending.Count++
+	ec.pending.Latest = meta
+	ec.pending.Added = append(ec.pending.Added, meta)
+
+	if window <= 0 {
+		ec.mu.Unlock()
+		ec.flush()
+		return
+	}
+	if ec.timer == nil {
+		ec.timer = time.AfterFunc(window, ec.flush)
+	}
+	ec.mu.Unlock()
+}
+
+func (ec *itemEventCoalescer) flush() {
+	ec.mu.Lock()
+	event := ec.pending
+	ec.pending = models.ItemsChanged{}
+	ec.timer = nil
+	ec.mu.Unlock()
+
+	if event.Count == 0 || ec.ctx.Err() != nil {
+		return
+	}
+	ec.emit(event)
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hexColorRegex matches CSS-style #RGB, #RRGGBB and #RRGGBBAA colors
+var hexColorRegex = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// detectColorHex returns content as a normalized lowercase hex color, with
+// shorthand #RGB expanded, or "" if content isn't a single hex color
+func detectColorHex(content string) string {
+	content = strings.TrimSpace(content)
+	if !hexColorRegex.MatchString(content) {
+		return ""
+	}
+
+	hex := strings.ToLower(content[1:])
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	return "#" + hex
+}
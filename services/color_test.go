@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectColorHex(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"#ff8800", "#ff8800"},
+		{"#FF8800", "#ff8800"},
+		{"#f80", "#ff8800"},
+		{" #1e90ff\n", "#1e90ff"},
+		{"#ff880080", "#ff880080"},
+		{"#ff88", ""},
+		{"ff8800", ""},
+		{"#gg0000", ""},
+		{"background: #ff8800", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, detectColorHex(test.content), "Content: %q", test.content)
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"klipd/models"
+)
+
+// compressThreshold is the text size above which CompressLargeContent
+// gzips an item's content
+const compressThreshold = 16 * 1024
+
+// compressItem gzips the text of a large item into ContentBinary in place.
+// Images, encrypted items and text that doesn't shrink are left as they are.
+func compressItem(item *models.ClipboardItem) error {
+	if len(item.ContentText) <= compressThreshold || len(item.ContentBinary) > 0 ||
+		item.ContentType == "image" || item.Encrypted {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(item.ContentText)); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if buf.Len() >= len(item.ContentText) {
+		return nil
+	}
+
+	item.ContentBinary = buf.Bytes()
+	item.ContentText = ""
+	item.Compressed = true
+	item.SizeBytes = item.ContentSize()
+	return nil
+}
+
+// decompressItem reverses compressItem in place; other items are left as is.
+// Like Encrypted, Compressed keeps describing the stored row, so a
+// decompressed item must never be saved back.
+func decompressItem(item *models.ClipboardItem) error {
+	if !item.Compressed {
+		return nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(item.ContentBinary))
+	if err != nil {
+		return fmt.Errorf("invalid compressed content: %w", err)
+	}
+	defer zr.Close()
+
+	text, err := io.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("failed to decompress item: %w", err)
+	}
+
+	item.ContentText = string(text)
+	item.ContentBinary = nil
+	return nil
+}
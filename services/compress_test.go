@@ -0,0 +1,96 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"klipd/database"
+	"klipd/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressItemRoundTrip(t *testing.T) {
+	content := strings.Repeat("2024-01-01 INFO request handled in 12ms\n", 1000)
+	item := &models.ClipboardItem{ContentType: "text", ContentText: content, PreviewText: "2024-01-01 INFO"}
+
+	require.NoError(t, compressItem(item))
+	assert.True(t, item.Compressed)
+	assert.Empty(t, item.ContentText)
+	assert.Less(t, len(item.ContentBinary), len(content))
+	assert.Equal(t, len(item.ContentBinary), item.SizeBytes)
+	assert.Equal(t, "2024-01-01 INFO", item.PreviewText)
+
+	require.NoError(t, decompressItem(item))
+	assert.Equal(t, content, item.ContentText)
+	assert.Empty(t, item.ContentBinary)
+}
+
+func TestCompressItemSkips(t *testing.T) {
+	small := &models.ClipboardItem{ContentType: "text", ContentText: "short note"}
+	require.NoError(t, compressItem(small))
+	assert.False(t, small.Compressed)
+	assert.Equal(t, "short note", small.ContentText)
+
+	large := strings.Repeat("x", compressThreshold+1)
+	image := &models.ClipboardItem{ContentType: "image", ContentText: large, ContentBinary: []byte{1}}
+	require.NoError(t, compressItem(image))
+	assert.False(t, image.Compressed)
+
+	encrypted := &models.ClipboardItem{ContentType: "text", ContentText: large, Encrypted: true}
+	require.NoError(t, compressItem(encrypted))
+	assert.False(t, encrypted.Compressed)
+
+	// Uncompressed items come back unchanged
+	require.NoError(t, decompressItem(small))
+	assert.Equal(t, "short note", small.ContentText)
+}
+
+func TestCheckClipboardCompressesLargeContent(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.CompressLargeContent = true
+
+	large := strings.Repeat("SELECT * FROM users WHERE id = 1;\n", 1000)
+	for _, content := range []string{large, "small item"} {
+		monitor.readClipboard = func() (string, error) { return content, nil }
+		monitor.checkClipboard()
+	}
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	var stored, small models.ClipboardItem
+	for _, item := range items {
+		if item.Compressed {
+			stored = item
+		} else {
+			small = item
+		}
+	}
+	require.NotEmpty(t, stored.ID, "the large item should be stored compressed")
+	assert.Empty(t, stored.ContentText)
+	assert.Equal(t, "small item", small.ContentText)
+
+	// Search still sees the plain preview
+	results, err := db.SearchClipboardItems("FROM users", 10, 0, database.SortCopied, false)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, stored.ID, results[0].ID)
+
+	item, err := monitor.GetItemByID(stored.ID)
+	require.NoError(t, err)
+	assert.Equal(t, large, item.ContentText)
+
+	var written string
+	monitor.writeClipboard = func(s string) error { written = s; return nil }
+	require.NoError(t, monitor.CopyItemToClipboard(stored.ID))
+	assert.Equal(t, large, written)
+
+	// Copying saves the item back without undoing the compression
+	again, err := db.GetClipboardItemByID(stored.ID)
+	require.NoError(t, err)
+	assert.True(t, again.Compressed)
+	assert.Empty(t, again.ContentText)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// maxDiffBytes caps the content size DiffItems will compare; the diff is
+// quadratic in the worst case and runs on the UI's request path
+const maxDiffBytes = 256 * 1024
+
+// DiffItems returns a unified diff from the content of item idA to that of
+// item idB, or "" if they are identical. Image items and items larger than
+// maxDiffBytes can't be compared.
+func (cm *ClipboardMonitor) DiffItems(idA, idB string) (string, error) {
+	a, err := cm.diffableContent(idA)
+	if err != nil {
+		return "", err
+	}
+	b, err := cm.diffableContent(idB)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(a),
+		B:        diffLines(b),
+		FromFile: idA,
+		ToFile:   idB,
+		Context:  3,
+	})
+}
+
+// diffLines splits s into newline-terminated lines. Unlike
+// difflib.SplitLines it adds no phantom empty line after a final newline.
+func diffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+	return lines
+}
+
+func (cm *ClipboardMonitor) diffableContent(id string) (string, error) {
+	item, err := cm.GetItemByID(id)
+	if err != nil {
+		return "", err
+	}
+	if item.ContentType == "image" || len(item.ContentBinary) > 0 {
+		return "", fmt.Errorf("item %s is not text", id)
+	}
+	if len(item.ContentText) > maxDiffBytes {
+		return "", fmt.Errorf("item %s is too large to diff (%d bytes, limit %d)", id, len(item.ContentText), maxDiffBytes)
+	}
+	return item.ContentText, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"klipd/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffItems(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	items := []*models.ClipboardItem{
+		{ID: "base", ContentType: "text", ContentText: "alpha\nbeta\ngamma\n", Hash: "diff-1"},
+		{ID: "same", ContentType: "text", ContentText: "alpha\nbeta\ngamma\n", Hash: "diff-2"},
+		{ID: "added", ContentType: "text", ContentText: "alpha\nbeta\nbeta two\ngamma\n", Hash: "diff-3"},
+		{ID: "removed", ContentType: "text", ContentText: "alpha\ngamma\n", Hash: "diff-4"},
+		{ID: "image", ContentType: "image", ContentText: "data:image/png;base64,...", ContentBinary: []byte{0x89, 'P', 'N', 'G'}, Hash: "diff-5"},
+		{ID: "huge", ContentType: "text", ContentText: strings.Repeat("x", maxDiffBytes+1), Hash: "diff-6"},
+	}
+	for _, item := range items {
+		require.NoError(t, db.CreateClipboardItem(item))
+	}
+
+	diff, err := monitor.DiffItems("base", "same")
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	diff, err = monitor.DiffItems("base", "added")
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- base\n+++ added\n")
+	assert.Contains(t, diff, "@@ -1,3 +1,4 @@")
+	assert.Contains(t, diff, "\n+beta two\n")
+	assert.NotContains(t, diff, "\n-")
+
+	diff, err = monitor.DiffItems("base", "removed")
+	require.NoError(t, err)
+	assert.Contains(t, diff, "@@ -1,3 +1,2 @@")
+	assert.Contains(t, diff, "\n-beta\n")
+	assert.NotContains(t, diff, "\n+alpha")
+
+	_, err = monitor.DiffItems("base", "image")
+	assert.Error(t, err)
+
+	_, err = monitor.DiffItems("huge", "base")
+	assert.Error(t, err)
+
+	_, err = monitor.DiffItems("base", "missing")
+	assert.Error(t, err)
+}
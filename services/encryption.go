@@ -0,0 +1,148 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"klipd/models"
+)
+
+// sensitivePreview replaces the preview of encrypted items, since previews
+// are stored in plaintext and searched
+const sensitivePreview = "•••••••• (sensitive)"
+
+// itemCipher encrypts item content with AES-GCM. The key is fetched from the
+// KeyProvider on first use so the keychain is only touched when needed.
+type itemCipher struct {
+	keys KeyProvider
+	mu   sync.Mutex
+	key  []byte
+	aead cipher.AEAD
+}
+
+func newItemCipher(keys KeyProvider) *itemCipher {
+	return &itemCipher{keys: keys}
+}
+
+// init loads the key and sets up the cipher. Only success is kept, so a
+// keychain that was locked or denied access is asked again on the next call.
+func (ic *itemCipher) init() (cipher.AEAD, []byte, error) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	if ic.aead != nil {
+		return ic.aead, ic.key, nil
+	}
+
+	key, err := ic.keys.EncryptionKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ic.aead, ic.key = aead, key
+	return aead, key, nil
+}
+
+// keyedHash returns the HMAC-SHA256 of content under the item key. Hashes
+// stored next to encrypted content use it, since a plain SHA-256 would let
+// anyone holding the database check guesses at the plaintext.
+func (ic *itemCipher) keyedHash(content string) (string, error) {
+	_, key, err := ic.init()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(content))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func (ic *itemCipher) seal(plaintext []byte) ([]byte, error) {
+	aead, _, err := ic.init()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (ic *itemCipher) open(sealed []byte) ([]byte, error) {
+	aead, _, err := ic.init()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted content is truncated")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// encryptItem encrypts the content of item in place and masks its preview.
+// ContentText holds base64 so it stays a valid string column.
+func (ic *itemCipher) encryptItem(item *models.ClipboardItem) error {
+	text, err := ic.seal([]byte(item.ContentText))
+	if err != nil {
+		return err
+	}
+
+	var binary []byte
+	if len(item.ContentBinary) > 0 {
+		if binary, err = ic.seal(item.ContentBinary); err != nil {
+			return err
+		}
+	}
+
+	item.ContentText = base64.StdEncoding.EncodeToString(text)
+	item.ContentBinary = binary
+	item.PreviewText = sensitivePreview
+	item.Thumbnail = nil
+	item.Encrypted = true
+	return nil
+}
+
+// decryptItem reverses encryptItem in place; plaintext items are left as is.
+// Encrypted keeps describing the stored row, so a decrypted item must never be
+// saved back.
+func (ic *itemCipher) decryptItem(item *models.ClipboardItem) error {
+	if !item.Encrypted {
+		return nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(item.ContentText)
+	if err != nil {
+		return fmt.Errorf("invalid encrypted content: %w", err)
+	}
+	text, err := ic.open(sealed)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt item: %w", err)
+	}
+
+	var binary []byte
+	if len(item.ContentBinary) > 0 {
+		if binary, err = ic.open(item.ContentBinary); err != nil {
+			return fmt.Errorf("failed to decrypt item: %w", err)
+		}
+	}
+
+	item.ContentText = string(text)
+	item.ContentBinary = binary
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"klipd/config"
+	"klipd/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticKey is a KeyProvider for tests
+type staticKey []byte
+
+func (k staticKey) EncryptionKey() ([]byte, error) {
+	return k, nil
+}
+
+// missingKey is a KeyProvider that never has a key, like a platform without a keychain
+type missingKey struct{}
+
+func (missingKey) EncryptionKey() ([]byte, error) {
+	return nil, errors.New("no key")
+}
+
+func testKey() staticKey {
+	return staticKey(bytes.Repeat([]byte{7}, 32))
+}
+
+func TestItemCipherRoundTrip(t *testing.T) {
+	ic := newItemCipher(testKey())
+
+	item := &models.ClipboardItem{
+		ContentText:   "Tr0ub4dor&3",
+		ContentBinary: []byte{1, 2, 3},
+		PreviewText:   "Tr0ub4dor&3",
+		Thumbnail:     []byte{9},
+	}
+	require.NoError(t, ic.encryptItem(item))
+
+	assert.True(t, item.Encrypted)
+	assert.NotContains(t, item.ContentText, "Tr0ub4dor&3")
+	assert.NotEqual(t, []byte{1, 2, 3}, item.ContentBinary)
+	assert.Equal(t, sensitivePreview, item.PreviewText)
+	assert.Nil(t, item.Thumbnail)
+
+	require.NoError(t, ic.decryptItem(item))
+	assert.Equal(t, "Tr0ub4dor&3", item.ContentText)
+	assert.Equal(t, []byte{1, 2, 3}, item.ContentBinary)
+}
+
+func TestItemCipherWrongKey(t *testing.T) {
+	item := &models.ClipboardItem{ContentText: "Tr0ub4dor&3"}
+	require.NoError(t, newItemCipher(testKey()).encryptItem(item))
+
+	other := newItemCipher(staticKey(bytes.Repeat([]byte{8}, 32)))
+	assert.Error(t, other.decryptItem(item))
+}
+
+func TestItemCipherKeyedHash(t *testing.T) {
+	ic := newItemCipher(testKey())
+
+	hash, err := ic.keyedHash("Tr0ub4dor&3")
+	require.NoError(t, err)
+	again, err := ic.keyedHash("Tr0ub4dor&3")
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+	assert.NotEqual(t, config.GenerateHash("Tr0ub4dor&3"), hash)
+
+	other, err := newItemCipher(staticKey(bytes.Repeat([]byte{8}, 32))).keyedHash("Tr0ub4dor&3")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	_, err = newItemCipher(missingKey{}).keyedHash("Tr0ub4dor&3")
+	assert.Error(t, err)
+}
+
+// flakyKey fails until it has been asked failures times, like a keychain the
+// user denied access to once
+type flakyKey struct {
+	failures int
+	calls    int
+}
+
+func (k *flakyKey) EncryptionKey() ([]byte, error) {
+	k.calls++
+	if k.calls <= k.failures {
+		return nil, errors.New("keychain locked")
+	}
+	return testKey(), nil
+}
+
+func TestItemCipherRetriesKeyAfterFailure(t *testing.T) {
+	keys := &flakyKey{failures: 1}
+	ic := newItemCipher(keys)
+
+	item := &models.ClipboardItem{ContentText: "Tr0ub4dor&3"}
+	assert.Error(t, ic.encryptItem(item))
+	assert.False(t, item.Encrypted)
+
+	require.NoError(t, ic.encryptItem(item))
+	assert.True(t, item.Encrypted)
+
+	// Once loaded the key is kept
+	require.NoError(t, ic.decryptItem(item))
+	assert.Equal(t, 2, keys.calls)
+}
+
+func TestItemCipherPlaintextUntouched(t *testing.T) {
+	// Plaintext items never need the key
+	ic := newItemCipher(missingKey{})
+	item := &models.ClipboardItem{ContentText: "hello"}
+
+	require.NoError(t, ic.decryptItem(item))
+	assert.Equal(t, "hello", item.ContentText)
+
+	assert.Error(t, ic.encryptItem(item))
+}
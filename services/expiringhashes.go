@@ -0,0 +1,70 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// deletedHashTTL is how long the content of a deleted item is kept from
+	// being captured again while it stays on the clipboard
+	deletedHashTTL = time.Minute
+
+	// selfWriteTTL is how long the content Klipd last put on the clipboard is
+	// still recognized as its own when it shows up again
+	selfWriteTTL = 5 * time.Minute
+)
+
+// expiringHashes is a set of content hashes whose entries lapse after a TTL.
+// The monitor keeps two: the content of items deleted while on the clipboard,
+// so the next poll doesn't bring them straight back, and the content Klipd
+// last wrote itself, so another clipboard manager putting it back isn't a
+// new copy.
+type expiringHashes struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	hashes map[string]time.Time
+}
+
+func newExpiringHashes(ttl time.Duration) *expiringHashes {
+	return &expiringHashes{ttl: ttl, hashes: make(map[string]time.Time)}
+}
+
+// add records hash as of now, dropping entries that have expired so the set
+// stays small
+func (eh *expiringHashes) add(hash string) {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+
+	now := time.Now()
+	for h, addedAt := range eh.hashes {
+		if now.Sub(addedAt) >= eh.ttl {
+			delete(eh.hashes, h)
+		}
+	}
+	eh.hashes[hash] = now
+}
+
+// contains reports whether hash was added within the TTL
+func (eh *expiringHashes) contains(hash string) bool {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+
+	addedAt, ok := eh.hashes[hash]
+	return ok && time.Since(addedAt) < eh.ttl
+}
+
+// replace forgets every entry and records hash as of now
+func (eh *expiringHashes) replace(hash string) {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+	clear(eh.hashes)
+	eh.hashes[hash] = time.Now()
+}
+
+// clear forgets every entry
+func (eh *expiringHashes) clear() {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+	clear(eh.hashes)
+}
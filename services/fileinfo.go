@@ -0,0 +1,79 @@
+package services
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileStatTimeout bounds how long a capture waits on paths that live on slow
+// or unreachable network mounts
+const fileStatTimeout = 500 * time.Millisecond
+
+// filePaths turns the content of a file item into local paths, one per
+// non-empty line, resolving file:// URLs and ~/
+func filePaths(content string) []string {
+	var paths []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "file://") {
+			u, err := url.Parse(line)
+			if err != nil {
+				continue
+			}
+			line = u.Path
+		} else if strings.HasPrefix(line, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				continue
+			}
+			line = filepath.Join(home, line[2:])
+		}
+		paths = append(paths, line)
+	}
+	return paths
+}
+
+// statFiles returns the combined size of the regular files among paths and
+// whether every path exists. Paths still being looked up when timeout runs
+// out count as missing.
+func statFiles(paths []string, timeout time.Duration) (size int64, exists bool) {
+	if len(paths) == 0 {
+		return 0, false
+	}
+
+	type result struct {
+		size   int64
+		exists bool
+	}
+	// Buffered so a stat that outlives the timeout doesn't block forever
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		r.exists = true
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				r.exists = false
+				continue
+			}
+			if info.Mode().IsRegular() {
+				r.size += info.Size()
+			}
+		}
+		done <- r
+	}()
+
+	select {
+	case r := <-done:
+		return r.size, r.exists
+	case <-time.After(timeout):
+		return 0, false
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePaths(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	paths := filePaths("/tmp/a.txt\n\n  file:///Users/me/My%20Notes.md \n~/Downloads/b.zip")
+	assert.Equal(t, []string{
+		"/tmp/a.txt",
+		"/Users/me/My Notes.md",
+		filepath.Join(home, "Downloads", "b.zip"),
+	}, paths)
+}
+
+func TestStatFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.bin")
+	require.NoError(t, os.WriteFile(first, []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(second, make([]byte, 1024), 0644))
+
+	size, exists := statFiles([]string{first}, fileStatTimeout)
+	assert.True(t, exists)
+	assert.Equal(t, int64(5), size)
+
+	// Sizes of several files add up
+	size, exists = statFiles([]string{first, second}, fileStatTimeout)
+	assert.True(t, exists)
+	assert.Equal(t, int64(1029), size)
+
+	// Directories exist but don't count towards the size
+	size, exists = statFiles([]string{dir}, fileStatTimeout)
+	assert.True(t, exists)
+	assert.Zero(t, size)
+
+	// One missing path marks the whole item missing
+	size, exists = statFiles([]string{first, filepath.Join(dir, "gone.txt")}, fileStatTimeout)
+	assert.False(t, exists)
+	assert.Equal(t, int64(5), size)
+
+	_, exists = statFiles(nil, fileStatTimeout)
+	assert.False(t, exists)
+}
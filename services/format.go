@@ -0,0 +1,109 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// isJSONDocument reports whether content is valid JSON with an object or
+// array root. Bare strings and numbers are left as plain text.
+func isJSONDocument(content string) bool {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// isXMLDocument reports whether content is well-formed XML with a root element
+func isXMLDocument(content string) bool {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "<") || !strings.HasSuffix(trimmed, ">") {
+		return false
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(trimmed))
+	hasElement := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return hasElement
+		}
+		if err != nil {
+			return false
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			hasElement = true
+		}
+	}
+}
+
+// detectLanguage returns a syntax-highlighting hint for structured content
+func detectLanguage(content string) string {
+	switch {
+	case isJSONDocument(content):
+		return "json"
+	case isXMLDocument(content):
+		return "xml"
+	default:
+		return ""
+	}
+}
+
+// formatContent pretty-prints JSON or XML content
+func formatContent(content string) (string, error) {
+	trimmed := strings.TrimSpace(content)
+
+	switch {
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		return formatJSON(trimmed)
+	case strings.HasPrefix(trimmed, "<"):
+		return formatXML(trimmed)
+	default:
+		return "", fmt.Errorf("content is not JSON or XML")
+	}
+}
+
+func formatJSON(content string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(content), "", "  "); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func formatXML(content string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid XML: %w", err)
+		}
+
+		// Drop the whitespace between elements; the encoder re-indents
+		if data, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return "", fmt.Errorf("invalid XML: %w", err)
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsJSONDocument(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected bool
+		desc     string
+	}{
+		{`{"name":"klipd","version":1}`, true, "object"},
+		{`[1,2,3]`, true, "array"},
+		{"  {\"nested\":{\"a\":[true,null]}}\n", true, "surrounding whitespace"},
+		{`{"name":"klipd",}`, false, "trailing comma"},
+		{`{name: "klipd"}`, false, "unquoted key"},
+		{`"just a string"`, false, "string root"},
+		{`42`, false, "number root"},
+		{`[link](http://example.com)`, false, "markdown link"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, isJSONDocument(test.content), test.desc)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	assert.Equal(t, "json", detectLanguage(`{"a":1}`))
+	assert.Equal(t, "xml", detectLanguage(`<note><to>Tove</to></note>`))
+	assert.Equal(t, "", detectLanguage(`<not closed`))
+	assert.Equal(t, "", detectLanguage("plain text"))
+}
+
+func TestFormatContentJSON(t *testing.T) {
+	formatted, err := formatContent(`{"name":"klipd","tags":["a","b"]}`)
+	require.NoError(t, err)
+
+	expected := `{
+  "name": "klipd",
+  "tags": [
+    "a",
+    "b"
+  ]
+}`
+	assert.Equal(t, expected, formatted)
+
+	_, err = formatContent(`{"name":"klipd",}`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid JSON")
+}
+
+func TestFormatContentXML(t *testing.T) {
+	formatted, err := formatContent(`<note><to>Tove</to><from>Jani</from></note>`)
+	require.NoError(t, err)
+
+	expected := `<note>
+  <to>Tove</to>
+  <from>Jani</from>
+</note>`
+	assert.Equal(t, expected, formatted)
+
+	_, err = formatContent(`<note><to>Tove</note>`)
+	assert.Error(t, err)
+}
+
+func TestFormatContentUnsupported(t *testing.T) {
+	_, err := formatContent("plain text")
+	assert.Error(t, err)
+}
@@ -2,31 +2,50 @@ package services
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.design/x/hotkey"
 )
 
+// hotkeyDebounce is how long after a trigger further keydowns of the same
+// hotkey are ignored, so holding a key down doesn't repeat its action
+const hotkeyDebounce = 150 * time.Millisecond
+
 // HotkeyCallback represents a function to be called when a hotkey is pressed
 type HotkeyCallback func()
 
+// systemHotkey is the part of *hotkey.Hotkey the manager uses
+type systemHotkey interface {
+	Register() error
+	Unregister() error
+	Keydown() <-chan hotkey.Event
+}
+
 // HotkeyManager manages global hotkeys using golang.design/x/hotkey
 type HotkeyManager struct {
 	mu         sync.RWMutex
 	isRunning  bool
 	callbacks  map[string]HotkeyCallback
-	registered map[string]*hotkey.Hotkey
+	registered map[string]systemHotkey
+	newHotkey  func([]hotkey.Modifier, hotkey.Key) systemHotkey
+	debounce   time.Duration
 }
 
 // NewHotkeyManager creates a new hotkey manager
 func NewHotkeyManager() *HotkeyManager {
 	return &HotkeyManager{
 		callbacks:  make(map[string]HotkeyCallback),
-		registered: make(map[string]*hotkey.Hotkey),
+		registered: make(map[string]systemHotkey),
 		isRunning:  false,
+		debounce:   hotkeyDebounce,
+		newHotkey: func(mods []hotkey.Modifier, key hotkey.Key) systemHotkey {
+			return hotkey.New(mods, key)
+		},
 	}
 }
 
@@ -44,7 +63,7 @@ func (hm *HotkeyManager) Register(hotkeyStr string, callback HotkeyCallback) err
 		return err
 	}
 
-	hk := hotkey.New(mods, key)
+	hk := hm.newHotkey(mods, key)
 	err = hk.Register()
 	if err != nil {
 		return fmt.Errorf("failed to register hotkey %s: %w", hotkeyStr, err)
@@ -53,19 +72,84 @@ func (hm *HotkeyManager) Register(hotkeyStr string, callback HotkeyCallback) err
 	hm.registered[hotkeyStr] = hk
 	hm.callbacks[hotkeyStr] = callback
 
+	// Callbacks run one at a time on a worker per hotkey. A trigger while
+	// one is running is held until it finishes, and any beyond that dropped.
+	triggers := make(chan struct{}, 1)
+	go func() {
+		for range triggers {
+			hm.mu.RLock()
+			cb, ok := hm.callbacks[hotkeyStr]
+			hm.mu.RUnlock()
+			if ok {
+				cb()
+			}
+		}
+	}()
+
+	debounce := hm.debounce
 	go func() {
+		defer close(triggers)
+		var last time.Time
 		for range hk.Keydown() {
-			log.Printf("Global hotkey triggered: %s", hotkeyStr)
-			if cb, ok := hm.callbacks[hotkeyStr]; ok {
-				go cb()
+			if now := time.Now(); now.Sub(last) >= debounce {
+				last = now
+			} else {
+				slog.Debug("Global hotkey debounced", "hotkey", hotkeyStr)
+				continue
+			}
+
+			select {
+			case triggers <- struct{}{}:
+				slog.Debug("Global hotkey triggered", "hotkey", hotkeyStr)
+			default:
+				slog.Debug("Global hotkey dropped, callback busy", "hotkey", hotkeyStr)
 			}
 		}
 	}()
 
-	log.Printf("Registered global hotkey: %s", hotkeyStr)
+	slog.Info("Registered global hotkey", "hotkey", hotkeyStr)
 	return nil
 }
 
+// RegisterAll registers each of hotkeys to run the same callback, so an
+// action can have alternate combinations. Spellings of a combination already
+// in the list are skipped. If one fails, those registered before it are
+// released again.
+func (hm *HotkeyManager) RegisterAll(hotkeys []string, callback HotkeyCallback) error {
+	var registered []string
+	seen := make(map[string]bool)
+	for _, hotkeyStr := range hotkeys {
+		if _, _, err := parseHotkey(hotkeyStr); err == nil {
+			canonical := canonicalHotkey(hotkeyStr)
+			if seen[canonical] {
+				continue
+			}
+			seen[canonical] = true
+		}
+
+		if err := hm.Register(hotkeyStr, callback); err != nil {
+			for _, done := range registered {
+				hm.Unregister(done)
+			}
+			return err
+		}
+		registered = append(registered, hotkeyStr)
+	}
+	return nil
+}
+
+// SplitHotkeys splits a hotkey setting holding comma-separated alternate
+// combinations, e.g. "Cmd+Shift+Space, Cmd+Shift+V"
+func SplitHotkeys(value string) []string {
+	var hotkeys []string
+	for _, hotkeyStr := range strings.Split(value, ",") {
+		if hotkeyStr = strings.TrimSpace(hotkeyStr); hotkeyStr != "" {
+			hotkeys = append(hotkeys, hotkeyStr)
+		}
+	}
+	return hotkeys
+}
+
 // parseHotkey converts a string like "Cmd+Shift+C" into hotkey library types
 func parseHotkey(hotkeyStr string) ([]hotkey.Modifier, hotkey.Key, error) {
 	parts := strings.Split(hotkeyStr, "+")
@@ -104,6 +188,58 @@ func parseHotkey(hotkeyStr string) ([]hotkey.Modifier, hotkey.Key, error) {
 	return mods, key, nil
 }
 
+// modifierNames maps the modifier spellings parseHotkey accepts to one name
+var modifierNames = map[string]string{
+	"cmd":     "cmd",
+	"command": "cmd",
+	"super":   "cmd",
+	"shift":   "shift",
+	"ctrl":    "ctrl",
+	"control": "ctrl",
+	"alt":     "alt",
+	"option":  "alt",
+}
+
+// canonicalHotkey normalizes a parseable hotkey string so that spellings like
+// "Shift+Cmd+k" and "Command+Shift+K" compare equal
+func canonicalHotkey(hotkeyStr string) string {
+	parts := strings.Split(hotkeyStr, "+")
+	mods := make([]string, 0, len(parts)-1)
+	for _, mod := range parts[:len(parts)-1] {
+		mods = append(mods, modifierNames[strings.ToLower(mod)])
+	}
+	sort.Strings(mods)
+	return strings.Join(append(mods, strings.ToUpper(parts[len(parts)-1])), "+")
+}
+
+// ValidateHotkeys checks that every hotkey, keyed by a human-readable name,
+// parses and that no two share a combination. Each value may list alternate
+// combinations as in SplitHotkeys; repeating one within the same value is
+// fine. Empty hotkeys are unbound and skipped.
+func ValidateHotkeys(hotkeys map[string]string) error {
+	names := make([]string, 0, len(hotkeys))
+	for name := range hotkeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	used := make(map[string]string)
+	for _, name := range names {
+		for _, hotkeyStr := range SplitHotkeys(hotkeys[name]) {
+			if _, _, err := parseHotkey(hotkeyStr); err != nil {
+				return fmt.Errorf("%s hotkey: %w", name, err)
+			}
+
+			canonical := canonicalHotkey(hotkeyStr)
+			if other, exists := used[canonical]; exists && other != name {
+				return fmt.Errorf("%s hotkey %s conflicts with the %s hotkey", name, hotkeyStr, other)
+			}
+			used[canonical] = name
+		}
+	}
+	return nil
+}
+
 // Unregister removes a hotkey registration
 func (hm *HotkeyManager) Unregister(hotkeyStr string) {
 	hm.mu.Lock()
@@ -111,12 +247,25 @@ func (hm *HotkeyManager) Unregister(hotkeyStr string) {
 
 	if hk, exists := hm.registered[hotkeyStr]; exists {
 		if err := hk.Unregister(); err != nil {
-			log.Printf("Failed to unregister hotkey %s: %v", hotkeyStr, err)
+			slog.Warn("Failed to unregister hotkey", "hotkey", hotkeyStr, "err", err)
 		}
 		delete(hm.registered, hotkeyStr)
 		delete(hm.callbacks, hotkeyStr)
-		log.Printf("Unregistered hotkey: %s", hotkeyStr)
+		slog.Info("Unregistered hotkey", "hotkey", hotkeyStr)
+	}
+}
+
+// ListRegistered returns the currently registered hotkey strings, sorted
+func (hm *HotkeyManager) ListRegistered() []string {
+	hm.mu.RLock()
+	defer hm.mu.RUnlock()
+
+	hotkeys := make([]string, 0, len(hm.registered))
+	for str := range hm.registered {
+		hotkeys = append(hotkeys, str)
 	}
+	sort.Strings(hotkeys)
+	return hotkeys
 }
 
 // Start is a placeholder, as registration happens immediately
@@ -124,7 +273,7 @@ func (hm *HotkeyManager) Start() error {
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
 	hm.isRunning = true
-	log.Println("Global hotkey manager started")
+	slog.Info("Global hotkey manager started")
 	return nil
 }
 
@@ -139,15 +288,15 @@ func (hm *HotkeyManager) Stop() {
 
 	for str, hk := range hm.registered {
 		if err := hk.Unregister(); err != nil {
-			log.Printf("Failed to unregister hotkey %s: %v", str, err)
+			slog.Warn("Failed to unregister hotkey", "hotkey", str, "err", err)
 		}
-		log.Printf("Unregistered hotkey on stop: %s", str)
+		slog.Debug("Unregistered hotkey on stop", "hotkey", str)
 	}
 
-	hm.registered = make(map[string]*hotkey.Hotkey)
+	hm.registered = make(map[string]systemHotkey)
 	hm.callbacks = make(map[string]HotkeyCallback)
 	hm.isRunning = false
-	log.Println("Hotkey manager stopped")
+	slog.Info("Hotkey manager stopped")
 }
 
 // IsRunning returns whether the hotkey manager is currently running
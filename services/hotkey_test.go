@@ -1,12 +1,39 @@
 package services
 
 import (
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.design/x/hotkey"
 )
 
+// fakeHotkey stands in for an OS hotkey registration
+type fakeHotkey struct {
+	keydown      chan hotkey.Event
+	unregistered bool
+}
+
+func (f *fakeHotkey) Register() error { return nil }
+
+func (f *fakeHotkey) Unregister() error {
+	f.unregistered = true
+	return nil
+}
+
+func (f *fakeHotkey) Keydown() <-chan hotkey.Event { return f.keydown }
+
+func newFakeHotkeyManager() *HotkeyManager {
+	hm := NewHotkeyManager()
+	hm.newHotkey = func([]hotkey.Modifier, hotkey.Key) systemHotkey {
+		return &fakeHotkey{keydown: make(chan hotkey.Event)}
+	}
+	return hm
+}
+
 func TestNewHotkeyManager(t *testing.T) {
 	hm := NewHotkeyManager()
 
@@ -94,6 +121,182 @@ func TestHotkeyManagerRegisterInvalidKey(t *testing.T) {
 	hm.Stop()
 }
 
+func TestHotkeyManagerListRegistered(t *testing.T) {
+	hm := newFakeHotkeyManager()
+	assert.Empty(t, hm.ListRegistered())
+
+	require.NoError(t, hm.Register("Cmd+Shift+V", func() {}))
+	require.NoError(t, hm.Register("Cmd+Shift+C", func() {}))
+	assert.Equal(t, []string{"Cmd+Shift+C", "Cmd+Shift+V"}, hm.ListRegistered())
+
+	fake := hm.registered["Cmd+Shift+V"].(*fakeHotkey)
+	hm.Unregister("Cmd+Shift+V")
+	assert.True(t, fake.unregistered)
+	assert.Equal(t, []string{"Cmd+Shift+C"}, hm.ListRegistered())
+
+	// Unregistering something that isn't bound is a no-op
+	hm.Unregister("Cmd+Shift+X")
+	assert.Equal(t, []string{"Cmd+Shift+C"}, hm.ListRegistered())
+}
+
+func TestHotkeyManagerConcurrentAccess(t *testing.T) {
+	hm := newFakeHotkeyManager()
+
+	triggered := make(chan struct{}, 10)
+	require.NoError(t, hm.Register("Cmd+Shift+V", func() { triggered <- struct{}{} }))
+	fake := hm.registered["Cmd+Shift+V"].(*fakeHotkey)
+
+	// Key presses, listing and (un)registration racing each other
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			fake.keydown <- hotkey.Event{}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			hm.ListRegistered()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			_ = hm.Register("Cmd+Shift+C", func() {})
+			hm.Unregister("Cmd+Shift+C")
+		}
+	}()
+	wg.Wait()
+
+	// Rapid presses are debounced, so only the first is guaranteed to run
+	<-triggered
+	assert.Equal(t, []string{"Cmd+Shift+V"}, hm.ListRegistered())
+}
+
+func TestHotkeyManagerDebouncesRapidTriggers(t *testing.T) {
+	hm := newFakeHotkeyManager()
+	hm.debounce = time.Hour
+
+	var calls atomic.Int32
+	triggered := make(chan struct{}, 10)
+	require.NoError(t, hm.Register("Cmd+Shift+V", func() {
+		calls.Add(1)
+		triggered <- struct{}{}
+	}))
+	fake := hm.registered["Cmd+Shift+V"].(*fakeHotkey)
+
+	for i := 0; i < 10; i++ {
+		fake.keydown <- hotkey.Event{}
+	}
+	<-triggered
+
+	// Give any stray callbacks a chance to run before counting
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHotkeyManagerSerializesCallbacks(t *testing.T) {
+	hm := newFakeHotkeyManager()
+	hm.debounce = 0
+
+	var running, maxRunning, calls atomic.Int32
+	release := make(chan struct{})
+	require.NoError(t, hm.Register("Cmd+Shift+V", func() {
+		n := running.Add(1)
+		if n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		<-release
+		running.Add(-1)
+		calls.Add(1)
+	}))
+	fake := hm.registered["Cmd+Shift+V"].(*fakeHotkey)
+
+	// The first press blocks in the callback, one more waits behind it and
+	// the rest are dropped
+	for i := 0; i < 10; i++ {
+		fake.keydown <- hotkey.Event{}
+	}
+	close(release)
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), maxRunning.Load())
+	assert.Less(t, calls.Load(), int32(10))
+}
+
+func TestHotkeyManagerRegisterAll(t *testing.T) {
+	hm := newFakeHotkeyManager()
+	hm.debounce = 0
+
+	triggered := make(chan struct{}, 10)
+	callback := func() { triggered <- struct{}{} }
+	require.NoError(t, hm.RegisterAll(SplitHotkeys(" Cmd+Shift+Space,Cmd+Shift+V, shift+cmd+v ,"), callback))
+	assert.Equal(t, []string{"Cmd+Shift+Space", "Cmd+Shift+V"}, hm.ListRegistered())
+
+	// Either combination runs the action
+	for _, combo := range []string{"Cmd+Shift+Space", "Cmd+Shift+V"} {
+		hm.registered[combo].(*fakeHotkey).keydown <- hotkey.Event{}
+		select {
+		case <-triggered:
+		case <-time.After(time.Second):
+			t.Fatalf("%s didn't trigger the callback", combo)
+		}
+	}
+
+	// A failure part way through releases the combinations before it
+	err := hm.RegisterAll([]string{"Ctrl+Alt+K", "Cmd+Shift+V"}, callback)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already registered")
+	assert.Equal(t, []string{"Cmd+Shift+Space", "Cmd+Shift+V"}, hm.ListRegistered())
+}
+
+func TestValidateHotkeys(t *testing.T) {
+	defaults := map[string]string{
+		"global search":  "Cmd+Shift+Space",
+		"paste previous": "Cmd+Shift+C",
+		"show window":    "Cmd+Shift+K",
+		"forget":         "",
+	}
+	assert.NoError(t, ValidateHotkeys(defaults))
+
+	tests := []struct {
+		name       string
+		showWindow string
+		errorMsg   string
+	}{
+		{"Same as search", "Cmd+Shift+Space", "conflicts with the global search hotkey"},
+		{"Different spelling of search", "shift+command+SPACE", "conflicts with the global search hotkey"},
+		{"Unparseable", "Cmd+Shift+Nope", "show window hotkey: unknown key"},
+		{"Unique", "Ctrl+Alt+K", ""},
+		{"Alternates", "Cmd+Shift+K, Ctrl+Alt+K", ""},
+		{"Alternate repeated", "Cmd+Shift+K, shift+cmd+k", ""},
+		{"Alternate same as search", "Cmd+Shift+K, Cmd+Shift+Space", "conflicts with the global search hotkey"},
+		{"Alternate unparseable", "Cmd+Shift+K, Cmd+Nope", "show window hotkey: unknown key"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hotkeys := map[string]string{
+				"global search": "Cmd+Shift+Space",
+				"show window":   tc.showWindow,
+			}
+			err := ValidateHotkeys(hotkeys)
+			if tc.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errorMsg)
+		})
+	}
+
+	// Unbound hotkeys never conflict with each other
+	assert.NoError(t, ValidateHotkeys(map[string]string{"forget": "", "show window": ""}))
+}
+
 func TestParseHotkey(t *testing.T) {
 	tests := []struct {
 		input       string
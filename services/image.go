@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.Decode
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+)
+
+// thumbnailSize is the longest edge, in pixels, of generated thumbnails
+const thumbnailSize = 128
+
+// maxDecodePixels caps the area of images we fully decode. A few bytes of
+// compressed data can claim enormous dimensions, so the header is checked
+// before any pixel buffer is allocated.
+const maxDecodePixels = 50_000_000
+
+// base64ImagePayload extracts the base64 data from a "data:image/...;base64,"
+// URI or a bare base64 string. It only checks the shape, not the contents.
+func base64ImagePayload(content string) (string, bool) {
+	payload := strings.TrimSpace(content)
+
+	if strings.HasPrefix(payload, "data:") {
+		header, data, found := strings.Cut(payload, ",")
+		if !found || !strings.HasPrefix(header, "data:image/") || !strings.HasSuffix(header, ";base64") {
+			return "", false
+		}
+		payload = data
+	}
+
+	if len(payload) < 16 || len(payload)%4 != 0 {
+		return "", false
+	}
+
+	for _, r := range payload {
+		isBase64 := (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') ||
+			(r >= '0' && r <= '9') || r == '+' || r == '/' || r == '='
+		if !isBase64 {
+			return "", false
+		}
+	}
+
+	return payload, true
+}
+
+// isBase64Image reports whether content is base64 whose decoded bytes start
+// with a known image signature. Only a short prefix is decoded.
+func isBase64Image(content string) bool {
+	payload, ok := base64ImagePayload(content)
+	if !ok {
+		return false
+	}
+
+	prefix := payload
+	if len(prefix) > 64 {
+		prefix = prefix[:64]
+	}
+
+	header, err := base64.StdEncoding.DecodeString(prefix)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(http.DetectContentType(header), "image/")
+}
+
+// decodeBase64Image returns the raw image bytes of an inline base64 image
+func decodeBase64Image(content string) ([]byte, error) {
+	payload, ok := base64ImagePayload(content)
+	if !ok {
+		return nil, fmt.Errorf("content is not base64 image data")
+	}
+	return base64.StdEncoding.DecodeString(payload)
+}
+
+// downscaleBase64Image shrinks an inline image so neither edge exceeds
+// maxSize and returns it as a data URI, along with the original and new
+// dimensions and the original format. JPEGs stay JPEG; everything else
+// becomes PNG. Images already within bounds are returned unchanged.
+func downscaleBase64Image(content string, maxSize int) (string, image.Config, image.Config, string, error) {
+	data, err := decodeBase64Image(content)
+	if err != nil {
+		return "", image.Config{}, image.Config{}, "", err
+	}
+
+	// Check the header first so small images are never fully decoded
+	original, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", image.Config{}, image.Config{}, "", err
+	}
+	if original.Width <= maxSize && original.Height <= maxSize {
+		return content, original, original, format, nil
+	}
+
+	img, _, err := decodeImage(data)
+	if err != nil {
+		return "", original, image.Config{}, format, err
+	}
+	scaled := scaleImage(img, maxSize)
+
+	var buf bytes.Buffer
+	mimeType := "image/png"
+	if format == "jpeg" {
+		mimeType = "image/jpeg"
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 90})
+	} else {
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return "", original, image.Config{}, format, err
+	}
+
+	bounds := scaled.Bounds()
+	resized := image.Config{Width: bounds.Dx(), Height: bounds.Dy()}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), original, resized, format, nil
+}
+
+// decodeImage decodes data after checking from the header that the image
+// fits within maxDecodePixels
+func decodeImage(data []byte) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxDecodePixels {
+		return nil, format, fmt.Errorf("image too large to decode: %dx%d", cfg.Width, cfg.Height)
+	}
+	return image.Decode(bytes.NewReader(data))
+}
+
+// makeThumbnail decodes an image and returns a PNG no larger than maxSize on
+// either edge, along with the original dimensions and format
+func makeThumbnail(data []byte, maxSize int) ([]byte, image.Config, string, error) {
+	img, format, err := decodeImage(data)
+	if err != nil {
+		return nil, image.Config{}, "", err
+	}
+
+	bounds := img.Bounds()
+	cfg := image.Config{Width: bounds.Dx(), Height: bounds.Dy()}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaleImage(img, maxSize)); err != nil {
+		return nil, cfg, format, err
+	}
+	return buf.Bytes(), cfg, format, nil
+}
+
+// scaleImage shrinks img (nearest neighbour) so neither edge exceeds maxSize,
+// preserving aspect ratio. Smaller images are returned unchanged.
+func scaleImage(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return img
+	}
+
+	newWidth, newHeight := maxSize, maxSize
+	if width > height {
+		newHeight = max(1, height*maxSize/width)
+	} else {
+		newWidth = max(1, width*maxSize/height)
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		srcY := bounds.Min.Y + y*height/newHeight
+		for x := 0; x < newWidth; x++ {
+			srcX := bounds.Min.X + x*width/newWidth
+			scaled.Set(x, y, img.At(srcX, srcY))
+		}
+	}
+	return scaled
+}
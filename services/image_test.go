@@ -0,0 +1,160 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onePixelPNG is a valid 1x1 PNG, base64 encoded
+const onePixelPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestIsBase64Image(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected bool
+		desc     string
+	}{
+		{"data:image/png;base64," + onePixelPNG, true, "PNG data URI"},
+		{onePixelPNG, true, "raw base64 PNG"},
+		{"  data:image/png;base64," + onePixelPNG + "\n", true, "surrounding whitespace"},
+		{base64.StdEncoding.EncodeToString([]byte("just some plain text, not an image")), false, "non-image base64"},
+		{"data:text/plain;base64,aGVsbG8gd29ybGQh", false, "non-image data URI"},
+		{"data:image/png," + onePixelPNG, false, "data URI without base64 marker"},
+		{"Hello, World!", false, "plain text"},
+		{"/Users/test/image.png", false, "image path"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, isBase64Image(test.content), test.desc)
+	}
+}
+
+func TestDecodeBase64Image(t *testing.T) {
+	data, err := decodeBase64Image("data:image/png;base64," + onePixelPNG)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("\x89PNG")))
+
+	_, err = decodeBase64Image("not base64 at all")
+	assert.Error(t, err)
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	data := encodeTestPNG(t, 300, 150)
+
+	thumbnail, dims, format, err := makeThumbnail(data, 128)
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 300, dims.Width)
+	assert.Equal(t, 150, dims.Height)
+
+	thumbCfg, err := png.DecodeConfig(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, 128, thumbCfg.Width)
+	assert.Equal(t, 64, thumbCfg.Height)
+
+	// Images already within bounds keep their size
+	thumbnail, _, _, err = makeThumbnail(encodeTestPNG(t, 20, 10), 128)
+	require.NoError(t, err)
+	thumbCfg, err = png.DecodeConfig(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, 20, thumbCfg.Width)
+	assert.Equal(t, 10, thumbCfg.Height)
+
+	_, _, _, err = makeThumbnail([]byte("not an image"), 128)
+	assert.Error(t, err)
+}
+
+// oversizedPNGHeader returns a PNG signature and IHDR chunk claiming the given
+// dimensions, with no pixel data behind them
+func oversizedPNGHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12], ihdr[13] = 8, 6 // 8-bit RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(13))
+	buf.Write(ihdr)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+func TestDecodeImageRejectsHugeDimensions(t *testing.T) {
+	data := oversizedPNGHeader(100_000, 100_000)
+
+	_, _, _, err := makeThumbnail(data, 128)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too large")
+
+	content := "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+	_, original, _, _, err := downscaleBase64Image(content, 1024)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too large")
+	assert.Equal(t, 100_000, original.Width)
+}
+
+func TestDownscaleBase64Image(t *testing.T) {
+	content := "data:image/png;base64," + base64.StdEncoding.EncodeToString(encodeTestPNG(t, 400, 100))
+
+	scaled, original, resized, format, err := downscaleBase64Image(content, 200)
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, []int{400, 100}, []int{original.Width, original.Height})
+	assert.Equal(t, []int{200, 50}, []int{resized.Width, resized.Height})
+
+	data, err := decodeBase64Image(scaled)
+	require.NoError(t, err)
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 200, cfg.Width)
+	assert.Equal(t, 50, cfg.Height)
+
+	// Images within bounds come back untouched
+	unchanged, original, resized, _, err := downscaleBase64Image(content, 400)
+	require.NoError(t, err)
+	assert.Equal(t, content, unchanged)
+	assert.Equal(t, original.Width, resized.Width)
+	assert.Equal(t, original.Height, resized.Height)
+
+	_, _, _, _, err = downscaleBase64Image("data:image/png;base64,"+base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\nbroken")), 10)
+	assert.Error(t, err)
+}
+
+func TestDownscaleBase64ImageKeepsJPEG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 300))
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	content := "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	scaled, _, resized, format, err := downscaleBase64Image(content, 150)
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, []int{50, 150}, []int{resized.Width, resized.Height})
+	assert.True(t, strings.HasPrefix(scaled, "data:image/jpeg;base64,"))
+}
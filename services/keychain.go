@@ -0,0 +1,20 @@
+package services
+
+// KeyProvider supplies the key used to encrypt sensitive items at rest
+type KeyProvider interface {
+	EncryptionKey() ([]byte, error)
+}
+
+// Keychain entry holding the item encryption key
+const (
+	keychainService = "Klipd"
+	keychainAccount = "item-encryption-key"
+)
+
+// systemKeychain reads the key from the OS keychain, creating it on first use.
+// Off macOS there is no keychain and it always returns an error.
+type systemKeychain struct{}
+
+func (systemKeychain) EncryptionKey() ([]byte, error) {
+	return loadKeychainKey()
+}
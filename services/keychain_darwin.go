@@ -0,0 +1,57 @@
+//go:build darwin
+
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errSecItemNotFound is the exit status of `security` when no entry matches
+const errSecItemNotFound = 44
+
+func loadKeychainKey() ([]byte, error) {
+	key, err := readKeychainKey()
+	if err == nil {
+		return key, nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != errSecItemNotFound {
+		return nil, fmt.Errorf("failed to read keychain: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+
+	// Command arguments are visible to every process on the machine, so the
+	// key goes to `security` on stdin. Its interactive mode doesn't report
+	// failures in the exit status; reading the entry back does.
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -s %s -a %s -w %s\n",
+		keychainService, keychainAccount, hex.EncodeToString(key)))
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to store encryption key in keychain: %w", err)
+	}
+	stored, err := readKeychainKey()
+	if err != nil || !bytes.Equal(stored, key) {
+		return nil, fmt.Errorf("failed to store encryption key in keychain")
+	}
+	return key, nil
+}
+
+func readKeychainKey() ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password",
+		"-s", keychainService, "-a", keychainAccount, "-w").Output()
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(out)))
+}
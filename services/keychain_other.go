@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package services
+
+import "fmt"
+
+func loadKeychainKey() ([]byte, error) {
+	return nil, fmt.Errorf("no keychain available on this platform")
+}
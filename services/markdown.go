@@ -0,0 +1,109 @@
+package services
+
+import (
+	"encoding/base64"
+	"net/http"
+	"path"
+	"strings"
+
+	"klipd/models"
+)
+
+// itemMarkdown renders a decrypted item as a Markdown snippet for pasting
+// into chats and docs: structured content as a fenced block tagged with its
+// language, images as image links, URLs as autolinks, file paths as code
+// spans and anything else as is.
+func itemMarkdown(item *models.ClipboardItem) string {
+	content := strings.TrimSpace(item.ContentText)
+
+	switch {
+	case item.ContentType == "image" && len(item.ContentBinary) > 0:
+		return "![Image](data:" + http.DetectContentType(item.ContentBinary) + ";base64," +
+			base64.StdEncoding.EncodeToString(item.ContentBinary) + ")"
+	case item.ContentType == "image" && isBase64Image(content):
+		return "![Image](" + content + ")"
+	case item.ContentType == "image":
+		return "![" + path.Base(content) + "](" + markdownLinkTarget(content) + ")"
+	case item.ContentType == "file":
+		var lines []string
+		for _, line := range strings.Split(content, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, "- "+codeSpan(line))
+			}
+		}
+		return strings.Join(lines, "\n")
+	case item.ContentType == "color":
+		return codeSpan(content)
+	case item.Language != "":
+		return fencedBlock(item.ContentText, item.Language)
+	case isSingleURL(content):
+		return "<" + content + ">"
+	default:
+		return item.ContentText
+	}
+}
+
+// fencedBlock wraps content in a code fence longer than any run of
+// backticks inside it, so the content can't close the block early
+func fencedBlock(content string, language string) string {
+	fence := strings.Repeat("`", max(3, longestRun(content, '`')+1))
+	return fence + language + "\n" + strings.TrimRight(content, "\n") + "\n" + fence
+}
+
+// codeSpan wraps text in enough backticks to hold any it contains
+func codeSpan(text string) string {
+	ticks := strings.Repeat("`", longestRun(text, '`')+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return ticks + " " + text + " " + ticks
+	}
+	return ticks + text + ticks
+}
+
+// longestRun returns the length of the longest run of r in s
+func longestRun(s string, r rune) int {
+	longest, run := 0, 0
+	for _, c := range s {
+		if c == r {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+// markdownLinkTarget returns target in a form a Markdown link accepts; paths
+// with spaces or parentheses go in angle brackets
+func markdownLinkTarget(target string) string {
+	if strings.ContainsAny(target, " ()") {
+		return "<" + target + ">"
+	}
+	return target
+}
+
+// isSingleURL reports whether content is one http(s) URL and nothing else
+func isSingleURL(content string) bool {
+	return (strings.HasPrefix(content, "http://") || strings.HasPrefix(content, "https://")) &&
+		!strings.ContainsAny(content, " \t\r\n<>")
+}
+
+// ItemMarkdown returns an item formatted as a Markdown snippet
+func (cm *ClipboardMonitor) ItemMarkdown(id string) (string, error) {
+	item, err := cm.GetItemByID(id)
+	if err != nil {
+		return "", err
+	}
+	return itemMarkdown(item), nil
+}
+
+// CopyItemAsMarkdown writes an item's Markdown snippet to the clipboard. The
+// snippet is treated as already seen so it doesn't become an item itself.
+func (cm *ClipboardMonitor) CopyItemAsMarkdown(id string) error {
+	markdown, err := cm.ItemMarkdown(id)
+	if err != nil {
+		return err
+	}
+
+	return cm.writeOwn(markdown)
+}
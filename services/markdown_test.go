@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+
+	"klipd/database"
+	"klipd/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     models.ClipboardItem
+		expected string
+	}{
+		{
+			name:     "code with language",
+			item:     models.ClipboardItem{ContentType: "json", Language: "json", ContentText: "{\"ok\": true}\n"},
+			expected: "```json\n{\"ok\": true}\n```",
+		},
+		{
+			name:     "code containing a fence",
+			item:     models.ClipboardItem{ContentType: "text", Language: "xml", ContentText: "<doc>```</doc>"},
+			expected: "````xml\n<doc>```</doc>\n````",
+		},
+		{
+			name:     "plain text",
+			item:     models.ClipboardItem{ContentType: "text", ContentText: "Meeting moved to 3pm"},
+			expected: "Meeting moved to 3pm",
+		},
+		{
+			name:     "url",
+			item:     models.ClipboardItem{ContentType: "text", ContentText: " https://example.com/docs?page=2 "},
+			expected: "<https://example.com/docs?page=2>",
+		},
+		{
+			name:     "text mentioning a url",
+			item:     models.ClipboardItem{ContentType: "text", ContentText: "see https://example.com"},
+			expected: "see https://example.com",
+		},
+		{
+			name:     "image path",
+			item:     models.ClipboardItem{ContentType: "image", ContentText: "/Users/me/My Shots/cat.png"},
+			expected: "![cat.png](</Users/me/My Shots/cat.png>)",
+		},
+		{
+			name:     "image url",
+			item:     models.ClipboardItem{ContentType: "image", ContentText: "https://example.com/cat.png"},
+			expected: "![cat.png](https://example.com/cat.png)",
+		},
+		{
+			name:     "files",
+			item:     models.ClipboardItem{ContentType: "file", ContentText: "/tmp/a.txt\n/tmp/b.txt\n"},
+			expected: "- `/tmp/a.txt`\n- `/tmp/b.txt`",
+		},
+		{
+			name:     "color",
+			item:     models.ClipboardItem{ContentType: "color", ContentText: "#ff8800"},
+			expected: "`#ff8800`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, itemMarkdown(&tt.item))
+		})
+	}
+}
+
+func TestItemMarkdownInlineImage(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	item := &models.ClipboardItem{ContentType: "image", ContentBinary: png}
+	assert.Equal(t, "![Image](data:image/png;base64,iVBORw0KGgo=)", itemMarkdown(item))
+}
+
+func TestCopyItemAsMarkdown(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	var written string
+	monitor.writeClipboard = func(s string) error { written = s; return nil }
+
+	require.NoError(t, db.CreateClipboardItem(&models.ClipboardItem{
+		ID: "json-item", ContentType: "json", Language: "json", ContentText: `{"a":1}`, PreviewText: `{"a":1}`, Hash: "md-hash",
+	}))
+
+	markdown, err := monitor.ItemMarkdown("json-item")
+	require.NoError(t, err)
+	assert.Equal(t, "```json\n{\"a\":1}\n```", markdown)
+
+	require.NoError(t, monitor.CopyItemAsMarkdown("json-item"))
+	assert.Equal(t, markdown, written)
+
+	// The snippet isn't captured as an item of its own
+	monitor.readClipboard = func() (string, error) { return written, nil }
+	monitor.checkClipboard()
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	_, err = monitor.ItemMarkdown("missing")
+	assert.Error(t, err)
+}
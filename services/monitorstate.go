@@ -0,0 +1,92 @@
+package services
+
+import "klipd/models"
+
+// States carried by the "monitor-state" event
+const (
+	MonitorStopped = "stopped" // Not started, or stopped on shutdown
+	MonitorRunning = "running" // Started and capturing, or recovered from an error
+	MonitorPaused  = "paused"  // Started but monitoring is disabled
+	MonitorResumed = "resumed" // Monitoring was re-enabled after a pause
+	MonitorError   = "error"   // The clipboard can't be read
+)
+
+// setState records a new monitoring state and announces it with a
+// "monitor-state" event. Every transition goes through here so the frontend
+// sees one consistent sequence. err, if set, explains an error state.
+// Callers must hold stateMu.
+func (cm *ClipboardMonitor) setState(state string, err error) {
+	if state == cm.state {
+		return
+	}
+	cm.state = state
+
+	event := models.MonitorState{
+		State:   state,
+		Enabled: cm.config.MonitoringEnabled,
+		Running: cm.isRunning,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	cm.emitEvent("monitor-state", event)
+}
+
+// capturing reports whether state is one in which the clipboard is polled
+func capturing(state string) bool {
+	return state == MonitorRunning || state == MonitorResumed || state == MonitorError
+}
+
+// syncState moves between capturing and paused to match
+// Config.MonitoringEnabled, for when it has been changed from outside
+func (cm *ClipboardMonitor) syncState() {
+	cm.stateMu.Lock()
+	defer cm.stateMu.Unlock()
+
+	if !cm.isRunning {
+		return
+	}
+	switch {
+	case !cm.config.MonitoringEnabled && capturing(cm.state):
+		cm.setState(MonitorPaused, nil)
+	case cm.config.MonitoringEnabled && cm.state == MonitorPaused:
+		cm.setState(MonitorResumed, nil)
+	}
+}
+
+// SetMonitoringEnabled pauses or resumes capturing
+func (cm *ClipboardMonitor) SetMonitoringEnabled(enabled bool) {
+	cm.config.MonitoringEnabled = enabled
+	cm.syncState()
+}
+
+// readFailed moves to the error state when reading the clipboard fails
+// while capturing, and readSucceeded back out of it once reads work again
+func (cm *ClipboardMonitor) readFailed(err error) {
+	cm.stateMu.Lock()
+	defer cm.stateMu.Unlock()
+
+	if capturing(cm.state) {
+		cm.setState(MonitorError, err)
+	}
+}
+
+func (cm *ClipboardMonitor) readSucceeded() {
+	cm.stateMu.Lock()
+	defer cm.stateMu.Unlock()
+
+	if cm.state == MonitorError {
+		cm.setState(MonitorRunning, nil)
+	}
+}
+
+// State returns the current monitoring state, one of the Monitor constants
+func (cm *ClipboardMonitor) State() string {
+	cm.stateMu.Lock()
+	defer cm.stateMu.Unlock()
+
+	if cm.state == "" {
+		return MonitorStopped
+	}
+	return cm.state
+}
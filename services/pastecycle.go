@@ -0,0 +1,57 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// pasteCycleWindow is how long after a press the next one keeps cycling
+// further back instead of starting over at the most recent item
+const pasteCycleWindow = 3 * time.Second
+
+// pasteCycle tracks the history index for repeated "paste previous" presses
+type pasteCycle struct {
+	mu         sync.Mutex
+	window     time.Duration
+	index      int
+	generation int
+	timer      *time.Timer
+}
+
+func newPasteCycle(window time.Duration) *pasteCycle {
+	return &pasteCycle{window: window}
+}
+
+// next returns the index to paste for this press and restarts the reset timer
+func (pc *pasteCycle) next() int {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	current := pc.index
+	pc.index++
+
+	if pc.timer != nil {
+		pc.timer.Stop()
+	}
+
+	// A timer that already fired but is waiting on the lock must not reset
+	// the index of a newer cycle, so each press gets its own generation
+	pc.generation++
+	generation := pc.generation
+	pc.timer = time.AfterFunc(pc.window, func() {
+		pc.mu.Lock()
+		defer pc.mu.Unlock()
+		if pc.generation == generation {
+			pc.index = 0
+		}
+	})
+
+	return current
+}
+
+// reset starts the cycle over, e.g. when it runs past the end of history
+func (pc *pasteCycle) reset() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.index = 0
+}
@@ -0,0 +1,44 @@
+//go:build darwin
+
+package services
+
+/*
+#cgo LDFLAGS: -framework ApplicationServices
+#include <ApplicationServices/ApplicationServices.h>
+
+// kVK_ANSI_V from Carbon's Events.h
+static const CGKeyCode keyCodeV = 9;
+
+static void postCommandV(void) {
+	CGEventSourceRef source = CGEventSourceCreate(kCGEventSourceStateCombinedSessionState);
+	CGEventRef down = CGEventCreateKeyboardEvent(source, keyCodeV, true);
+	CGEventRef up = CGEventCreateKeyboardEvent(source, keyCodeV, false);
+
+	// Set the flags outright so modifiers still held from the hotkey, such
+	// as Shift, don't turn this into a different shortcut
+	CGEventSetFlags(down, kCGEventFlagMaskCommand);
+	CGEventSetFlags(up, kCGEventFlagMaskCommand);
+	CGEventPost(kCGAnnotatedSessionEventTap, down);
+	CGEventPost(kCGAnnotatedSessionEventTap, up);
+
+	CFRelease(down);
+	CFRelease(up);
+	if (source != NULL) {
+		CFRelease(source);
+	}
+}
+*/
+import "C"
+
+import "errors"
+
+// SendPasteKeystroke presses Cmd+V in the frontmost app. macOS drops
+// synthesized key events from apps without Accessibility access, so that's
+// reported rather than failing silently.
+func SendPasteKeystroke() error {
+	if C.AXIsProcessTrusted() == 0 {
+		return errors.New("pasting needs Accessibility access in System Settings > Privacy & Security")
+	}
+	C.postCommandV()
+	return nil
+}
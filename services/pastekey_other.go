@@ -0,0 +1,10 @@
+//go:build !darwin
+
+package services
+
+import "errors"
+
+// SendPasteKeystroke is only implemented on macOS
+func SendPasteKeystroke() error {
+	return errors.New("pasting into other apps is not supported on this platform")
+}
@@ -0,0 +1,25 @@
+package services
+
+import "klipd/config"
+
+// PreviousItemActions holds what the previous-item hotkey can do, one func
+// per config.PreviousAction* value
+type PreviousItemActions struct {
+	Copy  func() error
+	Paste func() error
+	Show  func() error
+}
+
+// Run performs action. Settings are validated when saved, so anything
+// unrecognised falls back to copying, as the hotkey did before it was
+// configurable.
+func (p PreviousItemActions) Run(action string) error {
+	switch action {
+	case config.PreviousActionPaste:
+		return p.Paste()
+	case config.PreviousActionShow:
+		return p.Show()
+	default:
+		return p.Copy()
+	}
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"klipd/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviousItemActionsRun(t *testing.T) {
+	var called []string
+	stub := func(name string) func() error {
+		return func() error {
+			called = append(called, name)
+			return nil
+		}
+	}
+	actions := PreviousItemActions{
+		Copy:  stub("copy"),
+		Paste: stub("paste"),
+		Show:  stub("show"),
+	}
+
+	for action, want := range map[string]string{
+		config.PreviousActionCopy:  "copy",
+		config.PreviousActionPaste: "paste",
+		config.PreviousActionShow:  "show",
+		"":                         "copy",
+		"bogus":                    "copy",
+	} {
+		called = nil
+		assert.NoError(t, actions.Run(action), action)
+		assert.Equal(t, []string{want}, called, action)
+	}
+
+	failed := errors.New("nothing to paste")
+	actions.Paste = func() error { return failed }
+	assert.ErrorIs(t, actions.Run(config.PreviousActionPaste), failed)
+}
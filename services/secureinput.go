@@ -0,0 +1,14 @@
+package services
+
+// SecureInputDetector reports whether the OS is in secure input mode, which
+// macOS enables while a password field has focus
+type SecureInputDetector interface {
+	IsSecureInputActive() bool
+}
+
+// systemSecureInput queries the platform; it always reports false off macOS
+type systemSecureInput struct{}
+
+func (systemSecureInput) IsSecureInputActive() bool {
+	return isSecureInputEnabled()
+}
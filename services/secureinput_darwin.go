@@ -0,0 +1,13 @@
+//go:build darwin
+
+package services
+
+/*
+#cgo LDFLAGS: -framework Carbon
+#include <Carbon/Carbon.h>
+*/
+import "C"
+
+func isSecureInputEnabled() bool {
+	return C.IsSecureEventInputEnabled() != 0
+}
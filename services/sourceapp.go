@@ -0,0 +1,14 @@
+package services
+
+// SourceAppDetector reports the name of the app in the foreground, which is
+// taken to be the one that just wrote to the clipboard
+type SourceAppDetector interface {
+	FrontmostApp() string
+}
+
+// systemSourceApp queries the platform; it always reports "" off macOS
+type systemSourceApp struct{}
+
+func (systemSourceApp) FrontmostApp() string {
+	return frontmostAppName()
+}
@@ -0,0 +1,33 @@
+//go:build darwin
+
+package services
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework AppKit
+#import <AppKit/AppKit.h>
+#include <stdlib.h>
+#include <string.h>
+
+static char *frontmostAppName(void) {
+	@autoreleasepool {
+		NSString *name = [[[NSWorkspace sharedWorkspace] frontmostApplication] localizedName];
+		if (name == nil) {
+			return NULL;
+		}
+		return strdup([name UTF8String]);
+	}
+}
+*/
+import "C"
+
+import "unsafe"
+
+func frontmostAppName() string {
+	name := C.frontmostAppName()
+	if name == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(name))
+	return C.GoString(name)
+}
@@ -0,0 +1,7 @@
+//go:build !darwin
+
+package services
+
+func frontmostAppName() string {
+	return ""
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"encoding/csv"
+	"strings"
+)
+
+// tableMaxCellWords caps the words in a comma-separated cell. Longer cells
+// read as clauses of prose rather than spreadsheet values.
+const tableMaxCellWords = 4
+
+// tableMaxBytes caps the content checked for a table. Parsing is linear but
+// runs on every capture, and a preview line isn't worth that on huge text.
+const tableMaxBytes = 256 << 10
+
+// csvMinRows is how many lines comma-separated content needs to count as a
+// table; two short lines with a comma each are as likely to be a note
+const csvMinRows = 3
+
+// detectTable reports the size of content that looks like a range copied
+// from a spreadsheet: at least two lines splitting into the same number (two
+// or more) of tab- or comma-separated cells. Commas are held to a stricter
+// standard since prose and code use them too: there must be more rows, cells
+// must be short, rows can't end like sentences and the text must be free of
+// code punctuation. Content over tableMaxBytes is never a table.
+func detectTable(content string) (rows, cols int, ok bool) {
+	content = strings.Trim(content, "\r\n")
+	if len(content) > tableMaxBytes || !strings.Contains(content, "\n") {
+		return 0, 0, false
+	}
+
+	var records [][]string
+	if strings.Contains(content, "\t") {
+		if records, ok = splitTable(content, '\t'); !ok {
+			return 0, 0, false
+		}
+	} else {
+		if strings.ContainsAny(content, "(){};=") {
+			return 0, 0, false
+		}
+		if records, ok = splitTable(content, ','); !ok || len(records) < csvMinRows {
+			return 0, 0, false
+		}
+		for _, record := range records {
+			if endsSentence(record[len(record)-1]) {
+				return 0, 0, false
+			}
+			for _, cell := range record {
+				if len(strings.Fields(cell)) > tableMaxCellWords {
+					return 0, 0, false
+				}
+			}
+		}
+	}
+
+	return len(records), len(records[0]), true
+}
+
+// splitTable parses content as rows of cells separated by delimiter,
+// failing unless every row has the same number of cells, at least two.
+// Text indented with the delimiter, which leaves the first column empty
+// throughout, isn't a table either.
+func splitTable(content string, delimiter rune) ([][]string, bool) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.Comma = delimiter
+	reader.LazyQuotes = delimiter == '\t'
+	reader.TrimLeadingSpace = delimiter != '\t'
+
+	records, err := reader.ReadAll()
+	if err != nil || len(records) < 2 || len(records[0]) < 2 {
+		return nil, false
+	}
+
+	for _, record := range records {
+		if strings.TrimSpace(record[0]) != "" {
+			return records, true
+		}
+	}
+	return nil, false
+}
+
+// endsSentence reports whether cell ends with sentence punctuation
+func endsSentence(cell string) bool {
+	cell = strings.TrimSpace(cell)
+	return strings.HasSuffix(cell, ".") || strings.HasSuffix(cell, "!") || strings.HasSuffix(cell, "?")
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		rows    int
+		cols    int
+		ok      bool
+	}{
+		{"3x4 TSV", "Name\tQty\tPrice\tTotal\nApple\t3\t0.50\t1.50\nPear\t2\t0.75\t1.50\n", 3, 4, true},
+		{"TSV with empty cells", "a\t\tc\nd\te\t\n", 2, 3, true},
+		{"CSV", "id,name,city\n1,Ada Lovelace,London\n2,\"Grace Hopper\",New York\n", 3, 3, true},
+		{"CSV with quoted comma", "name,location\n\"Smith, J\",\"Paris, FR\"\nLee,Seoul\n", 3, 2, true},
+		{"two-line CSV", "sku,qty\nA-1,4\n", 0, 0, false},
+		{"short sentences", "Yes, it works.\nNo, not yet.\nMaybe, later.", 0, 0, false},
+		{"plain paragraph", "Well, I think so. However, the results\nwere, as far as anyone could tell, inconclusive.", 0, 0, false},
+		{"prose with one comma per line", "Dear Ada, thanks for the notes you sent over\nBest wishes, and see you on Monday morning", 0, 0, false},
+		{"code", "foo(a, b)\nbar(c, d)", 0, 0, false},
+		{"tab-indented code", "\tif ok {\n\t\treturn\n\t}", 0, 0, false},
+		{"indented lines", "\tfirst\n\tsecond", 0, 0, false},
+		{"single row", "a\tb\tc", 0, 0, false},
+		{"ragged rows", "a,b,c\nd,e\n", 0, 0, false},
+		{"single column", "apples\npears\n", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, cols, ok := detectTable(tt.content)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.rows, rows)
+			assert.Equal(t, tt.cols, cols)
+		})
+	}
+}
+
+func TestDetectTableSkipsHugeContent(t *testing.T) {
+	row := "alpha\tbeta\tgamma\n"
+	small := strings.Repeat(row, 10)
+	_, _, ok := detectTable(small)
+	assert.True(t, ok)
+
+	huge := strings.Repeat(row, tableMaxBytes/len(row)+1)
+	_, _, ok = detectTable(huge)
+	assert.False(t, ok)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	utf8BOM    = "\xEF\xBB\xBF"
+	utf16LEBOM = "\xFF\xFE"
+	utf16BEBOM = "\xFE\xFF"
+)
+
+// normalizeClipboardText turns text some Windows apps leave on the clipboard
+// into plain UTF-8: a leading byte order mark is dropped, and UTF-16, marked
+// by its BOM or recognizable as ASCII-range text padded with NUL bytes, is
+// decoded. Ordinary UTF-8 comes back unchanged.
+func normalizeClipboardText(content string) string {
+	switch {
+	case strings.HasPrefix(content, utf8BOM):
+		return content[len(utf8BOM):]
+	case strings.HasPrefix(content, utf16LEBOM):
+		return decodeUTF16(content[len(utf16LEBOM):], false)
+	case strings.HasPrefix(content, utf16BEBOM):
+		return decodeUTF16(content[len(utf16BEBOM):], true)
+	}
+
+	if bigEndian, ok := looksLikeUTF16(content); ok {
+		return decodeUTF16(content, bigEndian)
+	}
+	return content
+}
+
+// looksLikeUTF16 reports whether content is UTF-16 without a BOM. Only text
+// that is mostly ASCII can be told apart, by the NUL high byte of each code
+// unit; UTF-8 text never contains NUL, so it can't match.
+func looksLikeUTF16(content string) (bigEndian bool, ok bool) {
+	if len(content) < 4 || !strings.Contains(content, "\x00") {
+		return false, false
+	}
+
+	// A NUL terminator is all zeros, so it can't tell the byte order
+	for len(content) >= 2 && content[len(content)-2:] == "\x00\x00" {
+		content = content[:len(content)-2]
+	}
+
+	var evenNULs, oddNULs int
+	for i := 0; i < len(content); i++ {
+		if content[i] != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenNULs++
+		} else {
+			oddNULs++
+		}
+	}
+
+	// Most code units must carry a NUL, all on the same side
+	units := len(content) / 2
+	switch {
+	case evenNULs == 0 && oddNULs*2 >= units:
+		return false, true
+	case oddNULs == 0 && evenNULs*2 >= units:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// decodeUTF16 decodes UTF-16 bytes to UTF-8. A dangling odd byte and the NUL
+// terminator Windows adds are dropped; invalid surrogates become U+FFFD.
+func decodeUTF16(content string, bigEndian bool) string {
+	units := make([]uint16, 0, len(content)/2)
+	for i := 0; i+1 < len(content); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(content[i])<<8|uint16(content[i+1]))
+		} else {
+			units = append(units, uint16(content[i+1])<<8|uint16(content[i]))
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range utf16.Decode(units) {
+		b.WriteRune(r)
+	}
+	decoded := strings.TrimRight(b.String(), "\x00")
+
+	// A BOM can also survive as a character after decoding
+	return strings.TrimPrefix(decoded, "\uFEFF")
+}
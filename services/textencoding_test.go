@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// utf16Bytes encodes s as UTF-16 in the given byte order
+func utf16Bytes(s string, bigEndian bool) string {
+	var b []byte
+	for _, unit := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b = append(b, byte(unit>>8), byte(unit))
+		} else {
+			b = append(b, byte(unit), byte(unit>>8))
+		}
+	}
+	return string(b)
+}
+
+func TestNormalizeClipboardText(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"plain ASCII", "hello world", "hello world"},
+		{"plain UTF-8", "héllo 你好 🎉", "héllo 你好 🎉"},
+		{"empty", "", ""},
+		{"UTF-8 BOM", utf8BOM + "hello", "hello"},
+		{"UTF-16LE with BOM", utf16LEBOM + utf16Bytes("hello", false), "hello"},
+		{"UTF-16BE with BOM", utf16BEBOM + utf16Bytes("hello", true), "hello"},
+		{"UTF-16LE CJK with BOM", utf16LEBOM + utf16Bytes("你好", false), "你好"},
+		{"UTF-16LE emoji with BOM", utf16LEBOM + utf16Bytes("hi 🎉", false), "hi 🎉"},
+		{"UTF-16LE without BOM", utf16Bytes("Copied from Notepad", false), "Copied from Notepad"},
+		{"UTF-16BE without BOM", utf16Bytes("Copied from Notepad", true), "Copied from Notepad"},
+		{"UTF-16LE with NUL terminator", utf16Bytes("line one\r\nline two\x00", false), "line one\r\nline two"},
+		{"UTF-16LE with dangling byte", utf16LEBOM + utf16Bytes("abc", false) + "x", "abc"},
+		{"stray NUL in UTF-8 text", "a\x00bcdefgh", "a\x00bcdefgh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := normalizeClipboardText(tt.content)
+			assert.Equal(t, tt.expected, result)
+			assert.True(t, utf8.ValidString(result))
+		})
+	}
+}
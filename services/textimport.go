@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"klipd/config"
+	"klipd/database"
+	"klipd/models"
+
+	"github.com/google/uuid"
+)
+
+// How ImportFromTextFile splits a file into items
+const (
+	TextImportLines      = "lines"      // Every non-blank line is an item
+	TextImportParagraphs = "paragraphs" // Runs of lines separated by blank lines are items
+)
+
+// paragraphBreak matches the blank lines between paragraphs, including ones
+// holding only whitespace
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n`)
+
+// splitTextImport breaks text into the trimmed, non-empty chunks for mode
+func splitTextImport(text string, mode string) ([]string, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var parts []string
+	switch mode {
+	case TextImportLines:
+		parts = strings.Split(text, "\n")
+	case TextImportParagraphs:
+		parts = paragraphBreak.Split(text, -1)
+	default:
+		return nil, fmt.Errorf("unknown text import mode: %s", mode)
+	}
+
+	chunks := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			chunks = append(chunks, part)
+		}
+	}
+	return chunks, nil
+}
+
+// ImportFromTextFile adds a text item for each line or paragraph of the file
+// at path, as chosen by mode. Chunks ShouldSkipContent rejects are left out,
+// as are ones already in history; the rest are encrypted or compressed as
+// captures would be. Items are stored in one transaction and
+// dated a millisecond apart so history keeps the file's order, last chunk
+// newest. It returns how many items were added.
+func (cm *ClipboardMonitor) ImportFromTextFile(path string, mode string, progress database.ProgressFunc) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	chunks, err := splitTextImport(string(data), mode)
+	if err != nil {
+		return 0, err
+	}
+
+	toImport := make([]models.ClipboardItem, 0, len(chunks))
+	for _, chunk := range chunks {
+		if cm.config.ShouldSkipContent(chunk) {
+			continue
+		}
+
+		sensitive := cm.config.IsSensitiveContent(chunk)
+		hash, dedupHash, err := cm.itemHashes(chunk, sensitive)
+		if err != nil {
+			slog.Warn("Skipping sensitive imported chunk, encryption unavailable", "err", err)
+			continue
+		}
+
+		item := models.ClipboardItem{
+			ID:          uuid.New().String(),
+			ContentType: "text",
+			ContentText: chunk,
+			PreviewText: config.TruncatePreview(chunk, 200),
+			Hash:        hash,
+			DedupHash:   dedupHash,
+		}
+		if err := cm.protectItem(&item, chunk, sensitive); err != nil {
+			slog.Warn("Skipping sensitive imported chunk, encryption unavailable", "err", err)
+			continue
+		}
+		toImport = append(toImport, item)
+	}
+
+	now := time.Now()
+	for i := range toImport {
+		toImport[i].CreatedAt = now.Add(time.Duration(i-len(toImport)+1) * time.Millisecond)
+	}
+
+	return cm.db.ImportItems(toImport, database.ImportSkip, progress)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"klipd/config"
+	"klipd/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const textImportFile = "first line\r\nsecond line\n\n   \nthird line\n  indented\nfirst line\n\n\n"
+
+func writeTextImportFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "snippets.txt")
+	require.NoError(t, os.WriteFile(path, []byte(textImportFile), 0o600))
+	return path
+}
+
+func TestImportFromTextFileLines(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	imported, err := monitor.ImportFromTextFile(writeTextImportFile(t), TextImportLines, nil)
+	require.NoError(t, err)
+	// The repeated first line is only stored once
+	assert.Equal(t, 4, imported)
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"indented", "third line", "second line", "first line"}, itemContents(items))
+	for _, item := range items {
+		assert.Equal(t, "text", item.ContentType)
+	}
+}
+
+func TestImportFromTextFileParagraphs(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+
+	imported, err := monitor.ImportFromTextFile(writeTextImportFile(t), TextImportParagraphs, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"third line\n  indented\nfirst line", "first line\nsecond line"}, itemContents(items))
+}
+
+func TestImportFromTextFileSkipsFilteredContent(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.config.MinContentLength = 11
+
+	imported, err := monitor.ImportFromTextFile(writeTextImportFile(t), TextImportLines, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"second line"}, itemContents(items))
+
+	_, err = monitor.ImportFromTextFile(writeTextImportFile(t), "sentences", nil)
+	assert.Error(t, err)
+}
+
+func TestImportFromTextFileProtectsChunks(t *testing.T) {
+	monitor, db := setupTestClipboardMonitor(t)
+	monitor.cipher = newItemCipher(testKey())
+	monitor.config.AllowPasswords = true
+	monitor.config.CompressLargeContent = true
+
+	large := strings.Repeat("SELECT * FROM users WHERE id = 1;\n", 1000)
+	path := filepath.Join(t.TempDir(), "snippets.txt")
+	require.NoError(t, os.WriteFile(path, []byte("VeryComplexP@ssw0rd!\n\n"+large), 0o600))
+
+	imported, err := monitor.ImportFromTextFile(path, TextImportParagraphs, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+
+	items, err := db.GetClipboardItems(10, 0, "", database.SortCopied)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	compressed, secret := items[0], items[1]
+
+	// Sensitive chunks are encrypted under keyed hashes, like captures
+	assert.True(t, secret.Encrypted)
+	assert.NotContains(t, secret.ContentText, "P@ssw0rd")
+	assert.NotEqual(t, config.GenerateHash("VeryComplexP@ssw0rd!"), secret.Hash)
+
+	assert.True(t, compressed.Compressed)
+	content, err := monitor.GetItemContent(compressed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(large), content)
+}
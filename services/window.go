@@ -0,0 +1,74 @@
+package services
+
+import "sync"
+
+// WindowControls is the part of the Wails window runtime the main window
+// toggle needs
+type WindowControls interface {
+	Show()
+	Hide()
+	IsMinimised() bool
+}
+
+// MainWindow remembers whether the main window is showing and in front.
+// Wails can show and hide a window but can't say whether it's visible, so
+// every show and hide goes through here; the frontend reports focus changes
+// it sees so a window left behind other apps isn't mistaken for frontmost.
+type MainWindow struct {
+	mu       sync.Mutex
+	controls WindowControls
+	visible  bool
+	focused  bool
+}
+
+// NewMainWindow tracks a window that starts out shown and focused, as the
+// Wails main window does
+func NewMainWindow(controls WindowControls) *MainWindow {
+	return &MainWindow{controls: controls, visible: true, focused: true}
+}
+
+// Show brings the window to the front
+func (w *MainWindow) Show() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.show()
+}
+
+// Hide hides the window
+func (w *MainWindow) Hide() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.controls.Hide()
+	w.visible = false
+	w.focused = false
+}
+
+// SetFocused records whether the window has focus
+func (w *MainWindow) SetFocused(focused bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.focused = focused
+}
+
+// Toggle hides the window if it's frontmost and shows it otherwise, which
+// includes when it's minimised or behind another app. It reports whether
+// the window is now shown.
+func (w *MainWindow) Toggle() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.visible && w.focused && !w.controls.IsMinimised() {
+		w.controls.Hide()
+		w.visible = false
+		w.focused = false
+		return false
+	}
+	w.show()
+	return true
+}
+
+func (w *MainWindow) show() {
+	w.controls.Show()
+	w.visible = true
+	w.focused = true
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWindow stands in for the Wails window runtime
+type fakeWindow struct {
+	shown     bool
+	minimised bool
+	calls     []string
+}
+
+func (f *fakeWindow) Show() {
+	f.shown = true
+	f.minimised = false
+	f.calls = append(f.calls, "show")
+}
+
+func (f *fakeWindow) Hide() {
+	f.shown = false
+	f.calls = append(f.calls, "hide")
+}
+
+func (f *fakeWindow) IsMinimised() bool { return f.minimised }
+
+func TestMainWindowToggle(t *testing.T) {
+	t.Run("frontmost window is hidden", func(t *testing.T) {
+		fake := &fakeWindow{shown: true}
+		window := NewMainWindow(fake)
+
+		assert.False(t, window.Toggle())
+		assert.Equal(t, []string{"hide"}, fake.calls)
+	})
+
+	t.Run("hidden window is shown", func(t *testing.T) {
+		fake := &fakeWindow{}
+		window := NewMainWindow(fake)
+		window.Hide()
+
+		assert.True(t, window.Toggle())
+		assert.Equal(t, []string{"hide", "show"}, fake.calls)
+		assert.True(t, fake.shown)
+	})
+
+	t.Run("window behind another app is brought forward", func(t *testing.T) {
+		fake := &fakeWindow{shown: true}
+		window := NewMainWindow(fake)
+		window.SetFocused(false)
+
+		assert.True(t, window.Toggle())
+		assert.Equal(t, []string{"show"}, fake.calls)
+
+		// Now it's in front, so the next press hides it
+		assert.False(t, window.Toggle())
+		assert.Equal(t, []string{"show", "hide"}, fake.calls)
+	})
+
+	t.Run("minimised window is restored", func(t *testing.T) {
+		fake := &fakeWindow{shown: true, minimised: true}
+		window := NewMainWindow(fake)
+
+		assert.True(t, window.Toggle())
+		assert.Equal(t, []string{"show"}, fake.calls)
+	})
+
+	t.Run("repeated presses alternate", func(t *testing.T) {
+		fake := &fakeWindow{shown: true}
+		window := NewMainWindow(fake)
+
+		assert.False(t, window.Toggle())
+		assert.True(t, window.Toggle())
+		assert.False(t, window.Toggle())
+		assert.Equal(t, []string{"hide", "show", "hide"}, fake.calls)
+	})
+}